@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// command is a non-interactive subcommand such as `rafa plan migrate`.
+// Commands with Subcommands dispatch on their first argument; leaf commands
+// implement Run and parse their own flags, passing their synopsis to
+// parseCommandFlags for help and usage errors.
+type command struct {
+	Name        string
+	Summary     string
+	Run         func(args []string, stdout io.Writer) error
+	Subcommands []command
+}
+
+// exitError carries a specific process exit code out of a command.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	return e.msg
+}
+
+// usageError marks an error caused by invalid arguments (exit code 2).
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func newUsageError(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// commands is the registry of top-level subcommands.
+var commands = []command{
+	{
+		Name:    "plan",
+		Summary: "Manage plans",
+		Subcommands: []command{
+			{
+				Name:    "create",
+				Summary: "Create a plan from a checklist, task file or issue export (no AI)",
+				Run:     runPlanCreate,
+			},
+			{
+				Name:    "migrate",
+				Summary: "Upgrade plan.json files to the current schema version",
+				Run:     runPlanMigrate,
+			},
+			{
+				Name:    "export",
+				Summary: "Package a plan folder into a portable bundle",
+				Run:     runPlanExport,
+			},
+			{
+				Name:    "import",
+				Summary: "Import a plan bundle under a new ID",
+				Run:     runPlanImport,
+			},
+			{
+				Name:    "archive",
+				Summary: "Move a plan to .rafa/archive",
+				Run:     runPlanArchive,
+			},
+			{
+				Name:    "delete",
+				Summary: "Delete a plan folder",
+				Run:     runPlanDelete,
+			},
+		},
+	},
+	{
+		Name:    "gc",
+		Summary: "Prune large or old output logs",
+		Run:     runGC,
+	},
+	{
+		Name:    "metrics",
+		Summary: "Show task outcome trends across all plans",
+		Run:     runMetrics,
+	},
+	{
+		Name:    "prompt",
+		Summary: "Inspect agent prompts",
+		Subcommands: []command{
+			{
+				Name:    "render",
+				Summary: "Print the prompt the next attempt of a task would get",
+				Run:     runPromptRender,
+			},
+		},
+	},
+	{
+		Name:    "report",
+		Summary: "Print run analytics for a plan as Markdown or JSON",
+		Run:     runReport,
+	},
+	{
+		Name:    "search",
+		Summary: "Search plans, agent transcripts and progress logs",
+		Run:     runSearch,
+	},
+}
+
+// commandStdin is where commands read confirmation answers from.
+var commandStdin io.Reader = os.Stdin
+
+// confirm asks a yes/no question on stdout and reads the answer from
+// commandStdin. Anything other than "y" or "yes" counts as no.
+func confirm(stdout io.Writer, question string) bool {
+	fmt.Fprintf(stdout, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(commandStdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// isCommand reports whether arg names a registered top-level subcommand.
+func isCommand(arg string) bool {
+	_, ok := findCommand(commands, arg)
+	return ok
+}
+
+func findCommand(list []command, name string) (command, bool) {
+	for _, c := range list {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// runCommand dispatches args (starting with the command name) and returns the
+// process exit code. Errors are written to stderr.
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	err := dispatchCommand(commands, "rafa", args, stdout)
+	if err == nil {
+		return 0
+	}
+
+	var usageErr *usageError
+	var exitErr *exitError
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(stderr, usageErr.msg)
+		return 2
+	case errors.As(err, &exitErr):
+		if exitErr.msg != "" {
+			fmt.Fprintln(stderr, exitErr.msg)
+		}
+		return exitErr.code
+	default:
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+}
+
+func dispatchCommand(list []command, prefix string, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return newUsageError("missing command\n\n%s", commandListUsage(prefix, list))
+	}
+
+	name := args[0]
+	if name == "-h" || name == "--help" || name == "help" {
+		fmt.Fprint(stdout, commandListUsage(prefix, list))
+		return flag.ErrHelp
+	}
+
+	cmd, ok := findCommand(list, name)
+	if !ok {
+		return newUsageError("unknown command %q\n\n%s", name, commandListUsage(prefix, list))
+	}
+
+	path := prefix + " " + cmd.Name
+	if len(cmd.Subcommands) > 0 {
+		return dispatchCommand(cmd.Subcommands, path, args[1:], stdout)
+	}
+	return cmd.Run(args[1:], stdout)
+}
+
+func commandListUsage(prefix string, list []command) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s <command> [arguments]\n\n", prefix)
+	fmt.Fprintln(&b, "Commands:")
+	for _, c := range list {
+		fmt.Fprintf(&b, "  %-10s %s\n", c.Name, c.Summary)
+	}
+	return b.String()
+}
+
+// newCommandFlagSet creates a flag set for a leaf command. Parse errors and
+// -h are reported through parseCommandFlags.
+func newCommandFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseCommandFlags parses args and converts flag errors into usage errors.
+// On -h it prints usage to stdout and returns flag.ErrHelp.
+func parseCommandFlags(fs *flag.FlagSet, usage string, args []string, stdout io.Writer) error {
+	help := func() string {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Usage: %s\n", usage)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(&b, "")
+			fmt.Fprintln(&b, "Flags:")
+			fs.SetOutput(&b)
+			fs.PrintDefaults()
+			fs.SetOutput(io.Discard)
+		}
+		return b.String()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			fmt.Fprint(stdout, help())
+			return flag.ErrHelp
+		}
+		return newUsageError("%v\n\n%s", err, help())
+	}
+	return nil
+}
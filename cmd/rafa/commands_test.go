@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/testutil"
+)
+
+func writeTestPlan(t *testing.T, folder, content string) string {
+	t.Helper()
+	dir := filepath.Join(".rafa", "plans", folder)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create plan dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plan.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write plan.json: %v", err)
+	}
+	return dir
+}
+
+func TestIsCommand(t *testing.T) {
+	if !isCommand("plan") {
+		t.Error("expected plan to be a command")
+	}
+	if isCommand("foo") || isCommand("--demo") {
+		t.Error("unexpected command match")
+	}
+}
+
+func TestRunCommand_UnknownSubcommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := runCommand([]string{"plan", "nope"}, &stdout, &stderr)
+	if code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `unknown command "nope"`) {
+		t.Errorf("expected unknown command error, got: %s", stderr.String())
+	}
+}
+
+func TestRunCommand_Help(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := runCommand([]string{"plan", "--help"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "migrate") {
+		t.Errorf("expected help to list migrate, got: %s", stdout.String())
+	}
+}
+
+func TestPlanMigrate_Check(t *testing.T) {
+	testutil.SetupTestDir(t)
+	legacy := writeTestPlan(t, "abc123-legacy", `{"id": "abc123", "name": "legacy", "tasks": []}`)
+	writeTestPlan(t, "def456-current", `{"schemaVersion": 2, "id": "def456", "name": "current", "status": "not_started", "tasks": []}`)
+
+	var stdout, stderr bytes.Buffer
+	code := runCommand([]string{"plan", "migrate", "--check"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "abc123-legacy: needs migration (schema v0 -> v2)") {
+		t.Errorf("expected legacy plan to need migration, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "def456-current: up to date") {
+		t.Errorf("expected current plan to be up to date, got:\n%s", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "plan.json.v0.bak")); !os.IsNotExist(err) {
+		t.Error("--check must not modify plans")
+	}
+}
+
+func TestPlanMigrate_Migrates(t *testing.T) {
+	testutil.SetupTestDir(t)
+	legacy := writeTestPlan(t, "abc123-legacy", `{"id": "abc123", "name": "legacy", "tasks": []}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"plan", "migrate"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "plan.json.v0.bak")); err != nil {
+		t.Errorf("expected backup after migration: %v", err)
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"plan", "migrate", "--check"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected clean check after migration, got %d:\n%s", code, stdout.String())
+	}
+}
+
+func TestPlanMigrate_CheckReportsInvalidPlan(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeTestPlan(t, "abc123-broken", `{"schemaVersion": 2, "id": "abc123", "bogus": true}`)
+
+	var stdout, stderr bytes.Buffer
+	code := runCommand([]string{"plan", "migrate", "--check", "broken"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), `unknown field "bogus"`) {
+		t.Errorf("expected unknown field report, got:\n%s", stdout.String())
+	}
+}
+
+func TestPlanExportImport(t *testing.T) {
+	testutil.SetupTestDir(t)
+	planDir := writeTestPlan(t, "abc123-feature", `{"schemaVersion": 2, "id": "abc123", "name": "feature", "status": "completed", "tasks": []}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"plan", "export", "-o", "feature.json", "--remove", "feature"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("export: exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Exported abc123-feature to feature.json") {
+		t.Errorf("unexpected export output: %s", stdout.String())
+	}
+	if _, err := os.Stat(planDir); !os.IsNotExist(err) {
+		t.Error("expected --remove to delete the plan folder")
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"plan", "import", "feature.json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("import: exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "-feature (0 tasks)") {
+		t.Errorf("unexpected import output: %s", stdout.String())
+	}
+}
+
+func TestPlanExport_RemoveRefusesLockedPlan(t *testing.T) {
+	testutil.SetupTestDir(t)
+	planDir := writeTestPlan(t, "abc123-feature", `{"schemaVersion": 2, "id": "abc123", "name": "feature", "status": "in_progress", "tasks": []}`)
+	if err := os.WriteFile(filepath.Join(planDir, "run.lock"), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"plan", "export", "--remove", "feature"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if _, err := os.Stat(planDir); err != nil {
+		t.Error("locked plan must not be removed")
+	}
+}
+
+func TestPlanCreate_FromChecklist(t *testing.T) {
+	testutil.SetupTestDir(t)
+	if err := os.MkdirAll(filepath.Join(".rafa", "plans"), 0755); err != nil {
+		t.Fatalf("failed to create .rafa: %v", err)
+	}
+	if err := os.WriteFile("todo.md", []byte("# Cleanup\n\n## Drop flags\n- [ ] Flag is gone\n"), 0644); err != nil {
+		t.Fatalf("failed to write checklist: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"plan", "create", "todo.md"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "-cleanup") || !strings.Contains(stdout.String(), "1. Drop flags (1 criteria)") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	dirs, err := filepath.Glob(filepath.Join(".rafa", "plans", "*-cleanup"))
+	if err != nil || len(dirs) != 1 {
+		t.Fatalf("expected one plan folder, got %v (%v)", dirs, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dirs[0], "plan.json"))
+	if err != nil {
+		t.Fatalf("failed to read plan.json: %v", err)
+	}
+	if !strings.Contains(string(data), `"sourceFile": "todo.md"`) {
+		t.Errorf("expected source file in plan.json:\n%s", data)
+	}
+}
+
+func TestPlanCreate_DryRunDoesNotWrite(t *testing.T) {
+	testutil.SetupTestDir(t)
+	if err := os.WriteFile("tasks.yaml", []byte("tasks:\n  - title: A\n    acceptanceCriteria: [done]\n"), 0644); err != nil {
+		t.Fatalf("failed to write task file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"plan", "create", "--dry-run", "tasks.yaml"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Plan: tasks") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+	if _, err := os.Stat(".rafa"); !os.IsNotExist(err) {
+		t.Error("--dry-run must not create plans")
+	}
+}
+
+func TestPlanArchiveAndDelete(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeTestPlan(t, "abc123-old", `{"schemaVersion": 2, "id": "abc123", "name": "old", "status": "completed", "tasks": []}`)
+	doomed := writeTestPlan(t, "def456-doomed", `{"schemaVersion": 2, "id": "def456", "name": "doomed", "status": "completed", "tasks": []}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"plan", "archive", "old"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("archive: exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(".rafa", "archive", "abc123-old", "plan.json")); err != nil {
+		t.Errorf("expected archived plan: %v", err)
+	}
+
+	commandStdin = strings.NewReader("n\n")
+	defer func() { commandStdin = os.Stdin }()
+	stdout.Reset()
+	if code := runCommand([]string{"plan", "delete", "doomed"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("delete: exit code %d", code)
+	}
+	if _, err := os.Stat(doomed); err != nil {
+		t.Fatal("declined delete must keep the plan")
+	}
+
+	commandStdin = strings.NewReader("y\n")
+	if code := runCommand([]string{"plan", "delete", "doomed"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("delete: exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := os.Stat(doomed); !os.IsNotExist(err) {
+		t.Error("expected plan to be deleted")
+	}
+}
@@ -25,15 +25,25 @@ func parseArgs(args []string) (parseResult, error) {
 	demoMode := fs.String("demo-mode", string(demo.ModeRun), "Demo mode: run|create")
 	demoPreset := fs.String("demo-preset", string(demo.PresetMedium), "Demo preset: quick|medium|slow")
 	demoScenario := fs.String("demo-scenario", string(demo.ScenarioSuccess), "Demo scenario: success|flaky|fail")
+	otlpEndpoint := fs.String("otlp-endpoint", "", "Export plan run traces to this OTLP/HTTP collector (e.g. http://localhost:4318)")
+	metricsAddr := fs.String("metrics-addr", "", "Serve Prometheus metrics of plan runs at http://<addr>/metrics (e.g. :9090)")
+	apiAddr := fs.String("api-addr", "", "Serve the HTTP API and event stream on this address (e.g. 127.0.0.1:8765)")
+	apiToken := fs.String("api-token", "", "Token required by the API's run control endpoints (default $RAFA_API_TOKEN)")
 	showVersion := fs.Bool("version", false, "Show version information")
 	showVersionShort := fs.Bool("v", false, "Show version information")
 
 	usage := func() string {
 		var b strings.Builder
 		fmt.Fprintln(&b, "Usage: rafa [flags]")
+		fmt.Fprintln(&b, "       rafa <command> [arguments]")
 		fmt.Fprintln(&b, "")
 		fmt.Fprintln(&b, "Rafa is a task loop runner for AI coding agents.")
 		fmt.Fprintln(&b, "")
+		fmt.Fprintln(&b, "Commands:")
+		for _, c := range commands {
+			fmt.Fprintf(&b, "  %-10s %s\n", c.Name, c.Summary)
+		}
+		fmt.Fprintln(&b, "")
 		fmt.Fprintln(&b, "Flags:")
 		fs.SetOutput(&b)
 		fs.PrintDefaults()
@@ -75,7 +85,12 @@ func parseArgs(args []string) (parseResult, error) {
 	}
 
 	if !*demoEnabled {
-		return parseResult{Options: tui.Options{}}, nil
+		return parseResult{Options: tui.Options{
+			OTLPEndpoint: *otlpEndpoint,
+			MetricsAddr:  *metricsAddr,
+			APIAddr:      *apiAddr,
+			APIToken:     *apiToken,
+		}}, nil
 	}
 
 	mode, err := demo.ParseMode(*demoMode)
@@ -99,6 +114,10 @@ func parseArgs(args []string) (parseResult, error) {
 
 	return parseResult{
 		Options: tui.Options{
+			OTLPEndpoint: *otlpEndpoint,
+			MetricsAddr:  *metricsAddr,
+			APIAddr:      *apiAddr,
+			APIToken:     *apiToken,
 			Demo: &tui.DemoOptions{
 				Mode:     mode,
 				Preset:   preset,
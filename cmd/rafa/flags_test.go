@@ -184,3 +184,36 @@ func TestParseArgs_Help(t *testing.T) {
 		t.Fatalf("expected help text to include version flags, got: %s", res.HelpText)
 	}
 }
+
+func TestParseArgs_OTLPEndpoint(t *testing.T) {
+	res, err := parseArgs([]string{"--otlp-endpoint", "http://localhost:4318"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Options.OTLPEndpoint != "http://localhost:4318" {
+		t.Fatalf("expected endpoint to be set, got %q", res.Options.OTLPEndpoint)
+	}
+}
+
+func TestParseArgs_MetricsAddr(t *testing.T) {
+	res, err := parseArgs([]string{"--metrics-addr", ":9090"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Options.MetricsAddr != ":9090" {
+		t.Fatalf("expected metrics address to be set, got %q", res.Options.MetricsAddr)
+	}
+}
+
+func TestParseArgs_APIFlags(t *testing.T) {
+	res, err := parseArgs([]string{"--api-addr", "127.0.0.1:8787", "--api-token", "s3cret"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Options.APIAddr != "127.0.0.1:8787" {
+		t.Fatalf("expected API address to be set, got %q", res.Options.APIAddr)
+	}
+	if res.Options.APIToken != "s3cret" {
+		t.Fatalf("expected API token to be set, got %q", res.Options.APIToken)
+	}
+}
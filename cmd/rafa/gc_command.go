@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pablasso/rafa/internal/git"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+const (
+	defaultGCMaxSize = "50MB"
+	defaultGCMaxAge  = "30d"
+)
+
+// runGC implements `rafa gc`, which prunes plan output (output.log and the
+// per-attempt files in output/) in .rafa/plans and .rafa/archive that is
+// larger than --max-size or has not been written for --max-age, and commits
+// the prune. Running plans are never touched.
+func runGC(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("gc")
+	maxSizeFlag := fs.String("max-size", defaultGCMaxSize, "Prune output logs larger than this (e.g. 500KB, 50MB, 1GB; 0 disables)")
+	maxAgeFlag := fs.String("max-age", defaultGCMaxAge, "Prune output logs not written for this long (e.g. 72h, 30d; 0 disables)")
+	dryRun := fs.Bool("dry-run", false, "Report what would be pruned without changing anything")
+	if err := parseCommandFlags(fs, "rafa gc [--max-size size] [--max-age age] [--dry-run]", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return newUsageError("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	maxSize, err := parseByteSize(*maxSizeFlag)
+	if err != nil {
+		return newUsageError("invalid --max-size: %v", err)
+	}
+	maxAge, err := parseAge(*maxAgeFlag)
+	if err != nil {
+		return newUsageError("invalid --max-age: %v", err)
+	}
+
+	dirs := []string{
+		filepath.Join(".rafa", "plans"),
+		filepath.Join(".rafa", "archive"),
+	}
+	result, err := plan.CollectGarbage(dirs, plan.GCOptions{
+		MaxOutputSize: maxSize,
+		MaxAge:        maxAge,
+		DryRun:        *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "pruned"
+	if *dryRun {
+		verb = "would prune"
+	}
+	for _, a := range result.Pruned {
+		fmt.Fprintf(stdout, "%s: %s output (%s, %s)\n", filepath.Base(a.PlanDir), verb, formatByteSize(a.Size), a.Reason)
+	}
+	for _, s := range result.Skipped {
+		fmt.Fprintf(stdout, "%s: skipped (%s)\n", filepath.Base(s.PlanDir), s.Reason)
+	}
+
+	if len(result.Pruned) == 0 {
+		fmt.Fprintln(stdout, "Nothing to prune.")
+		return nil
+	}
+	if *dryRun {
+		fmt.Fprintf(stdout, "Would free %s.\n", formatByteSize(result.BytesFreed))
+		return nil
+	}
+	fmt.Fprintf(stdout, "Freed %s.\n", formatByteSize(result.BytesFreed))
+
+	// Plan output is committed with every task, so the prune would otherwise
+	// leave the workspace dirty and block the next run.
+	if err := commitPrunedOutput(result.Pruned); err != nil {
+		fmt.Fprintf(stdout, "Could not commit the pruned output (%v); commit it before the next rafa run.\n", err)
+	}
+	return nil
+}
+
+// commitPrunedOutput commits the truncated output.log and removed output/ of
+// each pruned plan, leaving any other changes in the workspace alone.
+func commitPrunedOutput(pruned []plan.GCAction) error {
+	var paths []string
+	for _, a := range pruned {
+		paths = append(paths, a.Path, filepath.Join(a.PlanDir, plan.OutputDirName))
+	}
+	return git.CommitPaths("", "[rafa] Prune plan output", paths)
+}
+
+var byteSizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize parses sizes like "512", "500KB", "50MB" or "1GB" (binary units).
+func parseByteSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	factor := int64(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(upper, u.suffix) {
+			upper = strings.TrimSpace(strings.TrimSuffix(upper, u.suffix))
+			factor = u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a size like 50MB, got %q", s)
+	}
+	return n * factor, nil
+}
+
+func formatByteSize(n int64) string {
+	for _, u := range byteSizeUnits {
+		if n >= u.factor && u.factor > 1 {
+			return fmt.Sprintf("%.1f %s", float64(n)/float64(u.factor), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// parseAge parses a Go duration, a number of days such as "30d", or "0".
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("expected an age like 30d or 72h, got %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("expected an age like 30d or 72h, got %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/testutil"
+)
+
+func TestGC(t *testing.T) {
+	testutil.SetupTestDir(t)
+	dir := writeTestPlan(t, "abc123-big", `{"schemaVersion": 2, "id": "abc123", "name": "big", "tasks": []}`)
+	if err := os.WriteFile(filepath.Join(dir, "output.log"), bytes.Repeat([]byte("x"), 2048), 0644); err != nil {
+		t.Fatalf("failed to write output.log: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"gc", "--max-size", "1KB", "--dry-run"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "abc123-big: would prune output (2.0 KB") {
+		t.Errorf("unexpected dry-run output:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"gc", "--max-size", "1KB"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "output.log")); info.Size() != 0 {
+		t.Errorf("expected output.log to be truncated, size %d", info.Size())
+	}
+	if !strings.Contains(stdout.String(), "Freed 2.0 KB.") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestGC_CommitsPrune(t *testing.T) {
+	testutil.SetupTestDir(t)
+	planDir := writeTestPlan(t, "abc123-big", `{"schemaVersion": 2, "id": "abc123", "name": "big", "status": "not_started", "tasks": [{"id": "t01", "title": "Next", "description": "", "acceptanceCriteria": ["done"], "status": "pending"}]}`)
+	if err := os.WriteFile(filepath.Join(planDir, "output.log"), bytes.Repeat([]byte("x"), 2048), 0644); err != nil {
+		t.Fatalf("failed to write output.log: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(planDir, "output"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(planDir, "output", "t01-attempt1.log"), []byte("attempt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	// Staged work of the user's own is not part of the prune commit.
+	if err := os.WriteFile("notes.txt", []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "add", "notes.txt").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v\n%s", err, out)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"gc", "--max-size", "1KB"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if strings.Contains(stdout.String(), "Could not commit") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	out, err := exec.Command("git", "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "A  notes.txt" {
+		t.Errorf("expected only notes.txt to stay uncommitted, got %q", got)
+	}
+	out, err = exec.Command("git", "log", "-1", "--format=%s").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "[rafa] Prune plan output" {
+		t.Errorf("unexpected last commit %q", got)
+	}
+
+	// With the user's work committed, the plan runs.
+	if out, err := exec.Command("git", "commit", "-q", "-m", "notes").CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+	p, err := plan.LoadPlan(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.New(planDir, p).WithRunner(noopRunner{}).Run(context.Background()); err != nil {
+		t.Fatalf("expected the plan to run after rafa gc, got: %v", err)
+	}
+}
+
+func TestParseByteSizeAndAge(t *testing.T) {
+	sizes := map[string]int64{"0": 0, "512": 512, "2KB": 2048, "50mb": 50 << 20, "1GB": 1 << 30}
+	for in, want := range sizes {
+		got, err := parseByteSize(in)
+		if err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := parseByteSize("lots"); err == nil {
+		t.Error("expected error for invalid size")
+	}
+
+	ages := map[string]time.Duration{"0": 0, "30d": 30 * 24 * time.Hour, "72h": 72 * time.Hour}
+	for in, want := range ages {
+		got, err := parseAge(in)
+		if err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseAge("-1d"); err == nil {
+		t.Error("expected error for negative age")
+	}
+}
@@ -23,6 +23,10 @@ func main() {
 		os.Exit(1)
 	}()
 
+	if len(os.Args) > 1 && isCommand(os.Args[1]) {
+		os.Exit(runCommand(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	parsed, err := parseArgs(os.Args[1:])
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pablasso/rafa/internal/metrics"
+	"github.com/pablasso/rafa/internal/report"
+)
+
+// runMetrics implements `rafa metrics`. It refreshes the metrics store
+// (see metrics.StorePath) from every plan in .rafa/plans and .rafa/archive, then
+// prints first-attempt success rate, average cost per task and median task
+// duration grouped by week, agent backend and design doc.
+func runMetrics(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("metrics")
+	byFlag := fs.String("by", "", "Only group by: week|backend|design (default: all three)")
+	jsonFlag := fs.Bool("json", false, "Print the trends as JSON")
+	noRefresh := fs.Bool("no-refresh", false, "Report from the stored history without reading plans")
+	if err := parseCommandFlags(fs, "rafa metrics [--by week|backend|design] [--json] [--no-refresh]", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return newUsageError("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	groupings := metrics.Groupings
+	if *byFlag != "" {
+		by, err := metrics.ParseGroupBy(*byFlag)
+		if err != nil {
+			return newUsageError("%v", err)
+		}
+		groupings = []metrics.GroupBy{by}
+	}
+
+	if _, err := os.Stat(".rafa"); os.IsNotExist(err) {
+		return fmt.Errorf(".rafa directory not found; run rafa from the repository root")
+	}
+	storePath := metrics.StorePath()
+	store, err := metrics.LoadStore(storePath)
+	if err != nil {
+		return err
+	}
+	if !*noRefresh {
+		records, err := metrics.CollectAll([]string{
+			filepath.Join(".rafa", "plans"),
+			filepath.Join(".rafa", "archive"),
+		})
+		if err != nil {
+			return err
+		}
+		store.Merge(records)
+		store.UpdatedAt = time.Now().UTC()
+		if err := store.Save(storePath); err != nil {
+			return err
+		}
+	}
+
+	if *jsonFlag {
+		out := map[string]interface{}{
+			"updatedAt": store.UpdatedAt,
+			"tasks":     len(store.Tasks),
+		}
+		for _, by := range groupings {
+			out[string(by)] = metrics.Aggregate(store.Tasks, by)
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	if len(store.Tasks) == 0 {
+		fmt.Fprintln(stdout, "No task runs recorded yet.")
+		return nil
+	}
+	fmt.Fprintf(stdout, "%d task runs recorded.\n", len(store.Tasks))
+	for _, by := range groupings {
+		fmt.Fprintf(stdout, "\nBy %s:\n", metricsGroupLabel(by))
+		fmt.Fprintf(stdout, "  %-32s %6s %10s %9s %12s\n", "", "Tasks", "First try", "Avg cost", "Median time")
+		for _, g := range metrics.Aggregate(store.Tasks, by) {
+			fmt.Fprintf(stdout, "  %-32s %6d %9.0f%% %9s %12s\n",
+				g.Key, g.Tasks, g.FirstAttemptSuccessRate*100,
+				fmt.Sprintf("$%.2f", g.AvgCostUSD), report.FormatDuration(g.MedianDurationSeconds))
+		}
+	}
+	return nil
+}
+
+func metricsGroupLabel(by metrics.GroupBy) string {
+	if by == metrics.ByDesignDoc {
+		return "design doc"
+	}
+	return string(by)
+}
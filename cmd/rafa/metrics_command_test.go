@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/metrics"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/testutil"
+)
+
+func writeMetricsPlan(t *testing.T) string {
+	t.Helper()
+	dir := writeTestPlan(t, "abc123-auth", `{"schemaVersion": 2, "id": "abc123", "name": "auth", "sourceFile": "docs/designs/auth.md", "tasks": [{"id": "t01", "title": "One", "status": "completed"}]}`)
+	progress := `{"timestamp":"2024-01-15T10:00:00Z","event":"plan_started","data":{"plan_id":"abc123","backend":"claude"}}
+{"timestamp":"2024-01-15T10:00:00Z","event":"task_started","data":{"task_id":"t01","attempt":1}}
+{"timestamp":"2024-01-15T10:03:00Z","event":"task_completed","data":{"task_id":"t01"}}
+{"timestamp":"2024-01-15T10:03:00Z","event":"plan_completed","data":{}}
+`
+	if err := os.WriteFile(filepath.Join(dir, "progress.log"), []byte(progress), 0644); err != nil {
+		t.Fatalf("failed to write progress.log: %v", err)
+	}
+	return dir
+}
+
+func TestMetrics(t *testing.T) {
+	testutil.SetupTestDir(t)
+	planDir := writeMetricsPlan(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"metrics"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"1 task runs recorded.", "By week:", "2024-W03", "By backend:", "claude", "By design doc:", "docs/designs/auth.md", "100%", "03:00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// History survives the plan being deleted.
+	if err := os.RemoveAll(planDir); err != nil {
+		t.Fatalf("failed to remove plan: %v", err)
+	}
+	stdout.Reset()
+	if code := runCommand([]string{"metrics", "--json", "--by", "backend"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	var decoded struct {
+		Tasks   int             `json:"tasks"`
+		Backend []metrics.Group `json:"backend"`
+		Week    []metrics.Group `json:"week"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if decoded.Tasks != 1 || len(decoded.Backend) != 1 || decoded.Backend[0].Key != "claude" || decoded.Week != nil {
+		t.Errorf("unexpected JSON: %+v", decoded)
+	}
+}
+
+func TestMetrics_KeepsCostAfterGC(t *testing.T) {
+	testutil.SetupTestDir(t)
+	planDir := writeMetricsPlan(t)
+	output := `
+=== Task t01, Attempt 1 ===
+Started: 2024-01-15T10:00:00Z
+
+{"type":"result","subtype":"success","usage":{"input_tokens":2000,"output_tokens":300},"total_cost_usd":0.75}
+
+=== Task t01: SUCCESS ===
+`
+	if err := os.WriteFile(filepath.Join(planDir, "output.log"), []byte(output), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	for _, args := range [][]string{{"metrics"}, {"gc", "--max-size", "1"}, {"metrics", "--json", "--by", "backend"}} {
+		stdout.Reset()
+		if code := runCommand(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("%v: exit code %d (stderr: %s)", args, code, stderr.String())
+		}
+	}
+	if info, err := os.Stat(filepath.Join(planDir, "output.log")); err != nil || info.Size() != 0 {
+		t.Fatalf("expected gc to prune output.log, got %v", err)
+	}
+
+	var decoded struct {
+		Backend []metrics.Group `json:"backend"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if len(decoded.Backend) != 1 || decoded.Backend[0].AvgCostUSD != 0.75 {
+		t.Errorf("expected the cost to survive gc, got %+v", decoded.Backend)
+	}
+}
+
+// noopRunner completes every task without changing anything.
+type noopRunner struct{}
+
+func (noopRunner) Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output executor.OutputWriter) error {
+	return nil
+}
+
+func TestMetrics_PlanRunsAfterRefresh(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeMetricsPlan(t)
+	planDir := writeTestPlan(t, "def456-next", `{"schemaVersion": 2, "id": "def456", "name": "next", "status": "not_started", "tasks": [{"id": "t01", "title": "Next", "description": "", "acceptanceCriteria": ["done"], "status": "pending"}]}`)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"metrics"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(".git", "rafa", metrics.StoreFileName)); err != nil {
+		t.Errorf("expected the store in the git directory: %v", err)
+	}
+
+	// The refreshed store doesn't dirty the workspace for the next run.
+	p, err := plan.LoadPlan(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.New(planDir, p).WithRunner(noopRunner{}).Run(context.Background()); err != nil {
+		t.Fatalf("expected the plan to run after rafa metrics, got: %v", err)
+	}
+}
+
+func TestMetrics_Empty(t *testing.T) {
+	testutil.SetupTestDir(t)
+	if err := os.Mkdir(".rafa", 0755); err != nil {
+		t.Fatalf("failed to create .rafa: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"metrics", "--no-refresh"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "No task runs recorded yet.") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+	if code := runCommand([]string{"metrics", "--by", "month"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 for an unknown grouping, got %d", code)
+	}
+}
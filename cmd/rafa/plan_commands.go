@@ -0,0 +1,345 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// resolvePlanDirs returns the plan folder for name, or every plan folder
+// when name is empty.
+func resolvePlanDirs(name string) ([]string, error) {
+	if name != "" {
+		dir, err := plan.FindPlanFolder(name)
+		if err != nil {
+			return nil, err
+		}
+		return []string{dir}, nil
+	}
+	return plan.ListPlanDirs()
+}
+
+// runPlanCreate implements `rafa plan create <file>`. It builds a plan from a
+// hand-written task source with a deterministic importer, so no extraction
+// conversation is needed.
+func runPlanCreate(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("create")
+	formatFlag := fs.String("format", "", "Source format: markdown|tasks|github-issues (default: detect)")
+	nameFlag := fs.String("name", "", "Plan name (default: from the source, else the file name)")
+	dryRun := fs.Bool("dry-run", false, "Print the tasks without creating the plan")
+	if err := parseCommandFlags(fs, "rafa plan create [--format markdown|tasks|github-issues] [--name name] [--dry-run] <file>", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("expected exactly one source file")
+	}
+
+	var format plan.ImportFormat
+	if *formatFlag != "" {
+		parsed, err := plan.ParseImportFormat(*formatFlag)
+		if err != nil {
+			return newUsageError("%v", err)
+		}
+		format = parsed
+	}
+
+	sourceFile := fs.Arg(0)
+	result, err := plan.ImportTasks(sourceFile, format)
+	if err != nil {
+		return err
+	}
+	if *nameFlag != "" {
+		result.Name = *nameFlag
+	}
+
+	if *dryRun {
+		fmt.Fprintf(stdout, "Plan: %s\n", result.Name)
+		printImportedTasks(stdout, result.Tasks)
+		return nil
+	}
+
+	if _, err := os.Stat(".rafa"); os.IsNotExist(err) {
+		return fmt.Errorf(".rafa directory not found; run rafa from the repository root")
+	}
+
+	if abs, err := filepath.Abs(sourceFile); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				sourceFile = rel
+			}
+		}
+	}
+
+	p, err := plan.NewPlanFromExtraction(result, sourceFile)
+	if err != nil {
+		return err
+	}
+	if err := plan.CreatePlanFolder(p); err != nil {
+		return fmt.Errorf("failed to create plan: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "Created plan %s-%s\n", p.ID, p.Name)
+	printImportedTasks(stdout, result.Tasks)
+	return nil
+}
+
+func printImportedTasks(w io.Writer, tasks []plan.ExtractedTask) {
+	for i, t := range tasks {
+		fmt.Fprintf(w, "  %d. %s (%d criteria)\n", i+1, t.Title, len(t.AcceptanceCriteria))
+	}
+}
+
+// runPlanMigrate implements `rafa plan migrate [--check] [name]`.
+// With --check it only reports plans that need migration and exits with
+// status 1 if any do (or fail to parse), which makes it suitable for CI.
+func runPlanMigrate(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("migrate")
+	check := fs.Bool("check", false, "Report plans that need migration without modifying them")
+	if err := parseCommandFlags(fs, "rafa plan migrate [--check] [name]", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return newUsageError("expected at most one plan name, got %d", fs.NArg())
+	}
+
+	dirs, err := resolvePlanDirs(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		fmt.Fprintln(stdout, "No plans found.")
+		return nil
+	}
+
+	var pending, failed int
+	for _, dir := range dirs {
+		label := filepath.Base(dir)
+
+		status, err := plan.InspectPlan(dir)
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: error: %v\n", label, err)
+			failed++
+			continue
+		}
+		if !status.NeedsMigration {
+			fmt.Fprintf(stdout, "%s: up to date (schema v%d)\n", label, status.Version)
+			continue
+		}
+
+		if *check {
+			fmt.Fprintf(stdout, "%s: needs migration (schema v%d -> v%d)\n", label, status.Version, plan.CurrentSchemaVersion)
+			pending++
+			continue
+		}
+
+		locked, err := plan.NewPlanLock(dir).IsLocked()
+		if err != nil || locked {
+			fmt.Fprintf(stdout, "%s: skipped (plan is running)\n", label)
+			pending++
+			continue
+		}
+
+		if _, err := plan.LoadPlan(dir); err != nil {
+			fmt.Fprintf(stdout, "%s: error: %v\n", label, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: migrated (schema v%d -> v%d)\n", label, status.Version, plan.CurrentSchemaVersion)
+	}
+
+	if failed > 0 || pending > 0 {
+		return &exitError{
+			code: 1,
+			msg:  fmt.Sprintf("%d plan(s) need migration, %d plan(s) failed to load", pending, failed),
+		}
+	}
+	return nil
+}
+
+// runPlanExport implements `rafa plan export`. The bundle format defaults to
+// tar.gz unless --format is given or the output file ends in .json.
+// With --remove the plan folder is deleted after a successful export, which
+// archives finished plans out of .rafa/plans.
+func runPlanExport(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("export")
+	formatFlag := fs.String("format", "", "Bundle format: tar.gz|json (default: from -o extension, else tar.gz)")
+	outPath := fs.String("o", "", "Output file, or - for stdout (default: <id>-<name>.rafa.<format>)")
+	remove := fs.Bool("remove", false, "Delete the plan folder after a successful export")
+	if err := parseCommandFlags(fs, "rafa plan export [--format tar.gz|json] [-o file] [--remove] <name>", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("expected exactly one plan name")
+	}
+
+	format := plan.BundleFormatTarGz
+	switch {
+	case *formatFlag != "":
+		parsed, err := plan.ParseBundleFormat(*formatFlag)
+		if err != nil {
+			return newUsageError("%v", err)
+		}
+		format = parsed
+	case strings.HasSuffix(*outPath, ".json"):
+		format = plan.BundleFormatJSON
+	}
+
+	planDir, err := plan.FindPlanFolder(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *remove {
+		if locked, err := plan.NewPlanLock(planDir).IsLocked(); err != nil || locked {
+			return plan.ErrPlanLocked
+		}
+	}
+
+	bundle, err := plan.ExportBundle(planDir)
+	if err != nil {
+		return err
+	}
+
+	target := *outPath
+	if target == "" {
+		target = fmt.Sprintf("%s.rafa.%s", filepath.Base(planDir), format)
+	}
+
+	if target == "-" {
+		if err := plan.WriteBundle(stdout, bundle, format); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	} else {
+		if err := writeBundleFile(target, bundle, format); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Exported %s to %s\n", filepath.Base(planDir), target)
+	}
+
+	if *remove {
+		if err := plan.DeletePlan(planDir); err != nil {
+			return err
+		}
+		if target != "-" {
+			fmt.Fprintf(stdout, "Removed %s\n", planDir)
+		}
+	}
+	return nil
+}
+
+// writeBundleFile writes a bundle via a temp file so a failed export never
+// leaves a truncated bundle behind.
+func writeBundleFile(target string, bundle *plan.Bundle, format plan.BundleFormat) error {
+	tmpPath := fmt.Sprintf("%s.tmp.%d", target, os.Getpid())
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle file: %w", err)
+	}
+	if err := plan.WriteBundle(f, bundle, format); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := os.Rename(tmpPath, target); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// runPlanImport implements `rafa plan import <file>`. Use - to read from stdin.
+func runPlanImport(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("import")
+	if err := parseCommandFlags(fs, "rafa plan import <file>", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("expected exactly one bundle file")
+	}
+
+	var r io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open bundle: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	bundle, err := plan.ReadBundle(r)
+	if err != nil {
+		return err
+	}
+
+	planDir, err := plan.ImportBundle(bundle)
+	if err != nil {
+		return err
+	}
+
+	taskWord := "tasks"
+	if len(bundle.Plan.Tasks) == 1 {
+		taskWord = "task"
+	}
+	fmt.Fprintf(stdout, "Imported %s (%d %s)\n", filepath.Base(planDir), len(bundle.Plan.Tasks), taskWord)
+	return nil
+}
+
+// runPlanArchive implements `rafa plan archive <name>`, which moves a plan
+// folder from .rafa/plans to .rafa/archive.
+func runPlanArchive(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("archive")
+	if err := parseCommandFlags(fs, "rafa plan archive <name>", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("expected exactly one plan name")
+	}
+
+	planDir, err := plan.FindPlanFolder(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	target, err := plan.ArchivePlan(planDir)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Archived %s to %s\n", filepath.Base(planDir), target)
+	return nil
+}
+
+// runPlanDelete implements `rafa plan delete [--yes] <name>`. Without --yes
+// it asks for confirmation on stdin.
+func runPlanDelete(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("delete")
+	yes := fs.Bool("yes", false, "Delete without asking for confirmation")
+	if err := parseCommandFlags(fs, "rafa plan delete [--yes] <name>", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("expected exactly one plan name")
+	}
+
+	planDir, err := plan.FindPlanFolder(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if !*yes && !confirm(stdout, fmt.Sprintf("Delete %s and all of its logs?", filepath.Base(planDir))) {
+		fmt.Fprintln(stdout, "Aborted.")
+		return nil
+	}
+
+	if err := plan.DeletePlan(planDir); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Deleted %s\n", filepath.Base(planDir))
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+)
+
+// runPromptRender implements `rafa prompt render <plan> <task>`, which prints
+// the prompt the next attempt of a task would get, rendered from the task
+// template and any override in .rafa/prompts, with the project context
+// configured in .rafa/config.json.
+func runPromptRender(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("prompt render")
+	if err := parseCommandFlags(fs, "rafa prompt render <plan> <task>", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return newUsageError("expected a plan name and a task ID")
+	}
+
+	planDir, err := plan.FindPlanFolder(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	p, err := plan.ReadPlan(planDir)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(".rafa")
+	if err != nil {
+		return err
+	}
+	prompts, err := prompt.Load(".rafa")
+	if err != nil {
+		return err
+	}
+	text, err := executor.New(planDir, p).
+		WithPrompts(prompts).
+		WithProjectContext(cfg.Context).
+		RenderPrompt(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, text)
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/testutil"
+)
+
+const promptTestPlan = `{"schemaVersion": 2, "id": "abc123", "name": "auth", "description": "Add auth", "sourceFile": "docs/auth.md", "status": "in_progress", "tasks": [{"id": "t01", "title": "Add login", "description": "Create the login endpoint", "acceptanceCriteria": ["Tests pass"], "status": "pending", "attempts": 1}]}`
+
+func TestPromptRender(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeTestPlan(t, "abc123-auth", promptTestPlan)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"prompt", "render", "auth", "t01"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	for _, want := range []string{"Plan: auth\nDescription: Add auth\nSource: docs/auth.md", "**Title**: Add login", "**Attempt**: 2 of 5", "1. Tests pass"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestPromptRender_Override(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeTestPlan(t, "abc123-auth", promptTestPlan)
+	if err := os.MkdirAll(filepath.Join(".rafa", "prompts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := "{{.Plan.Name}}: {{.Task.Title}} ({{.TaskNumber}}/{{.TaskCount}})\n"
+	if err := os.WriteFile(filepath.Join(".rafa", "prompts", "task.tmpl"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"prompt", "render", "auth", "t01"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if stdout.String() != "auth: Add login (1/1)\n" {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+}
+
+func TestPromptRender_DoesNotMigratePlan(t *testing.T) {
+	testutil.SetupTestDir(t)
+	legacy := `{"id": "abc123", "name": "auth", "tasks": [{"id": "t01", "title": "Add login", "description": "", "acceptanceCriteria": []}]}`
+	dir := writeTestPlan(t, "abc123-auth", legacy)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"prompt", "render", "auth", "t01"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "plan.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != legacy {
+		t.Errorf("expected plan.json to be left unchanged, got %s", data)
+	}
+}
+
+func TestPromptRender_Errors(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeTestPlan(t, "abc123-auth", promptTestPlan)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"prompt", "render", "auth"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 without a task, got %d", code)
+	}
+	if code := runCommand([]string{"prompt", "render", "missing", "t01"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for a missing plan, got %d", code)
+	}
+	if code := runCommand([]string{"prompt", "render", "auth", "t99"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for a missing task, got %d", code)
+	}
+}
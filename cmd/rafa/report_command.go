@@ -0,0 +1,35 @@
+package main
+
+import (
+	"io"
+
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/report"
+)
+
+// runReport implements `rafa report <name>`, which prints run analytics for a
+// plan (durations, attempts, retries, failure hot spots, tokens and cost) as
+// Markdown for pull request descriptions, or as JSON.
+func runReport(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("report")
+	jsonFlag := fs.Bool("json", false, "Print the report as JSON instead of Markdown")
+	if err := parseCommandFlags(fs, "rafa report [--json] <name>", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("expected exactly one plan name")
+	}
+
+	planDir, err := plan.FindPlanFolder(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	r, err := report.Build(planDir)
+	if err != nil {
+		return err
+	}
+	if *jsonFlag {
+		return r.WriteJSON(stdout)
+	}
+	return r.WriteMarkdown(stdout)
+}
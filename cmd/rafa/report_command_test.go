@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/report"
+	"github.com/pablasso/rafa/internal/testutil"
+)
+
+func writeReportPlan(t *testing.T) {
+	t.Helper()
+	dir := writeTestPlan(t, "abc123-auth", `{"schemaVersion": 2, "id": "abc123", "name": "auth", "status": "completed", "tasks": [{"id": "t01", "title": "Harden sessions", "status": "completed"}]}`)
+	progress := `{"timestamp":"2024-01-15T10:00:00Z","event":"plan_started","data":{"plan_id":"abc123"}}
+{"timestamp":"2024-01-15T10:00:00Z","event":"task_started","data":{"task_id":"t01","attempt":1}}
+{"timestamp":"2024-01-15T10:02:00Z","event":"task_completed","data":{"task_id":"t01"}}
+{"timestamp":"2024-01-15T10:02:05Z","event":"plan_completed","data":{}}
+`
+	if err := os.WriteFile(filepath.Join(dir, "progress.log"), []byte(progress), 0644); err != nil {
+		t.Fatalf("failed to write progress.log: %v", err)
+	}
+}
+
+func TestReport_Markdown(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeReportPlan(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"report", "auth"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	for _, want := range []string{"## Run report: auth", "| Wall time | 02:05 |", "| t01 | Harden sessions | completed | 1 | 1 | 02:00 |"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestReport_JSON(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeReportPlan(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"report", "--json", "auth"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	var r report.Report
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if r.PlanID != "abc123-auth" || r.Runs != 1 || len(r.Tasks) != 1 || r.Tasks[0].DurationSeconds != 120 {
+		t.Errorf("unexpected report: %+v", r)
+	}
+}
+
+func TestReport_Errors(t *testing.T) {
+	testutil.SetupTestDir(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"report"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 without a plan, got %d", code)
+	}
+	if code := runCommand([]string{"report", "missing"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for a missing plan, got %d", code)
+	}
+}
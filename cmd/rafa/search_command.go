@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pablasso/rafa/internal/search"
+)
+
+const defaultSearchLimit = 50
+
+// runSearch implements `rafa search <query>`, a full-text search over plan
+// metadata, agent transcripts and progress logs. Every term of the query must
+// match; hits print as "<plan> <task> #<attempt> <kind> <snippet>".
+func runSearch(args []string, stdout io.Writer) error {
+	fs := newCommandFlagSet("search")
+	jsonFlag := fs.Bool("json", false, "Print hits as a JSON array")
+	planFlag := fs.String("plan", "", "Only search this plan")
+	kindFlag := fs.String("kind", "", "Only return hits of this kind: plan|task|tool|text|progress")
+	limitFlag := fs.Int("limit", defaultSearchLimit, "Maximum number of hits (0 for no limit)")
+	if err := parseCommandFlags(fs, "rafa search [--json] [--plan name] [--kind kind] [--limit n] <query>", args, stdout); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return newUsageError("expected a search query")
+	}
+	if *limitFlag < 0 {
+		return newUsageError("--limit must not be negative")
+	}
+
+	query := search.Query{Text: strings.Join(fs.Args(), " "), Limit: *limitFlag}
+	if *kindFlag != "" {
+		kind, err := search.ParseKind(*kindFlag)
+		if err != nil {
+			return newUsageError("%v", err)
+		}
+		query.Kinds = []search.Kind{kind}
+	}
+
+	dirs, err := resolvePlanDirs(*planFlag)
+	if err != nil {
+		return err
+	}
+	idx, err := search.Build(dirs)
+	if err != nil {
+		return err
+	}
+	hits := idx.Search(query)
+
+	if *jsonFlag {
+		if hits == nil {
+			hits = []search.Hit{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(hits)
+	}
+
+	if len(hits) == 0 {
+		fmt.Fprintln(stdout, "No matches.")
+		return nil
+	}
+	for _, h := range hits {
+		fmt.Fprintf(stdout, "%s  %-8s  %-8s  %s\n", h.PlanID, formatHitLocation(h), h.Kind, h.Snippet)
+	}
+	return nil
+}
+
+// formatHitLocation returns "<task> #<attempt>", "<task>" or "-" for
+// plan-level hits.
+func formatHitLocation(h search.Hit) string {
+	switch {
+	case h.TaskID == "":
+		return "-"
+	case h.Attempt > 0:
+		return fmt.Sprintf("%s #%d", h.TaskID, h.Attempt)
+	default:
+		return h.TaskID
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/search"
+	"github.com/pablasso/rafa/internal/testutil"
+)
+
+const searchCommandOutput = `=== Task t01, Attempt 1 ===
+Started: 2024-01-15T10:00:00Z
+
+{"type":"assistant","message":{"content":[{"id":"tool_1","type":"tool_use","name":"Edit","input":{"file_path":"auth/session.go"}}]}}
+
+=== Task t01: SUCCESS ===
+`
+
+func writeSearchPlan(t *testing.T) {
+	t.Helper()
+	dir := writeTestPlan(t, "abc123-auth", `{"schemaVersion": 2, "id": "abc123", "name": "auth", "tasks": [{"id": "t01", "title": "Harden sessions", "status": "completed"}]}`)
+	if err := os.WriteFile(filepath.Join(dir, "output.log"), []byte(searchCommandOutput), 0644); err != nil {
+		t.Fatalf("failed to write output.log: %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeSearchPlan(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"search", "auth/session.go"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "abc123-auth  t01 #1    tool      Edit: auth/session.go") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"search", "nothing-matches"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "No matches.") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestSearch_JSON(t *testing.T) {
+	testutil.SetupTestDir(t)
+	writeSearchPlan(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"search", "--json", "--kind", "task", "--plan", "auth", "harden"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	var hits []search.Hit
+	if err := json.Unmarshal(stdout.Bytes(), &hits); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	if len(hits) != 1 || hits[0].TaskID != "t01" || hits[0].Kind != search.KindTask || hits[0].Position != 1 {
+		t.Errorf("unexpected hits: %+v", hits)
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"search", "--json", "zzz"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d (stderr: %s)", code, stderr.String())
+	}
+	if strings.TrimSpace(stdout.String()) != "[]" {
+		t.Errorf("expected an empty JSON array, got %s", stdout.String())
+	}
+}
+
+func TestSearch_UsageErrors(t *testing.T) {
+	testutil.SetupTestDir(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"search"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 without a query, got %d", code)
+	}
+	if code := runCommand([]string{"search", "--kind", "bogus", "x"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 for an unknown kind, got %d", code)
+	}
+}
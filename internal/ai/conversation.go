@@ -14,9 +14,11 @@ import (
 
 // ConversationConfig holds settings for a conversation session.
 type ConversationConfig struct {
-	SessionID     string // Claude session ID for --resume
-	InitialPrompt string // First message to send
-	SkillName     string // Skill name for context (used by TUI layer, not by invoke)
+	SessionID     string      // Claude session ID for --resume
+	InitialPrompt string      // First message to send
+	SkillName     string      // Skill name for context (used by TUI layer, not by invoke)
+	Permissions   Permissions // Tool permission policy; the zero value skips permission checks
+	Env           EnvPolicy   // Environment policy for the claude process
 }
 
 // StreamEvent represents a parsed event from Claude's stream-json output.
@@ -34,9 +36,11 @@ type StreamEvent struct {
 // Conversation manages a multi-turn conversation with Claude CLI.
 // Each message is a separate `claude -p` invocation with --resume.
 type Conversation struct {
-	sessionID string
-	ctx       context.Context
-	cancel    context.CancelFunc
+	sessionID   string
+	permissions Permissions
+	env         EnvPolicy
+	ctx         context.Context
+	cancel      context.CancelFunc
 
 	// Current invocation state
 	mu     sync.Mutex
@@ -54,9 +58,11 @@ func StartConversation(ctx context.Context, config ConversationConfig) (*Convers
 	ctx, cancel := context.WithCancel(ctx)
 
 	conv := &Conversation{
-		sessionID: config.SessionID,
-		ctx:       ctx,
-		cancel:    cancel,
+		sessionID:   config.SessionID,
+		permissions: config.Permissions,
+		env:         config.Env,
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 
 	events, err := conv.invoke(config.InitialPrompt)
@@ -78,22 +84,34 @@ func (c *Conversation) SendMessage(message string) (<-chan StreamEvent, error) {
 	return c.invoke(message)
 }
 
-// invoke runs a single claude -p invocation and returns the event stream.
-func (c *Conversation) invoke(prompt string) (<-chan StreamEvent, error) {
+// args returns the claude CLI arguments for one invocation.
+func (c *Conversation) args(prompt string) []string {
 	args := []string{
 		"-p", prompt,
 		"--output-format", "stream-json",
 		"--verbose",
 		"--include-partial-messages",
-		"--dangerously-skip-permissions",
 	}
+	args = append(args, c.permissions.Args(nil, nil)...)
 
 	if c.sessionID != "" {
 		args = append(args, "--resume", c.sessionID)
 	}
+	return args
+}
+
+// invoke runs a single claude -p invocation and returns the event stream.
+func (c *Conversation) invoke(prompt string) (<-chan StreamEvent, error) {
+	args := c.args(prompt)
+	env, _, err := c.env.Environ(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent environment: %w", err)
+	}
+	redactor := c.env.Redactor(env)
 
 	c.mu.Lock()
 	c.cmd = CommandContext(c.ctx, "claude", args...)
+	c.cmd.Env = env
 	c.cmd.Stderr = os.Stderr
 
 	stdout, err := c.cmd.StdoutPipe()
@@ -118,7 +136,7 @@ func (c *Conversation) invoke(prompt string) (<-chan StreamEvent, error) {
 		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
 
 		for scanner.Scan() {
-			line := scanner.Text()
+			line := redactor.Redact(scanner.Text())
 
 			event := parseStreamEvent(line)
 			if event.Type != "" {
@@ -275,3 +275,37 @@ func TestConversationResume_NoSessionErrorsOnSend(t *testing.T) {
 		t.Errorf("error should mention 'no session ID', got: %v", err)
 	}
 }
+
+// TestConversation_EnvPolicyAndRedaction tests that the claude process gets
+// the policy's environment and that secrets are redacted from its events.
+func TestConversation_EnvPolicyAndRedaction(t *testing.T) {
+	originalCommandContext := CommandContext
+	originalLookPath := LookPath
+	t.Cleanup(func() {
+		CommandContext = originalCommandContext
+		LookPath = originalLookPath
+	})
+	t.Setenv("RAFA_TEST_TOKEN", "tok-1234567890")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret")
+
+	LookPath = func(file string) (string, error) {
+		return "/usr/bin/claude", nil
+	}
+	CommandContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		script := `printf '{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"token=%s aws=%s"}}}\n' "$RAFA_TEST_TOKEN" "$AWS_SECRET_ACCESS_KEY"`
+		return exec.CommandContext(ctx, "sh", "-c", script)
+	}
+
+	_, events, err := StartConversation(context.Background(), ConversationConfig{InitialPrompt: "hi"})
+	if err != nil {
+		t.Fatalf("StartConversation failed: %v", err)
+	}
+	var text strings.Builder
+	for event := range events {
+		text.WriteString(event.Text)
+	}
+
+	if got := text.String(); got != "token=[REDACTED] aws=" {
+		t.Errorf("expected a redacted token and no cloud credentials, got %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -477,3 +478,19 @@ func TestParseStreamEvent_StreamReadError(t *testing.T) {
 		})
 	}
 }
+
+func TestConversationArgs_Permissions(t *testing.T) {
+	c := &Conversation{sessionID: "abc", permissions: ReadOnlyPermissions()}
+	args := strings.Join(c.args("hi"), " ")
+	if strings.Contains(args, "--dangerously-skip-permissions") {
+		t.Errorf("expected a restricted conversation not to skip permissions: %s", args)
+	}
+	if !strings.Contains(args, "--allowedTools Read(./**),Grep(./**),Glob(./**)") || !strings.Contains(args, "--resume abc") {
+		t.Errorf("unexpected args: %s", args)
+	}
+
+	c = &Conversation{}
+	if args := strings.Join(c.args("hi"), " "); !strings.Contains(args, "--dangerously-skip-permissions") {
+		t.Errorf("expected the default policy to skip permissions: %s", args)
+	}
+}
@@ -0,0 +1,239 @@
+package ai
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvDeny are the host variables kept from the agent when a policy
+// doesn't set Deny: cloud and infrastructure credentials.
+var DefaultEnvDeny = []string{
+	"AWS_*",
+	"AZURE_*",
+	"ARM_*",
+	"GOOGLE_APPLICATION_CREDENTIALS",
+	"GOOGLE_CREDENTIALS",
+	"CLOUDSDK_*",
+	"DIGITALOCEAN_*",
+	"HCLOUD_TOKEN",
+	"VAULT_TOKEN",
+}
+
+// DefaultEnvRedact match the variables whose values are redacted from agent
+// output when a policy doesn't set Redact.
+var DefaultEnvRedact = []string{
+	"*KEY*",
+	"*TOKEN*",
+	"*SECRET*",
+	"*PASSWORD*",
+	"*PASSWD*",
+	"*CREDENTIAL*",
+}
+
+// minSecretLen is the shortest value redacted. Shorter values, such as a
+// flag named like a secret set to 1, would mangle the output.
+const minSecretLen = 8
+
+// Redacted replaces secret values in agent output.
+const Redacted = "[REDACTED]"
+
+// EnvPolicy controls the environment of agent processes. Patterns are
+// variable names where * matches any characters, compared case-insensitively.
+type EnvPolicy struct {
+	// Allow lists the host variables passed to the agent. Empty passes all
+	// of them, except denied ones.
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists host variables never passed, even when allowed. Unset
+	// means DefaultEnvDeny; an empty list denies none.
+	Deny []string `json:"deny,omitempty"`
+	// Files are .env files whose variables are added to the agent's
+	// environment, relative to the repository root. Missing files are
+	// skipped.
+	Files []string `json:"files,omitempty"`
+	// Redact matches the variables whose values are replaced with
+	// [REDACTED] in the agent's output. Unset means DefaultEnvRedact.
+	Redact []string `json:"redact,omitempty"`
+}
+
+// InDir returns p with its relative Files resolved against dir, the
+// repository root, so they don't depend on where rafa was started.
+func (p EnvPolicy) InDir(dir string) EnvPolicy {
+	if len(p.Files) == 0 {
+		return p
+	}
+	files := make([]string, len(p.Files))
+	for i, f := range p.Files {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(dir, f)
+		}
+		files[i] = f
+	}
+	p.Files = files
+	return p
+}
+
+// Environ returns the environment for an agent process: the host
+// environment filtered by the policy, with the variables from the policy's
+// files and then extra added over it. It also returns the names of the
+// added variables, sorted.
+func (p EnvPolicy) Environ(extra map[string]string) ([]string, []string, error) {
+	vars := make(map[string]string)
+	for _, file := range p.Files {
+		fileVars, err := LoadEnvFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		maps.Copy(vars, fileVars)
+	}
+	maps.Copy(vars, extra)
+
+	env := p.filter(os.Environ())
+	names := slices.Collect(maps.Keys(vars))
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+vars[name])
+	}
+	return env, names, nil
+}
+
+// filter returns the entries of environ the policy lets through.
+func (p EnvPolicy) filter(environ []string) []string {
+	deny := p.Deny
+	if deny == nil {
+		deny = DefaultEnvDeny
+	}
+	var env []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if len(p.Allow) > 0 && !matchEnvName(p.Allow, name) {
+			continue
+		}
+		if matchEnvName(deny, name) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// Redactor returns a redactor for the secret values in env and in the host
+// environment, including variables the agent doesn't get.
+func (p EnvPolicy) Redactor(env []string) *Redactor {
+	patterns := p.Redact
+	if patterns == nil {
+		patterns = DefaultEnvRedact
+	}
+	var secrets []string
+	for _, kv := range append(os.Environ(), env...) {
+		name, value, _ := strings.Cut(kv, "=")
+		if matchEnvName(patterns, name) {
+			secrets = append(secrets, value)
+		}
+	}
+	return NewRedactor(secrets)
+}
+
+// matchEnvName reports whether name matches any of patterns.
+func matchEnvName(patterns []string, name string) bool {
+	name = strings.ToUpper(name)
+	for _, pattern := range patterns {
+		// Names have no slashes, so path.Match's * matches any characters.
+		if ok, _ := path.Match(strings.ToUpper(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadEnvFile reads KEY=VALUE lines from a .env file. Blank lines, comments
+// and an "export " prefix are ignored, and quoted values are unquoted.
+func LoadEnvFile(name string) (map[string]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", name, n)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid quoted value: %w", name, n, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return vars, nil
+}
+
+// Redactor replaces secret values in text. A nil Redactor changes nothing.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// NewRedactor returns a redactor for secrets. Values shorter than eight
+// characters are ignored. It returns nil when there is nothing to redact.
+func NewRedactor(secrets []string) *Redactor {
+	seen := make(map[string]bool)
+	var values []string
+	for _, s := range secrets {
+		if len(s) < minSecretLen || seen[s] {
+			continue
+		}
+		seen[s] = true
+		values = append(values, s)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	// Longest first, so a secret containing another is replaced whole.
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	var pairs []string
+	for _, v := range values {
+		pairs = append(pairs, v, Redacted)
+		// Agent output is mostly JSON, where a value may appear escaped.
+		if quoted := strconv.Quote(v); quoted[1:len(quoted)-1] != v {
+			pairs = append(pairs, quoted[1:len(quoted)-1], Redacted)
+		}
+	}
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+// Redact returns s with every secret replaced.
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
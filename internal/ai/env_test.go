@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestEnvPolicy_Environ(t *testing.T) {
+	t.Setenv("RAFA_TEST_KEEP", "1")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret")
+	t.Setenv("RAFA_TEST_DROP", "1")
+
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("# local\nexport DB_URL=\"postgres://db\"\nPLAN=file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := EnvPolicy{Deny: []string{"rafa_test_drop"}, Files: []string{".env", "missing.env"}}.InDir(dir)
+	env, names, err := p.Environ(map[string]string{"PLAN": "agent"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, want := range []string{"RAFA_TEST_KEEP=1", "AWS_SECRET_ACCESS_KEY=aws-secret", "DB_URL=postgres://db", "PLAN=agent"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("expected %s in the environment", want)
+		}
+	}
+	if slices.Contains(env, "RAFA_TEST_DROP=1") || slices.Contains(env, "PLAN=file") {
+		t.Errorf("expected denied and overridden variables to be left out, got %v", env)
+	}
+	if !slices.Equal(names, []string{"DB_URL", "PLAN"}) {
+		t.Errorf("expected the added variable names, got %v", names)
+	}
+}
+
+func TestEnvPolicy_DefaultDenyAndAllow(t *testing.T) {
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret")
+	t.Setenv("RAFA_TEST_KEEP", "1")
+
+	env, _, err := EnvPolicy{}.Environ(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(env, "AWS_SECRET_ACCESS_KEY=aws-secret") {
+		t.Error("expected cloud credentials to be denied by default")
+	}
+
+	env, _, err = EnvPolicy{Allow: []string{"RAFA_TEST_*"}}.Environ(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(env, []string{"RAFA_TEST_KEEP=1"}) {
+		t.Errorf("expected only allowed variables, got %v", env)
+	}
+}
+
+func TestLoadEnvFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("OK=1\nnot a variable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEnvFile(path); err == nil {
+		t.Error("expected an error for a line without =")
+	}
+	if _, _, err := (EnvPolicy{Files: []string{path}}).Environ(nil); err == nil {
+		t.Error("expected Environ to report the invalid file")
+	}
+}
+
+func TestEnvPolicy_Redactor(t *testing.T) {
+	t.Setenv("RAFA_TEST_API_KEY", "sk-host-secret")
+
+	r := EnvPolicy{}.Redactor([]string{"SERVICE_TOKEN=tok\"en-value", "FEATURE_TOKEN=1", "HOME_DIR=/home/someone"})
+	got := r.Redact(`key sk-host-secret, token "tok\"en-value", flag 1, home /home/someone`)
+	want := `key [REDACTED], token "[REDACTED]", flag 1, home /home/someone`
+	if got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+
+	r = EnvPolicy{Redact: []string{"HOME_DIR"}}.Redactor([]string{"HOME_DIR=/home/someone"})
+	if got := r.Redact("sk-host-secret /home/someone"); got != "sk-host-secret [REDACTED]" {
+		t.Errorf("expected only the configured variables to be redacted, got %q", got)
+	}
+}
+
+func TestNewRedactor_Nothing(t *testing.T) {
+	r := NewRedactor([]string{"short", ""})
+	if r != nil {
+		t.Fatal("expected no redactor for values too short to redact")
+	}
+	if got := r.Redact("short"); got != "short" {
+		t.Errorf("expected a nil redactor to change nothing, got %q", got)
+	}
+}
@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"strings"
+)
+
+// Permission modes.
+const (
+	// PermissionsSkip skips every permission check
+	// (--dangerously-skip-permissions). It is the mode of the zero
+	// Permissions.
+	PermissionsSkip = "skip"
+	// PermissionsRestricted allows only the listed tools, commands and
+	// paths. Anything else is denied, since a non-interactive agent can't
+	// ask.
+	PermissionsRestricted = "restricted"
+)
+
+// fileEditTools are the tools that change files, which Paths scopes.
+var fileEditTools = map[string]bool{
+	"Edit":         true,
+	"MultiEdit":    true,
+	"Write":        true,
+	"NotebookEdit": true,
+}
+
+// fileReadTools are the tools that read files, which are scoped to the
+// repository.
+var fileReadTools = map[string]bool{
+	"Read": true,
+	"Grep": true,
+	"Glob": true,
+}
+
+// repoPattern matches every path in the repository.
+const repoPattern = "./**"
+
+// Permissions is a tool permission policy for the agent, translated into
+// the Claude CLI's allow and deny settings.
+type Permissions struct {
+	// Mode is PermissionsSkip (the default) or PermissionsRestricted. The
+	// other fields only apply when restricted.
+	Mode string `json:"mode,omitempty"`
+	// Tools are the tools the agent may use, such as Read, Grep, Edit or
+	// WebFetch. The file-reading tools (Read, Grep, Glob) are limited to the
+	// repository. Full Claude permission rules like "WebFetch(domain:go.dev)"
+	// are passed through.
+	Tools []string `json:"tools,omitempty"`
+	// Bash are the shell commands the agent may run, as Claude Bash rules:
+	// "go test:*" allows go test with any arguments.
+	Bash []string `json:"bash,omitempty"`
+	// Deny are tools or rules that are always denied, even when allowed
+	// above, such as "Bash(git push:*)".
+	Deny []string `json:"deny,omitempty"`
+	// Paths limit the file-editing tools (Edit, MultiEdit, Write,
+	// NotebookEdit) to gitignore-style patterns relative to the repository
+	// root. Empty means the whole repository.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// ReadOnlyPermissions lets the agent read and search the repository but
+// not change it or run commands.
+func ReadOnlyPermissions() Permissions {
+	return Permissions{
+		Mode:  PermissionsRestricted,
+		Tools: []string{"Read", "Grep", "Glob"},
+	}
+}
+
+// Restricted reports whether p limits the agent's tools. A mode other than
+// the two known ones is treated as restricted, so a typo doesn't lift the
+// limits.
+func (p Permissions) Restricted() bool {
+	return p.Mode != "" && p.Mode != PermissionsSkip
+}
+
+// Allowed returns the Claude permission rules p allows, or nil when p
+// isn't restricted.
+func (p Permissions) Allowed() []string {
+	if !p.Restricted() {
+		return nil
+	}
+	paths := p.Paths
+	if len(paths) == 0 {
+		paths = []string{repoPattern}
+	}
+	var rules []string
+	for _, tool := range p.Tools {
+		if fileReadTools[tool] {
+			rules = append(rules, tool+"("+repoPattern+")")
+			continue
+		}
+		if !fileEditTools[tool] {
+			rules = append(rules, tool)
+			continue
+		}
+		for _, path := range paths {
+			rules = append(rules, tool+"(./"+strings.TrimPrefix(path, "./")+")")
+		}
+	}
+	for _, command := range p.Bash {
+		rules = append(rules, "Bash("+command+")")
+	}
+	return rules
+}
+
+// Args returns the Claude CLI arguments for p, with extra allowed and
+// denied rules (such as per-task settings) added to the policy's.
+func (p Permissions) Args(allow, deny []string) []string {
+	if !p.Restricted() {
+		args := []string{"--dangerously-skip-permissions"}
+		if len(allow) > 0 {
+			args = append(args, "--allowedTools", strings.Join(allow, ","))
+		}
+		if len(deny) > 0 {
+			args = append(args, "--disallowedTools", strings.Join(deny, ","))
+		}
+		return args
+	}
+
+	var args []string
+	if allowed := append(p.Allowed(), allow...); len(allowed) > 0 {
+		args = append(args, "--allowedTools", strings.Join(allowed, ","))
+	}
+	if denied := append(append([]string(nil), p.Deny...), deny...); len(denied) > 0 {
+		args = append(args, "--disallowedTools", strings.Join(denied, ","))
+	}
+	return args
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPermissions_ArgsSkip(t *testing.T) {
+	var p Permissions
+	if p.Restricted() {
+		t.Error("expected the zero policy not to be restricted")
+	}
+	want := []string{"--dangerously-skip-permissions"}
+	if got := p.Args(nil, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() = %v, want %v", got, want)
+	}
+
+	want = []string{"--dangerously-skip-permissions", "--allowedTools", "WebFetch", "--disallowedTools", "Bash(rm:*)"}
+	if got := p.Args([]string{"WebFetch"}, []string{"Bash(rm:*)"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() with extras = %v, want %v", got, want)
+	}
+}
+
+func TestPermissions_ArgsRestricted(t *testing.T) {
+	p := Permissions{
+		Mode:  PermissionsRestricted,
+		Tools: []string{"Read", "Edit", "Write"},
+		Bash:  []string{"go test:*", "make lint"},
+		Deny:  []string{"Bash(git push:*)"},
+		Paths: []string{"internal/**", "./docs/*.md"},
+	}
+	want := []string{
+		"--allowedTools", "Read(./**),Edit(./internal/**),Edit(./docs/*.md),Write(./internal/**),Write(./docs/*.md),Bash(go test:*),Bash(make lint),WebFetch",
+		"--disallowedTools", "Bash(git push:*)",
+	}
+	if got := p.Args([]string{"WebFetch"}, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() = %v, want %v", got, want)
+	}
+}
+
+func TestPermissions_EditDefaultsToRepository(t *testing.T) {
+	p := Permissions{Mode: PermissionsRestricted, Tools: []string{"MultiEdit"}}
+	want := []string{"MultiEdit(./**)"}
+	if got := p.Allowed(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Allowed() = %v, want %v", got, want)
+	}
+}
+
+func TestPermissions_UnknownModeIsRestricted(t *testing.T) {
+	p := Permissions{Mode: "restrcited"}
+	if !p.Restricted() {
+		t.Error("expected an unknown mode to be restricted")
+	}
+	if got := p.Args(nil, nil); len(got) != 0 {
+		t.Errorf("expected no arguments for a policy allowing nothing, got %v", got)
+	}
+}
+
+func TestReadOnlyPermissions(t *testing.T) {
+	want := []string{"--allowedTools", "Read(./**),Grep(./**),Glob(./**)"}
+	if got := ReadOnlyPermissions().Args(nil, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() = %v, want %v", got, want)
+	}
+}
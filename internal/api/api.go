@@ -0,0 +1,399 @@
+// Package api serves plan status and a live event stream over HTTP so runs
+// started on one machine can be followed from another, either from scripts or
+// from the embedded web dashboard. Read endpoints are open; the run control
+// endpoints (pause, resume, cancel, skip) require the server's token.
+//
+//	GET  /                   web dashboard
+//	GET  /api/plans          plan list (as on the Run Plan screen)
+//	GET  /api/plans/{id}     one plan with its tasks
+//	GET  /api/plans/{id}/report  run analytics (as from rafa report --json)
+//	GET  /api/run            the active run, if any
+//	GET  /api/events         Server-Sent Events stream of executor events and output
+//	POST /api/run/pause      hold before the next attempt
+//	POST /api/run/resume     end a pause
+//	POST /api/run/cancel     stop the run
+//	POST /api/run/skip       stop the running task and move on
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/report"
+)
+
+// TokenEnvVar supplies the control token when no --api-token flag is given.
+const TokenEnvVar = "RAFA_API_TOKEN"
+
+// keepAliveInterval is how often idle event streams get a comment line, so
+// proxies and phones don't drop the connection.
+const keepAliveInterval = 15 * time.Second
+
+// ActiveRun is the plan run the control endpoints act on.
+type ActiveRun struct {
+	PlanID  string // Plan folder name ("shortID-name")
+	Control *executor.Control
+	Cancel  context.CancelFunc
+}
+
+// PlanSummary is a plan as listed on the Run Plan screen.
+type PlanSummary struct {
+	ID        string `json:"id"` // Plan folder name ("shortID-name")
+	ShortID   string `json:"shortId"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	TaskCount int    `json:"taskCount"`
+	Completed int    `json:"completed"`
+	Locked    bool   `json:"locked"` // A run holds the plan's lock
+}
+
+// TaskStatus is one task of a plan.
+type TaskStatus struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+}
+
+// PlanStatus is a plan with its tasks.
+type PlanStatus struct {
+	PlanSummary
+	Tasks []TaskStatus `json:"tasks"`
+}
+
+// RunStatus describes the active run.
+type RunStatus struct {
+	Running bool   `json:"running"`
+	PlanID  string `json:"planId,omitempty"`
+	Paused  bool   `json:"paused"`
+}
+
+// Server is the HTTP API.
+type Server struct {
+	rafaDir string
+	token   string
+	hub     *Hub
+
+	mu  sync.Mutex
+	run *ActiveRun
+
+	httpServer *http.Server
+	listener   net.Listener
+	closing    chan struct{} // Closed by Close to end event streams
+	closeOnce  sync.Once
+}
+
+// New creates a Server for the plans in rafaDir. An empty token disables the
+// control endpoints.
+func New(rafaDir, token string) *Server {
+	return &Server{
+		rafaDir: rafaDir,
+		token:   token,
+		hub:     NewHub(),
+		closing: make(chan struct{}),
+	}
+}
+
+// Hub returns the event hub to feed with executor events and output.
+func (s *Server) Hub() *Hub {
+	return s.hub
+}
+
+// SetRun sets the run the control endpoints act on; nil clears it.
+func (s *Server) SetRun(run *ActiveRun) {
+	s.mu.Lock()
+	s.run = run
+	s.mu.Unlock()
+	if run != nil {
+		s.hub.Publish(EventRunStarted, map[string]interface{}{"planId": run.PlanID})
+	} else {
+		s.hub.Publish(EventRunEnded, nil)
+	}
+}
+
+func (s *Server) activeRun() *ActiveRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.run
+}
+
+// Handler returns the API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/plans", s.handlePlans)
+	mux.HandleFunc("GET /api/plans/{id}", s.handlePlan)
+	mux.HandleFunc("GET /api/plans/{id}/report", s.handleReport)
+	mux.HandleFunc("GET /api/run", s.handleRun)
+	mux.HandleFunc("GET /api/events", s.handleEvents)
+	mux.HandleFunc("POST /api/run/{action}", s.requireToken(s.handleControl))
+	mux.Handle("GET /", dashboardHandler())
+	return mux
+}
+
+// Start listens on addr and serves the API in the background.
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.listener = listener
+	s.httpServer = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	// Serve only fails once the listener is closed; the TUI owns the
+	// terminal, so there is nowhere useful to report it.
+	go s.httpServer.Serve(listener)
+	return nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Close ends open event streams and stops the server.
+func (s *Server) Close() error {
+	s.closeOnce.Do(func() { close(s.closing) })
+	if s.httpServer == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
+}
+
+func (s *Server) handlePlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := s.listPlans()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	summaries := make([]PlanSummary, len(plans))
+	for i, p := range plans {
+		summaries[i] = p.PlanSummary
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
+	if p, ok := s.findPlan(w, r.PathValue("id")); ok {
+		writeJSON(w, http.StatusOK, p)
+	}
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.findPlan(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	rep, err := report.Build(filepath.Join(s.rafaDir, "plans", p.ID))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// findPlan looks up a plan by folder name or short ID, writing an error
+// response if there is none.
+func (s *Server) findPlan(w http.ResponseWriter, id string) (PlanStatus, bool) {
+	plans, err := s.listPlans()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return PlanStatus{}, false
+	}
+	for _, p := range plans {
+		if p.ID == id || p.ShortID == id {
+			return p, true
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("plan %q not found", id))
+	return PlanStatus{}, false
+}
+
+// listPlans reads every plan in .rafa/plans, sorted by folder name.
+func (s *Server) listPlans() ([]PlanStatus, error) {
+	plansDir := filepath.Join(s.rafaDir, "plans")
+	entries, err := os.ReadDir(plansDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []PlanStatus{}, nil
+		}
+		return nil, fmt.Errorf("failed to read plans: %w", err)
+	}
+
+	plans := []PlanStatus{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		planDir := filepath.Join(plansDir, entry.Name())
+		p, err := plan.ReadPlan(planDir)
+		if err != nil {
+			continue
+		}
+		status := PlanStatus{
+			PlanSummary: PlanSummary{
+				ID:        entry.Name(),
+				ShortID:   p.ID,
+				Name:      p.Name,
+				Status:    p.Status,
+				TaskCount: len(p.Tasks),
+				Locked:    isLocked(planDir),
+			},
+			Tasks: make([]TaskStatus, len(p.Tasks)),
+		}
+		for i, t := range p.Tasks {
+			if t.Status == plan.TaskStatusCompleted {
+				status.Completed++
+			}
+			status.Tasks[i] = TaskStatus{ID: t.ID, Title: t.Title, Status: t.Status, Attempts: t.Attempts}
+		}
+		plans = append(plans, status)
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].ID < plans[j].ID })
+	return plans, nil
+}
+
+// isLocked checks if a plan has a live lock holder, treating read errors as
+// locked like the plan list does.
+func isLocked(planDir string) bool {
+	locked, err := plan.NewPlanLock(planDir).IsLocked()
+	if err != nil {
+		return true
+	}
+	return locked
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.runStatus())
+}
+
+func (s *Server) runStatus() RunStatus {
+	run := s.activeRun()
+	if run == nil {
+		return RunStatus{}
+	}
+	return RunStatus{Running: true, PlanID: run.PlanID, Paused: run.Control.Paused()}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	events, unsubscribe := s.hub.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	// Start with the current run so clients don't have to poll /api/run.
+	writeSSE(w, Event{Type: "status", Time: time.Now(), Data: map[string]interface{}{"run": s.runStatus()}})
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			writeSSE(w, ev)
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSE writes ev as a Server-Sent Event named after its type.
+func writeSSE(w http.ResponseWriter, ev Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+}
+
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	run := s.activeRun()
+	if run == nil {
+		writeError(w, http.StatusConflict, "no plan is running")
+		return
+	}
+
+	switch action := r.PathValue("action"); action {
+	case "pause":
+		run.Control.Pause()
+		s.hub.Publish(EventPaused, map[string]interface{}{"planId": run.PlanID})
+	case "resume":
+		run.Control.Resume()
+		s.hub.Publish(EventResumed, map[string]interface{}{"planId": run.PlanID})
+	case "skip":
+		if !run.Control.Skip() {
+			writeError(w, http.StatusConflict, "no task is running")
+			return
+		}
+	case "cancel":
+		// Resume first so a paused executor notices the cancellation.
+		run.Control.Resume()
+		run.Cancel()
+		s.hub.Publish(EventCancelled, map[string]interface{}{"planId": run.PlanID})
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown action %q", action))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.runStatus())
+}
+
+// requireToken rejects requests without the server token, given as a bearer
+// token or a token query parameter.
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			writeError(w, http.StatusForbidden, "run control is disabled; start rafa with --api-token or "+TokenEnvVar)
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if got == "" {
+			got = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid or missing token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
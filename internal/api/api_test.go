@@ -0,0 +1,262 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/report"
+)
+
+const testToken = "s3cret"
+
+func setupAPIPlans(t *testing.T) string {
+	t.Helper()
+	rafaDir := filepath.Join(t.TempDir(), ".rafa")
+	planDir := filepath.Join(rafaDir, "plans", "abc123-auth")
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		t.Fatalf("failed to create plan dir: %v", err)
+	}
+	p := &plan.Plan{
+		SchemaVersion: plan.CurrentSchemaVersion,
+		ID:            "abc123",
+		Name:          "auth",
+		Status:        plan.PlanStatusInProgress,
+		Tasks: []plan.Task{
+			{ID: "t01", Title: "Add login", Status: plan.TaskStatusCompleted, Attempts: 1},
+			{ID: "t02", Title: "Add logout", Status: plan.TaskStatusPending},
+		},
+	}
+	if err := plan.SavePlan(planDir, p); err != nil {
+		t.Fatalf("failed to save plan: %v", err)
+	}
+	return rafaDir
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: invalid JSON: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+func post(t *testing.T, url, token string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServer_Plans(t *testing.T) {
+	server := httptest.NewServer(New(setupAPIPlans(t), testToken).Handler())
+	defer server.Close()
+
+	var plans []PlanSummary
+	if code := getJSON(t, server.URL+"/api/plans", &plans); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("expected 1 plan, got %+v", plans)
+	}
+	want := PlanSummary{ID: "abc123-auth", ShortID: "abc123", Name: "auth", Status: plan.PlanStatusInProgress, TaskCount: 2, Completed: 1}
+	if plans[0] != want {
+		t.Errorf("plan = %+v, want %+v", plans[0], want)
+	}
+
+	var detail PlanStatus
+	if code := getJSON(t, server.URL+"/api/plans/abc123", &detail); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if len(detail.Tasks) != 2 || detail.Tasks[0].Attempts != 1 || detail.Tasks[1].Status != plan.TaskStatusPending {
+		t.Errorf("unexpected tasks: %+v", detail.Tasks)
+	}
+
+	var errResp map[string]string
+	if code := getJSON(t, server.URL+"/api/plans/missing", &errResp); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown plan, got %d", code)
+	}
+}
+
+func TestServer_PlanReport(t *testing.T) {
+	rafaDir := setupAPIPlans(t)
+	server := httptest.NewServer(New(rafaDir, "").Handler())
+	defer server.Close()
+
+	var rep report.Report
+	if code := getJSON(t, server.URL+"/api/plans/abc123/report", &rep); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if rep.PlanID != "abc123-auth" || len(rep.Tasks) != 2 {
+		t.Errorf("unexpected report: %+v", rep)
+	}
+
+	var errResp map[string]string
+	if code := getJSON(t, server.URL+"/api/plans/missing/report", &errResp); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown plan, got %d", code)
+	}
+}
+
+func TestServer_Plans_NoRafaDir(t *testing.T) {
+	server := httptest.NewServer(New(filepath.Join(t.TempDir(), ".rafa"), "").Handler())
+	defer server.Close()
+
+	var plans []PlanSummary
+	if code := getJSON(t, server.URL+"/api/plans", &plans); code != http.StatusOK || plans == nil || len(plans) != 0 {
+		t.Errorf("expected empty list, got %d %+v", code, plans)
+	}
+}
+
+func TestServer_ControlRequiresToken(t *testing.T) {
+	s := New(setupAPIPlans(t), testToken)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	if code := post(t, server.URL+"/api/run/pause", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", code)
+	}
+	if code := post(t, server.URL+"/api/run/pause", "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with wrong token, got %d", code)
+	}
+	if code := post(t, server.URL+"/api/run/pause", testToken); code != http.StatusConflict {
+		t.Errorf("expected 409 with no active run, got %d", code)
+	}
+	if code := post(t, server.URL+"/api/run/pause?token="+testToken, ""); code != http.StatusConflict {
+		t.Errorf("expected query token to be accepted, got %d", code)
+	}
+
+	disabled := httptest.NewServer(New(setupAPIPlans(t), "").Handler())
+	defer disabled.Close()
+	if code := post(t, disabled.URL+"/api/run/pause", ""); code != http.StatusForbidden {
+		t.Errorf("expected 403 when control is disabled, got %d", code)
+	}
+}
+
+func TestServer_ControlActions(t *testing.T) {
+	s := New(setupAPIPlans(t), testToken)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	control := executor.NewControl()
+	cancelled := false
+	s.SetRun(&ActiveRun{PlanID: "abc123-auth", Control: control, Cancel: func() { cancelled = true }})
+
+	if code := post(t, server.URL+"/api/run/pause", testToken); code != http.StatusOK || !control.Paused() {
+		t.Errorf("pause: status %d, paused %v", code, control.Paused())
+	}
+	var status RunStatus
+	getJSON(t, server.URL+"/api/run", &status)
+	if !status.Running || !status.Paused || status.PlanID != "abc123-auth" {
+		t.Errorf("unexpected run status: %+v", status)
+	}
+	if code := post(t, server.URL+"/api/run/resume", testToken); code != http.StatusOK || control.Paused() {
+		t.Errorf("resume: status %d, paused %v", code, control.Paused())
+	}
+	if code := post(t, server.URL+"/api/run/skip", testToken); code != http.StatusConflict {
+		t.Errorf("expected 409 when no task is running, got %d", code)
+	}
+	if code := post(t, server.URL+"/api/run/reboot", testToken); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown action, got %d", code)
+	}
+	if code := post(t, server.URL+"/api/run/cancel", testToken); code != http.StatusOK || !cancelled {
+		t.Errorf("cancel: status %d, cancelled %v", code, cancelled)
+	}
+
+	s.SetRun(nil)
+	getJSON(t, server.URL+"/api/run", &status)
+	if status.Running {
+		t.Errorf("expected no active run, got %+v", status)
+	}
+}
+
+func TestServer_EventStream(t *testing.T) {
+	s := New(setupAPIPlans(t), testToken)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/events", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/events failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	next := func() Event {
+		t.Helper()
+		var name string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("stream ended: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				var ev Event
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
+					t.Fatalf("invalid event data %q: %v", line, err)
+				}
+				if ev.Type != name {
+					t.Fatalf("event name %q does not match type %q", name, ev.Type)
+				}
+				return ev
+			}
+		}
+	}
+
+	if ev := next(); ev.Type != "status" {
+		t.Fatalf("expected initial status event, got %+v", ev)
+	}
+
+	task := &plan.Task{ID: "t02", Title: "Add logout"}
+	hub := s.Hub()
+	hub.OnTaskStart(2, 2, task, 1)
+	hub.Output(executor.AssistantBoundaryChunk)
+	hub.Output("Reading files...\n")
+	hub.OnTaskComplete(task)
+
+	if ev := next(); ev.Type != EventTaskStarted || ev.Data["taskId"] != "t02" || ev.Data["attempt"] != float64(1) {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev := next(); ev.Type != EventOutput || ev.Data["text"] != "Reading files...\n" {
+		t.Errorf("expected output chunk without boundary markers, got %+v", ev)
+	}
+	if ev := next(); ev.Type != EventTaskCompleted {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+}
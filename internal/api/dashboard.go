@@ -0,0 +1,23 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// dashboardFiles is the single-page web dashboard. It only uses the API
+// routes, so it works wherever the API does.
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// dashboardHandler serves the dashboard's static files.
+func dashboardHandler() http.Handler {
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		// The directory is embedded at build time, so this can't happen.
+		panic(err)
+	}
+	return http.FileServerFS(files)
+}
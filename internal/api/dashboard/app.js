@@ -0,0 +1,264 @@
+// Rafa dashboard: mirrors the terminal run view from the HTTP API.
+"use strict";
+
+const maxOutputChars = 200000;
+const maxActivityItems = 500;
+
+const state = {
+  planId: "",        // Plan folder name shown in the run panels
+  running: false,
+  currentTaskId: "",
+  startedAt: null,
+  tokens: 0,
+  cost: 0,
+  tools: new Map(),  // toolId -> activity list item
+};
+
+const $ = (id) => document.getElementById(id);
+
+async function getJSON(path) {
+  const resp = await fetch(path);
+  if (!resp.ok) {
+    throw new Error(`${path}: ${resp.status}`);
+  }
+  return resp.json();
+}
+
+// taskIndicator matches the run view's getTaskIndicator.
+function taskIndicator(status, isCurrent) {
+  switch (status) {
+    case "completed":
+      return ["✓", "success"];
+    case "failed":
+      return ["✗", "error"];
+    case "in_progress":
+    case "running":
+      return isCurrent ? ["▶", "selected"] : ["⣾", ""];
+    default:
+      return ["○", "subtle"];
+  }
+}
+
+function formatDuration(seconds) {
+  seconds = Math.round(seconds);
+  const h = Math.floor(seconds / 3600);
+  const m = Math.floor((seconds % 3600) / 60);
+  const s = seconds % 60;
+  if (h > 0) return `${h}h ${m}m`;
+  if (m > 0) return `${m}m ${s}s`;
+  return `${s}s`;
+}
+
+function formatTokens(tokens) {
+  if (tokens >= 1e6) return `${(tokens / 1e6).toFixed(1)}M`;
+  if (tokens >= 1e3) return `${(tokens / 1e3).toFixed(1)}k`;
+  return String(tokens);
+}
+
+function renderStats() {
+  $("stat-elapsed").textContent = state.startedAt
+    ? formatDuration((Date.now() - state.startedAt) / 1000)
+    : "—";
+  $("stat-tokens").textContent = formatTokens(state.tokens);
+  $("stat-cost").textContent = `$${state.cost.toFixed(2)}`;
+}
+
+async function loadTasks() {
+  if (!state.planId) return;
+  let plan;
+  try {
+    plan = await getJSON(`/api/plans/${encodeURIComponent(state.planId)}`);
+  } catch (err) {
+    return;
+  }
+  $("plan-title").textContent = `${plan.name} (${plan.completed}/${plan.taskCount})`;
+  const list = $("tasks");
+  list.replaceChildren();
+  plan.tasks.forEach((task, i) => {
+    const isCurrent = state.running && task.id === state.currentTaskId;
+    const [symbol, cls] = taskIndicator(task.status, isCurrent);
+    const li = document.createElement("li");
+    const indicator = document.createElement("span");
+    indicator.className = cls;
+    indicator.textContent = symbol;
+    li.append(indicator, ` ${i + 1}. ${task.title}`);
+    li.title = `${task.id} · ${task.status} · ${task.attempts} attempt(s)`;
+    list.append(li);
+  });
+}
+
+async function loadPlans() {
+  let plans;
+  try {
+    plans = await getJSON("/api/plans");
+  } catch (err) {
+    return;
+  }
+  const body = $("plans");
+  body.replaceChildren();
+  for (const plan of plans) {
+    const row = document.createElement("tr");
+    const cells = [plan.name, plan.status, `${plan.completed}/${plan.taskCount}`, "", "", "", ""];
+    for (const text of cells) {
+      const td = document.createElement("td");
+      td.textContent = text;
+      row.append(td);
+    }
+    row.addEventListener("click", () => selectPlan(plan.id, false));
+    body.append(row);
+    loadReport(plan.id, row);
+  }
+}
+
+async function loadReport(planId, row) {
+  let report;
+  try {
+    report = await getJSON(`/api/plans/${encodeURIComponent(planId)}/report`);
+  } catch (err) {
+    return;
+  }
+  const cells = row.children;
+  cells[3].textContent = String(report.totalAttempts);
+  cells[4].textContent = formatDuration(report.wallTimeSeconds);
+  cells[5].textContent = formatTokens(report.inputTokens + report.outputTokens);
+  cells[6].textContent = `$${report.costUsd.toFixed(2)}`;
+}
+
+function selectPlan(planId, running) {
+  if (planId !== state.planId || running) {
+    state.tools.clear();
+    $("activity").replaceChildren();
+    $("output").textContent = "";
+    state.tokens = 0;
+    state.cost = 0;
+    state.startedAt = running ? Date.now() : null;
+  }
+  state.planId = planId;
+  state.running = running;
+  renderStats();
+  loadTasks();
+}
+
+function addActivity(text, cls) {
+  const list = $("activity");
+  const li = document.createElement("li");
+  li.textContent = text;
+  if (cls) li.className = cls;
+  list.append(li);
+  while (list.children.length > maxActivityItems) {
+    list.firstChild.remove();
+  }
+  list.scrollTop = list.scrollHeight;
+  return li;
+}
+
+function appendOutput(text) {
+  const out = $("output");
+  const atBottom = out.scrollTop + out.clientHeight >= out.scrollHeight - 4;
+  let content = out.textContent + text;
+  if (content.length > maxOutputChars) {
+    content = content.slice(content.length - maxOutputChars);
+  }
+  out.textContent = content;
+  if (atBottom) out.scrollTop = out.scrollHeight;
+}
+
+function setRunStatus(text) {
+  $("run-status").textContent = text;
+}
+
+const handlers = {
+  status(data) {
+    const run = data.run || {};
+    if (run.running) {
+      selectPlan(run.planId, true);
+      setRunStatus(run.paused ? `Paused: ${run.planId}` : `Running: ${run.planId}`);
+    } else {
+      setRunStatus("Idle");
+    }
+  },
+  run_started(data) {
+    selectPlan(data.planId, true);
+    setRunStatus(`Running: ${data.planId}`);
+  },
+  run_ended() {
+    state.running = false;
+    state.currentTaskId = "";
+    setRunStatus("Idle");
+    loadTasks();
+    loadPlans();
+  },
+  paused(data) {
+    setRunStatus(`Paused: ${data.planId}`);
+  },
+  resumed(data) {
+    setRunStatus(`Running: ${data.planId}`);
+  },
+  task_started(data) {
+    state.currentTaskId = data.taskId;
+    const label = data.attempt > 1
+      ? `Retrying task ${data.taskNum} (attempt ${data.attempt})`
+      : `Starting task ${data.taskNum}`;
+    addActivity(`── ${label}: ${data.title}`, "subtle");
+    appendOutput(`\n── ${label}: ${data.title}\n`);
+    loadTasks();
+  },
+  task_completed() {
+    loadTasks();
+  },
+  task_failed(data) {
+    addActivity(`✗ Attempt ${data.attempt} failed${data.error ? ": " + data.error : ""}`, "error");
+    loadTasks();
+  },
+  task_skipped(data) {
+    addActivity(`Skipped ${data.taskId}`, "subtle");
+    loadTasks();
+  },
+  plan_completed(data) {
+    addActivity(`✓ Plan complete: ${data.succeeded}/${data.total} tasks in ${formatDuration(data.durationSeconds)}`, "success");
+    loadTasks();
+  },
+  plan_failed(data) {
+    addActivity(`✗ Plan failed: ${data.reason}`, "error");
+    loadTasks();
+  },
+  tool_use(data) {
+    const text = data.target ? `${data.tool} ${data.target}` : data.tool;
+    let li = state.tools.get(data.toolId);
+    if (!li) {
+      li = addActivity("", data.parentToolId ? "nested" : "");
+      state.tools.set(data.toolId, li);
+    }
+    li.dataset.text = text;
+    li.textContent = `${li.dataset.done ? "✓" : "⣾"} ${text}`;
+  },
+  tool_result(data) {
+    const li = state.tools.get(data.toolId);
+    if (!li) return;
+    li.dataset.done = "1";
+    li.textContent = `✓ ${li.dataset.text}`;
+  },
+  usage(data) {
+    state.tokens += data.inputTokens + data.outputTokens;
+    state.cost += data.costUsd;
+    renderStats();
+  },
+  output(data) {
+    appendOutput(data.text);
+  },
+};
+
+function connect() {
+  const source = new EventSource("/api/events");
+  for (const [type, handle] of Object.entries(handlers)) {
+    source.addEventListener(type, (msg) => handle(JSON.parse(msg.data).data || {}));
+  }
+  source.onerror = () => setRunStatus("Disconnected, retrying…");
+}
+
+setInterval(() => {
+  if (state.running) renderStats();
+}, 1000);
+
+connect();
+loadPlans();
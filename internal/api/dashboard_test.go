@@ -0,0 +1,42 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDashboard_ServesEmbeddedFiles(t *testing.T) {
+	server := httptest.NewServer(New(setupAPIPlans(t), "").Handler())
+	defer server.Close()
+
+	for path, want := range map[string]string{
+		"/":          `<script src="app.js">`,
+		"/app.js":    `new EventSource("/api/events")`,
+		"/style.css": "#output",
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s: status %d", path, resp.StatusCode)
+		}
+		if !strings.Contains(string(body), want) {
+			t.Errorf("GET %s: expected body to contain %q", path, want)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/missing.js")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown file, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// Event types streamed to subscribers.
+const (
+	EventTaskStarted     = "task_started"
+	EventTaskCompleted   = "task_completed"
+	EventTaskFailed      = "task_failed"
+	EventTaskSkipped     = "task_skipped"
+	EventReviewStarted   = "review_started"
+	EventReviewCompleted = "review_completed"
+	EventPlanCompleted   = "plan_completed"
+	EventPlanFailed      = "plan_failed"
+	EventOutput          = "output"
+	EventToolUse         = "tool_use"
+	EventToolResult      = "tool_result"
+	EventUsage           = "usage"
+	EventRunStarted      = "run_started"
+	EventRunEnded        = "run_ended"
+	EventPaused          = "paused"
+	EventResumed         = "resumed"
+	EventCancelled       = "cancelled"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before events are dropped for it.
+const subscriberBuffer = 256
+
+// Event is one executor event or output chunk.
+type Event struct {
+	Type string                 `json:"type"`
+	Time time.Time              `json:"time"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Hub broadcasts events to every subscriber. It implements
+// executor.ExecutorEvents and executor.SkipEvents; output chunks from the
+// OutputCapture event channel are added with Output. Publishing never blocks
+// the executor: subscribers that fall behind lose events.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	now         func() time.Time
+}
+
+// NewHub creates a Hub with no subscribers.
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[chan Event]struct{}),
+		now:         time.Now,
+	}
+}
+
+// Subscribe returns a channel receiving every event published from now on,
+// and a function that unsubscribes and closes the channel.
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends an event to every subscriber. A nil Hub drops the event.
+func (h *Hub) Publish(eventType string, data map[string]interface{}) {
+	if h == nil {
+		return
+	}
+	ev := Event{Type: eventType, Time: h.now(), Data: data}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			// Drop for slow subscribers, don't block execution
+		}
+	}
+}
+
+// Output publishes a chunk of agent output. Internal stream markers are
+// dropped.
+func (h *Hub) Output(chunk string) {
+	if chunk == "" || chunk == executor.AssistantBoundaryChunk {
+		return
+	}
+	h.Publish(EventOutput, map[string]interface{}{"text": chunk})
+}
+
+// ToolUse publishes a tool call. The stream can report the same call twice,
+// the second time with its target, so clients should key calls by toolId.
+func (h *Hub) ToolUse(toolID, parentToolID, toolName, toolTarget string) {
+	h.Publish(EventToolUse, map[string]interface{}{
+		"toolId":       toolID,
+		"parentToolId": parentToolID,
+		"tool":         toolName,
+		"target":       toolTarget,
+	})
+}
+
+// ToolResult publishes the end of a tool call.
+func (h *Hub) ToolResult(toolID string) {
+	h.Publish(EventToolResult, map[string]interface{}{"toolId": toolID})
+}
+
+// Usage publishes the token usage and cost of a finished attempt.
+func (h *Hub) Usage(inputTokens, outputTokens int64, costUSD float64) {
+	h.Publish(EventUsage, map[string]interface{}{
+		"inputTokens":  inputTokens,
+		"outputTokens": outputTokens,
+		"costUsd":      costUSD,
+	})
+}
+
+// OnTaskStart implements executor.ExecutorEvents.
+func (h *Hub) OnTaskStart(taskNum, total int, task *plan.Task, attempt int) {
+	h.Publish(EventTaskStarted, map[string]interface{}{
+		"taskNum": taskNum,
+		"total":   total,
+		"taskId":  task.ID,
+		"title":   task.Title,
+		"attempt": attempt,
+	})
+}
+
+// OnTaskComplete implements executor.ExecutorEvents.
+func (h *Hub) OnTaskComplete(task *plan.Task) {
+	h.Publish(EventTaskCompleted, map[string]interface{}{"taskId": task.ID})
+}
+
+// OnTaskFailed implements executor.ExecutorEvents.
+func (h *Hub) OnTaskFailed(task *plan.Task, attempt int, err error) {
+	data := map[string]interface{}{"taskId": task.ID, "attempt": attempt}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	h.Publish(EventTaskFailed, data)
+}
+
+// OnTaskSkipped implements executor.SkipEvents.
+func (h *Hub) OnTaskSkipped(task *plan.Task) {
+	h.Publish(EventTaskSkipped, map[string]interface{}{"taskId": task.ID})
+}
+
+// OnReviewStart implements executor.ReviewEvents.
+func (h *Hub) OnReviewStart(task *plan.Task, attempt int) {
+	h.Publish(EventReviewStarted, map[string]interface{}{"taskId": task.ID, "attempt": attempt})
+}
+
+// OnReviewComplete implements executor.ReviewEvents.
+func (h *Hub) OnReviewComplete(task *plan.Task, attempt int, review *plan.Review, err error) {
+	data := map[string]interface{}{"taskId": task.ID, "attempt": attempt}
+	if review != nil {
+		data["verdict"] = review.Verdict
+		data["reasons"] = review.Reasons
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	h.Publish(EventReviewCompleted, data)
+}
+
+// OnOutput implements executor.ExecutorEvents.
+func (h *Hub) OnOutput(line string) {
+	h.Output(line)
+}
+
+// OnPlanComplete implements executor.ExecutorEvents.
+func (h *Hub) OnPlanComplete(succeeded, total int, duration time.Duration) {
+	h.Publish(EventPlanCompleted, map[string]interface{}{
+		"succeeded":       succeeded,
+		"total":           total,
+		"durationSeconds": duration.Seconds(),
+	})
+}
+
+// OnPlanFailed implements executor.ExecutorEvents.
+func (h *Hub) OnPlanFailed(task *plan.Task, reason string) {
+	h.Publish(EventPlanFailed, map[string]interface{}{"taskId": task.ID, "reason": reason})
+}
+
+var (
+	_ executor.ExecutorEvents = (*Hub)(nil)
+	_ executor.SkipEvents     = (*Hub)(nil)
+)
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+func TestHub_PublishToSubscribers(t *testing.T) {
+	hub := NewHub()
+	first, unsubscribeFirst := hub.Subscribe()
+	second, unsubscribeSecond := hub.Subscribe()
+	defer unsubscribeSecond()
+
+	hub.OnTaskFailed(&plan.Task{ID: "t01"}, 2, errors.New("tests failed"))
+
+	for i, ch := range []<-chan Event{first, second} {
+		ev := <-ch
+		if ev.Type != EventTaskFailed || ev.Data["error"] != "tests failed" || ev.Data["attempt"] != 2 {
+			t.Errorf("subscriber %d got %+v", i, ev)
+		}
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst() // safe to call twice
+	if _, ok := <-first; ok {
+		t.Error("expected channel to be closed after unsubscribe")
+	}
+	hub.OnTaskSkipped(&plan.Task{ID: "t01"})
+	if ev := <-second; ev.Type != EventTaskSkipped {
+		t.Errorf("expected remaining subscriber to keep receiving, got %+v", ev)
+	}
+	hub.OnReviewComplete(&plan.Task{ID: "t01"}, 1, &plan.Review{Verdict: plan.ReviewReject, Reasons: []string{"no tests"}}, nil)
+	if ev := <-second; ev.Type != EventReviewCompleted || ev.Data["verdict"] != plan.ReviewReject {
+		t.Errorf("expected the review verdict, got %+v", ev)
+	}
+}
+
+func TestHub_DropsForSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+	ch, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		hub.Output("chunk")
+	}
+	if len(ch) != subscriberBuffer {
+		t.Errorf("expected buffer to fill to %d, got %d", subscriberBuffer, len(ch))
+	}
+}
+
+func TestHub_StreamHooks(t *testing.T) {
+	hub := NewHub()
+	ch, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	hub.ToolUse("tool-1", "", "Read", "main.go")
+	hub.ToolResult("tool-1")
+	hub.Usage(100, 20, 0.05)
+
+	if ev := <-ch; ev.Type != EventToolUse || ev.Data["tool"] != "Read" || ev.Data["target"] != "main.go" {
+		t.Errorf("unexpected tool use event: %+v", ev)
+	}
+	if ev := <-ch; ev.Type != EventToolResult || ev.Data["toolId"] != "tool-1" {
+		t.Errorf("unexpected tool result event: %+v", ev)
+	}
+	if ev := <-ch; ev.Type != EventUsage || ev.Data["inputTokens"] != int64(100) || ev.Data["costUsd"] != 0.05 {
+		t.Errorf("unexpected usage event: %+v", ev)
+	}
+
+	var nilHub *Hub
+	nilHub.ToolUse("tool-2", "", "Bash", "") // must not panic
+}
@@ -0,0 +1,133 @@
+// Package config reads the project configuration in .rafa/config.json.
+// Every section is optional; a missing file is the same as an empty one.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pablasso/rafa/internal/ai"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// FileName is the configuration file inside the .rafa folder.
+const FileName = "config.json"
+
+// Config is the project configuration.
+type Config struct {
+	Notifications []Notification `json:"notifications,omitempty"`
+	Hooks         Hooks          `json:"hooks,omitempty"`
+	Context       ProjectContext `json:"context,omitempty"`
+	Review        Review         `json:"review,omitempty"`
+	// Agent sets the default agent options for every plan. Plans and tasks
+	// override them in plan.json.
+	Agent       plan.AgentConfig `json:"agent,omitempty"`
+	Permissions Permissions      `json:"permissions,omitempty"`
+	Container   Container        `json:"container,omitempty"`
+	// Env controls the environment of agent processes and which values
+	// are redacted from their output.
+	Env ai.EnvPolicy `json:"env,omitempty"`
+	// Paths limits what task agents may change in every plan. Plans and
+	// tasks override it in plan.json.
+	Paths plan.PathPolicy `json:"paths,omitempty"`
+}
+
+// Container runs task agents inside a container instead of on the host.
+// The repository is bind-mounted at its host path and is the container's
+// working directory. An empty Image runs agents on the host.
+type Container struct {
+	Image   string `json:"image,omitempty"`   // Must provide the claude CLI
+	Runtime string `json:"runtime,omitempty"` // docker (default) or podman
+	// Network is passed to --network, such as none or a network that only
+	// reaches the model API. Empty uses the runtime's default.
+	Network   string `json:"network,omitempty"`
+	CPUs      string `json:"cpus,omitempty"`   // Passed to --cpus
+	Memory    string `json:"memory,omitempty"` // Passed to --memory
+	PidsLimit int    `json:"pidsLimit,omitempty"`
+	// User is passed to --user. Empty runs docker as the host user, so files
+	// the agent writes aren't owned by root, and podman as its default.
+	User string `json:"user,omitempty"`
+	// Env are host environment variables passed into the container by
+	// name, such as ANTHROPIC_API_KEY.
+	Env       []string `json:"env,omitempty"`
+	ExtraArgs []string `json:"extraArgs,omitempty"` // Added to the run command before the image
+}
+
+// Permissions sets the agent's tool permission policies.
+type Permissions struct {
+	// Tasks applies to task attempts. Unset skips permission checks.
+	Tasks ai.Permissions `json:"tasks,omitempty"`
+	// Extraction applies to plan extraction. Unset means read-only tools.
+	Extraction *ai.Permissions `json:"extraction,omitempty"`
+}
+
+// ExtractionPermissions returns the policy for plan extraction.
+func (p Permissions) ExtractionPermissions() ai.Permissions {
+	if p.Extraction == nil {
+		return ai.ReadOnlyPermissions()
+	}
+	return *p.Extraction
+}
+
+// Review configures the reviewer agent that judges each successful
+// attempt's changes before they are committed.
+type Review struct {
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// ProjectContext selects the project context added to task prompts, so each
+// fresh agent doesn't have to rediscover the repository.
+type ProjectContext struct {
+	// Files are repository files included in full. Unset means CLAUDE.md
+	// and CONTRIBUTING.md; an empty list includes none. Missing files are
+	// skipped.
+	Files []string `json:"files,omitempty"`
+	// Disable turns off built-in sources: completed_tasks, changed_files
+	// and design_doc.
+	Disable []string `json:"disable,omitempty"`
+}
+
+// Hooks are shell commands run with sh -c from the repository root at fixed
+// points of a run. Empty hooks are not run.
+type Hooks struct {
+	BeforePlan       string `json:"before_plan,omitempty"`
+	BeforeTask       string `json:"before_task,omitempty"` // Before every attempt
+	AfterTaskSuccess string `json:"after_task_success,omitempty"`
+	AfterTaskFailure string `json:"after_task_failure,omitempty"` // After every failed attempt
+	AfterPlan        string `json:"after_plan,omitempty"`
+}
+
+// Notification sends word of run events somewhere. Type selects the channel
+// and which of the other fields are used:
+//
+//	command  Command, run with sh -c
+//	webhook  URL, POSTed a Slack-compatible JSON payload
+//	file     Path, appended one JSON line per event
+//	bell     rings the terminal bell
+type Notification struct {
+	Type string `json:"type"`
+	// Events lists the run events to notify about. Empty means
+	// plan_completed and plan_failed.
+	Events  []string `json:"events,omitempty"`
+	Command string   `json:"command,omitempty"`
+	URL     string   `json:"url,omitempty"`
+	Path    string   `json:"path,omitempty"`
+}
+
+// Load reads the configuration in rafaDir.
+func Load(rafaDir string) (*Config, error) {
+	data, err := os.ReadFile(filepath.Join(rafaDir, FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/ai"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	c, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(c.Notifications) != 0 {
+		t.Errorf("expected empty config, got %+v", c)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	data := `{
+		"notifications": [{"type": "webhook", "url": "http://example.com/hook", "events": ["plan_failed"]}],
+		"hooks": {"before_plan": "make db-up", "after_task_success": "gofmt -w ."},
+		"context": {"files": ["AGENTS.md"], "disable": ["changed_files"]},
+		"review": {"enabled": true},
+		"agent": {"model": "sonnet", "escalate": [{"afterFailures": 2, "model": "opus"}]},
+		"permissions": {"tasks": {"mode": "restricted", "tools": ["Read", "Edit"], "bash": ["go test:*"]}},
+		"container": {"image": "ghcr.io/acme/agent:1", "runtime": "podman", "network": "none", "pidsLimit": 128, "env": ["ANTHROPIC_API_KEY"]},
+		"env": {"deny": ["AWS_*"], "files": [".env.agent"], "redact": ["*_URL"]},
+		"paths": {"forbidden": [".github/**"], "onViolation": "revert"}
+	}`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(c.Notifications) != 1 {
+		t.Fatalf("expected 1 notification, got %+v", c.Notifications)
+	}
+	n := c.Notifications[0]
+	if n.Type != "webhook" || n.URL != "http://example.com/hook" || len(n.Events) != 1 || n.Events[0] != "plan_failed" {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+	if c.Hooks.BeforePlan != "make db-up" || c.Hooks.AfterTaskSuccess != "gofmt -w ." || c.Hooks.BeforeTask != "" {
+		t.Errorf("unexpected hooks: %+v", c.Hooks)
+	}
+	if len(c.Context.Files) != 1 || c.Context.Files[0] != "AGENTS.md" || len(c.Context.Disable) != 1 {
+		t.Errorf("unexpected context: %+v", c.Context)
+	}
+	if !c.Review.Enabled {
+		t.Error("expected review to be enabled")
+	}
+	if c.Agent.Model != "sonnet" || len(c.Agent.Escalate) != 1 || c.Agent.Escalate[0].Model != "opus" {
+		t.Errorf("unexpected agent: %+v", c.Agent)
+	}
+	if !c.Permissions.Tasks.Restricted() || len(c.Permissions.Tasks.Bash) != 1 {
+		t.Errorf("unexpected task permissions: %+v", c.Permissions.Tasks)
+	}
+	if c.Container.Image != "ghcr.io/acme/agent:1" || c.Container.Runtime != "podman" || c.Container.PidsLimit != 128 || len(c.Container.Env) != 1 {
+		t.Errorf("unexpected container: %+v", c.Container)
+	}
+	if len(c.Env.Deny) != 1 || len(c.Env.Files) != 1 || c.Env.Files[0] != ".env.agent" || len(c.Env.Redact) != 1 {
+		t.Errorf("unexpected env: %+v", c.Env)
+	}
+	if len(c.Paths.Forbidden) != 1 || !c.Paths.Revert() {
+		t.Errorf("unexpected paths: %+v", c.Paths)
+	}
+}
+
+func TestPermissions_ExtractionPermissions(t *testing.T) {
+	var p Permissions
+	if got := p.ExtractionPermissions(); !got.Restricted() || len(got.Tools) != 3 {
+		t.Errorf("expected read-only extraction by default, got %+v", got)
+	}
+
+	p.Extraction = &ai.Permissions{}
+	if got := p.ExtractionPermissions(); got.Restricted() {
+		t.Errorf("expected configured extraction permissions, got %+v", got)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("expected error for malformed config")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -71,8 +72,10 @@ func LoadDataset(planPath, outputPath string) (*Dataset, error) {
 	}, nil
 }
 
+// parseOutputLog parses task attempts from an output log. When path is a
+// plan's output.log, the plan's per-attempt output files are read as well.
 func parseOutputLog(path string) ([]TaskAttempt, error) {
-	f, err := os.Open(path)
+	f, err := openOutputLog(path)
 	if err != nil {
 		return nil, fmt.Errorf("open output log: %w", err)
 	}
@@ -180,6 +183,13 @@ func parseOutputLine(line string) ([]Event, *bool) {
 	return events, nil
 }
 
+func openOutputLog(path string) (io.ReadCloser, error) {
+	if filepath.Base(path) == plan.OutputLogFileName {
+		return plan.OpenOutputLog(filepath.Dir(path))
+	}
+	return os.Open(path)
+}
+
 // ParseOutputLine parses a single line from output.log into demo events.
 // It also returns a non-nil success value when the line contains a "result" event.
 //
@@ -0,0 +1,46 @@
+package demo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+func TestParseOutputLog_ReadsLegacyAndPerAttemptFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	legacy := "\n=== Task t01, Attempt 1 ===\nStarted: 2024-01-15T10:00:00Z\n\n\n=== Task t01: FAILED ===\n\n"
+	if err := os.WriteFile(filepath.Join(dir, plan.OutputLogFileName), []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write output.log: %v", err)
+	}
+
+	rel := plan.NewAttemptOutputFile("t01", 2, time.Date(2024, 1, 15, 10, 5, 0, 0, time.UTC))
+	attemptPath := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(attemptPath), 0755); err != nil {
+		t.Fatalf("failed to create output dir: %v", err)
+	}
+	attempt := "\n=== Task t01, Attempt 2 ===\nStarted: 2024-01-15T10:05:00Z\n\n\n=== Task t01: SUCCESS ===\n\n"
+	if err := os.WriteFile(attemptPath, []byte(attempt), 0644); err != nil {
+		t.Fatalf("failed to write attempt file: %v", err)
+	}
+	if err := plan.CompressOutputFile(attemptPath); err != nil {
+		t.Fatalf("CompressOutputFile() error: %v", err)
+	}
+
+	attempts, err := parseOutputLog(filepath.Join(dir, plan.OutputLogFileName))
+	if err != nil {
+		t.Fatalf("parseOutputLog() error: %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %+v", attempts)
+	}
+	if attempts[0].Attempt != 1 || attempts[0].Success {
+		t.Errorf("first attempt = %+v, want failed attempt 1", attempts[0])
+	}
+	if attempts[1].Attempt != 2 || !attempts[1].Success {
+		t.Errorf("second attempt = %+v, want successful attempt 2", attempts[1])
+	}
+}
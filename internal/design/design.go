@@ -0,0 +1,205 @@
+// Package design reads design docs: Markdown files split into sections at
+// their headings.
+package design
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Section is a heading of a design doc and the lines up to the next heading.
+type Section struct {
+	Heading string // Heading text without the #s; empty for text before the first heading
+	Level   int    // Number of #s; 0 for text before the first heading
+	Anchor  string // GitHub-style anchor of the heading
+	Start   int    // 1-based line of the heading
+	End     int    // 1-based last line of the section
+	Text    string // The section's lines, heading included
+}
+
+var headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// Parse splits a Markdown document into sections. Headings inside fenced
+// code blocks are ignored, and blank text before the first heading is
+// dropped.
+func Parse(text string) []Section {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var sections []Section
+	anchors := make(map[string]int)
+	current := Section{Start: 1}
+	flush := func(end int) {
+		current.End = end
+		current.Text = strings.Join(lines[current.Start-1:end], "\n")
+		if current.Level > 0 || strings.TrimSpace(current.Text) != "" {
+			sections = append(sections, current)
+		}
+	}
+
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		m := headingRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if i > 0 {
+			flush(i)
+		}
+		current = Section{Heading: m[2], Level: len(m[1]), Start: i + 1}
+		current.Anchor = uniqueAnchor(Slug(m[2]), anchors)
+	}
+	flush(len(lines))
+	return sections
+}
+
+// Slug returns the GitHub anchor of a heading: lowercased, with punctuation
+// dropped and spaces turned into hyphens.
+func Slug(heading string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteRune('-')
+		}
+	}
+	return sb.String()
+}
+
+// uniqueAnchor numbers repeated anchors the way GitHub does: the second
+// "setup" heading is "setup-1".
+func uniqueAnchor(anchor string, seen map[string]int) string {
+	n, ok := seen[anchor]
+	seen[anchor] = n + 1
+	if !ok {
+		return anchor
+	}
+	return anchor + "-" + strconv.Itoa(n)
+}
+
+// Relevant returns up to limit sections that mention a task, in document
+// order. Sections naming the task's ID or full title come first; failing
+// that, sections sharing the most significant words with the title.
+func Relevant(sections []Section, id, title string, limit int) []Section {
+	type scored struct {
+		idx   int
+		score int
+	}
+	words := significantWords(title)
+	var matches []scored
+	for i, s := range sections {
+		if s.Level == 0 {
+			continue
+		}
+		text := strings.ToLower(s.Text)
+		score := 0
+		if (id != "" && containsWord(text, strings.ToLower(id))) || (title != "" && strings.Contains(text, strings.ToLower(title))) {
+			score = 1000
+		} else {
+			sectionWords := significantWords(s.Text)
+			for w := range words {
+				if sectionWords[w] {
+					score++
+				}
+			}
+			// Sharing a word or two with a long title is noise.
+			if score < 2 || score*2 < len(words) {
+				continue
+			}
+		}
+		matches = append(matches, scored{idx: i, score: score})
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool { return matches[a].score > matches[b].score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	sort.Slice(matches, func(a, b int) bool { return matches[a].idx < matches[b].idx })
+	result := make([]Section, len(matches))
+	for i, m := range matches {
+		result[i] = sections[m.idx]
+	}
+	return result
+}
+
+// significantWords returns the lowercased words of text that are long
+// enough to say something about its topic.
+func significantWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) >= 4 && !stopWords[w] {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+var stopWords = map[string]bool{
+	"with": true, "from": true, "that": true, "this": true, "into": true,
+	"when": true, "each": true, "should": true, "must": true, "will": true,
+	"have": true, "their": true, "there": true, "these": true, "then": true,
+	"task": true, "tasks": true, "implement": true, "support": true,
+}
+
+// containsWord reports whether text contains word delimited by
+// non-identifier characters.
+func containsWord(text, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b == '-' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z'
+}
+
+// Find returns the section with anchor. A leading "#" is ignored, and
+// heading text matches by its slug.
+func Find(sections []Section, anchor string) (Section, bool) {
+	anchor = strings.TrimPrefix(strings.TrimSpace(anchor), "#")
+	if anchor == "" {
+		return Section{}, false
+	}
+	slug := Slug(anchor)
+	for _, s := range sections {
+		if s.Level > 0 && (s.Anchor == anchor || s.Anchor == slug) {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+// Lines returns lines start through end (1-based, inclusive) of text. An
+// end past the last line is clamped; a zero end means just the start line.
+func Lines(text string, start, end int) (string, bool) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if end == 0 {
+		end = start
+	}
+	end = min(end, len(lines))
+	if start < 1 || start > end {
+		return "", false
+	}
+	return strings.Join(lines[start-1:end], "\n"), true
+}
@@ -0,0 +1,110 @@
+package design
+
+import (
+	"testing"
+)
+
+const testDoc = `Intro paragraph.
+
+# Auth Design
+
+## Session Storage
+Sessions live in Redis with a 24h TTL.
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+## Login Endpoint
+POST /login checks the password hash and creates a session.
+
+## Setup
+Run make db.
+
+### Setup
+Nested setup.
+`
+
+func TestParse(t *testing.T) {
+	sections := Parse(testDoc)
+	if len(sections) != 6 {
+		t.Fatalf("expected 6 sections, got %d: %+v", len(sections), sections)
+	}
+
+	intro := sections[0]
+	if intro.Level != 0 || intro.Start != 1 || intro.End != 2 {
+		t.Errorf("unexpected intro: %+v", intro)
+	}
+
+	storage := sections[2]
+	if storage.Heading != "Session Storage" || storage.Level != 2 || storage.Anchor != "session-storage" {
+		t.Errorf("unexpected section: %+v", storage)
+	}
+	if storage.Start != 5 || storage.End != 11 {
+		t.Errorf("expected lines 5-11 (fenced heading ignored), got %d-%d", storage.Start, storage.End)
+	}
+
+	if sections[4].Anchor != "setup" || sections[5].Anchor != "setup-1" {
+		t.Errorf("expected numbered duplicate anchors, got %q and %q", sections[4].Anchor, sections[5].Anchor)
+	}
+	if sections[5].Text != "### Setup\nNested setup." {
+		t.Errorf("unexpected text %q", sections[5].Text)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Session Storage":         "session-storage",
+		"API: v2 (draft)":         "api-v2-draft",
+		"snake_case & kebab-case": "snake_case--kebab-case",
+	}
+	for heading, want := range tests {
+		if got := Slug(heading); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", heading, got, want)
+		}
+	}
+}
+
+func TestRelevant(t *testing.T) {
+	sections := Parse(testDoc)
+
+	got := Relevant(sections, "t02", "Login endpoint", 2)
+	if len(got) != 1 || got[0].Heading != "Login Endpoint" {
+		t.Errorf("expected the section naming the title, got %+v", got)
+	}
+
+	got = Relevant(sections, "t01", "Store sessions in Redis", 2)
+	if len(got) != 1 || got[0].Heading != "Session Storage" {
+		t.Errorf("expected the section sharing words with the title, got %+v", got)
+	}
+
+	if got := Relevant(sections, "t09", "Write the changelog", 2); len(got) != 0 {
+		t.Errorf("expected no sections, got %+v", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	sections := Parse(testDoc)
+	for _, anchor := range []string{"login-endpoint", "#login-endpoint", "Login Endpoint"} {
+		s, ok := Find(sections, anchor)
+		if !ok || s.Heading != "Login Endpoint" {
+			t.Errorf("Find(%q) = %+v, %v", anchor, s, ok)
+		}
+	}
+	if _, ok := Find(sections, "missing"); ok {
+		t.Error("expected no section for an unknown anchor")
+	}
+}
+
+func TestLines(t *testing.T) {
+	got, ok := Lines("a\nb\nc\n", 2, 10)
+	if !ok || got != "b\nc" {
+		t.Errorf("Lines = %q, %v", got, ok)
+	}
+	if got, ok := Lines("a\nb\n", 1, 0); !ok || got != "a" {
+		t.Errorf("expected a zero end to mean one line, got %q, %v", got, ok)
+	}
+	if _, ok := Lines("a\nb\n", 3, 4); ok {
+		t.Error("expected no lines past the end")
+	}
+}
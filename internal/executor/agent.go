@@ -0,0 +1,27 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// SettingsRunner is implemented by prompt runners that can apply agent
+// settings (model, turns, tools, extra arguments, environment) to an
+// attempt. Runners that don't implement it run every attempt the same way.
+type SettingsRunner interface {
+	RunPromptWithSettings(ctx context.Context, prompt string, agent plan.AgentSettings, output OutputWriter) error
+}
+
+// WithAgent sets the project's default agent settings, which the plan's and
+// the task's settings in plan.json override.
+func (e *Executor) WithAgent(c plan.AgentConfig) *Executor {
+	e.agent = c
+	return e
+}
+
+// agentSettings returns the effective agent settings for task's current
+// attempt.
+func (e *Executor) agentSettings(task *plan.Task) plan.AgentSettings {
+	return plan.ResolveAgent(task.Attempts, &e.agent, e.plan.Agent, task.Agent)
+}
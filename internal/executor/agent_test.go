@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// settingsRunner fails its first fails attempts and records the agent
+// settings of each.
+type settingsRunner struct {
+	fails  int
+	agents []plan.AgentSettings
+}
+
+func (r *settingsRunner) Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error {
+	return errors.New("expected RunPromptWithSettings to be used")
+}
+
+func (r *settingsRunner) RunPrompt(ctx context.Context, prompt string, output OutputWriter) error {
+	return errors.New("expected RunPromptWithSettings to be used")
+}
+
+func (r *settingsRunner) RunPromptWithSettings(ctx context.Context, prompt string, agent plan.AgentSettings, output OutputWriter) error {
+	r.agents = append(r.agents, agent)
+	if len(r.agents) <= r.fails {
+		return errors.New("tests failed")
+	}
+	return writeReport(output, doneReport)
+}
+
+func TestExecutor_AgentSettingsEscalate(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "t01", Title: "Boilerplate", Status: plan.TaskStatusPending, Agent: &plan.AgentConfig{
+			AgentSettings: plan.AgentSettings{Model: "haiku"},
+		}},
+		{ID: "t02", Title: "Hard part", Status: plan.TaskStatusPending},
+	})
+	p.Agent = &plan.AgentConfig{
+		AgentSettings: plan.AgentSettings{Model: "sonnet"},
+		Escalate:      []plan.Escalation{{AfterFailures: 2, AgentSettings: plan.AgentSettings{Model: "opus"}}},
+	}
+	planDir := createTestPlanDir(t, p)
+	runner := &settingsRunner{fails: 2}
+
+	err := New(planDir, p).
+		WithRunner(runner).
+		WithAgent(plan.AgentConfig{AgentSettings: plan.AgentSettings{MaxTurns: 30}}).
+		WithEvents(&mockEvents{}).
+		WithAllowDirty(true).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var models []string
+	for _, a := range runner.agents {
+		if a.MaxTurns != 30 {
+			t.Errorf("expected the project's max turns, got %+v", a)
+		}
+		models = append(models, a.Model)
+	}
+	// t01 fails twice and escalates on its third attempt; t02 uses the
+	// plan's model.
+	want := []string{"haiku", "haiku", "opus", "sonnet"}
+	if len(models) != len(want) {
+		t.Fatalf("expected models %v, got %v", want, models)
+	}
+	for i := range want {
+		if models[i] != want[i] {
+			t.Fatalf("expected models %v, got %v", want, models)
+		}
+	}
+
+	events, err := plan.ReadProgressEvents(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logged []string
+	for _, ev := range events {
+		if ev.Event != plan.EventTaskStarted {
+			continue
+		}
+		settings, _ := ev.Data["agent"].(map[string]interface{})
+		model, _ := settings["model"].(string)
+		logged = append(logged, model)
+	}
+	if len(logged) != len(want) || logged[2] != "opus" || logged[3] != "sonnet" {
+		t.Errorf("expected progress.log to record the models %v, got %v", want, logged)
+	}
+}
@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/pablasso/rafa/internal/ai"
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/util"
+)
+
+// containerRemoveTimeout bounds removing a cancelled attempt's container.
+const containerRemoveTimeout = 30 * time.Second
+
+// WithContainer runs the agent inside a container described by c instead
+// of on the host. The current directory, the repository root, is
+// bind-mounted into it.
+func (r *ClaudeRunner) WithContainer(c config.Container) *ClaudeRunner {
+	r.container = &c
+	return r
+}
+
+// containerRuntime returns the container runtime command, docker unless
+// configured otherwise.
+func containerRuntime(c config.Container) string {
+	if c.Runtime != "" {
+		return c.Runtime
+	}
+	return "docker"
+}
+
+// newContainerName returns a unique name for an attempt's container, so it
+// can be removed if the attempt is cancelled.
+func newContainerName() (string, error) {
+	id, err := util.GenerateShortID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate container name: %w", err)
+	}
+	return "rafa-" + id, nil
+}
+
+// containerRunArgs returns the runtime arguments that run the claude CLI
+// with args in a container named name, with dir mounted as its working
+// directory. env names the variables passed in from the runtime's
+// environment in addition to the configured ones, so their values never
+// appear on the command line.
+func containerRunArgs(c config.Container, name, dir string, env []string, args []string) []string {
+	run := []string{"run", "--rm", "--name", name, "-v", dir + ":" + dir, "-w", dir}
+	if user := containerUser(c); user != "" {
+		run = append(run, "--user", user)
+	}
+	if c.Network != "" {
+		run = append(run, "--network", c.Network)
+	}
+	if c.CPUs != "" {
+		run = append(run, "--cpus", c.CPUs)
+	}
+	if c.Memory != "" {
+		run = append(run, "--memory", c.Memory)
+	}
+	if c.PidsLimit > 0 {
+		run = append(run, "--pids-limit", strconv.Itoa(c.PidsLimit))
+	}
+	for _, names := range [][]string{c.Env, env} {
+		for _, n := range names {
+			run = append(run, "-e", n)
+		}
+	}
+	run = append(run, c.ExtraArgs...)
+	run = append(run, c.Image, "claude")
+	return append(run, args...)
+}
+
+// containerUser returns the --user value: the configured one, or the host
+// user for docker, whose containers otherwise write files as root.
+func containerUser(c config.Container) string {
+	if c.User != "" || containerRuntime(c) != "docker" || runtime.GOOS == "windows" {
+		return c.User
+	}
+	return fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+}
+
+// removeContainer force-removes a container left behind when its run was
+// cancelled: killing the runtime client doesn't stop the container.
+func removeContainer(c config.Container, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), containerRemoveTimeout)
+	defer cancel()
+	_ = ai.CommandContext(ctx, containerRuntime(c), "rm", "-f", name).Run()
+}
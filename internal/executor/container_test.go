@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// fakeRuntime is a stand-in for docker: it logs each invocation's
+// arguments to calls.log next to itself and prints a stream event with
+// $API_KEY, or sleeps for an image named slow.
+const fakeRuntime = `#!/bin/sh
+echo "$@" >> "$(dirname "$0")/calls.log"
+case "$*" in
+*" slow claude "*) exec sleep 5 ;;
+esac
+if [ "$1" = run ]; then
+	echo "{\"type\":\"result\",\"result\":\"key=$API_KEY\"}"
+fi
+`
+
+// writeFakeRuntime installs fakeRuntime in a temporary directory and
+// returns its path.
+func writeFakeRuntime(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "docker")
+	if err := os.WriteFile(path, []byte(fakeRuntime), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readRuntimeCalls(t *testing.T, runtime string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(runtime), "calls.log"))
+	if err != nil {
+		t.Fatalf("failed to read runtime calls: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestContainerRunArgs(t *testing.T) {
+	c := config.Container{
+		Image:     "ghcr.io/acme/agent:1",
+		Runtime:   "podman",
+		Network:   "none",
+		CPUs:      "2",
+		Memory:    "4g",
+		PidsLimit: 256,
+		Env:       []string{"ANTHROPIC_API_KEY"},
+		ExtraArgs: []string{"--read-only"},
+	}
+	got := containerRunArgs(c, "rafa-abc123", "/src/repo", []string{"GOFLAGS"}, []string{"-p", "do it"})
+	want := []string{
+		"run", "--rm", "--name", "rafa-abc123", "-v", "/src/repo:/src/repo", "-w", "/src/repo",
+		"--network", "none", "--cpus", "2", "--memory", "4g", "--pids-limit", "256",
+		"-e", "ANTHROPIC_API_KEY", "-e", "GOFLAGS",
+		"--read-only",
+		"ghcr.io/acme/agent:1", "claude", "-p", "do it",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("containerRunArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestContainerUser(t *testing.T) {
+	if got := containerUser(config.Container{User: "1000:1000"}); got != "1000:1000" {
+		t.Errorf("expected the configured user, got %q", got)
+	}
+	if got := containerUser(config.Container{Runtime: "podman"}); got != "" {
+		t.Errorf("expected podman to keep its default user, got %q", got)
+	}
+	if got := containerUser(config.Container{}); !strings.Contains(got, ":") {
+		t.Errorf("expected docker to run as the host user, got %q", got)
+	}
+}
+
+func TestClaudeRunner_Container(t *testing.T) {
+	runtime := writeFakeRuntime(t)
+	planDir := t.TempDir()
+	output, err := NewOutputCapture(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+	output.WriteTaskHeader("t01", 1)
+
+	runner := NewClaudeRunner().WithContainer(config.Container{Image: "agent", Runtime: runtime, User: "1:1"})
+	agent := plan.AgentSettings{Env: map[string]string{"API_KEY": "s3cret"}}
+	if err := runner.RunPromptWithSettings(context.Background(), "do it", agent, output); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	calls := readRuntimeCalls(t, runtime)
+	if len(calls) != 1 {
+		t.Fatalf("expected one runtime call, got %v", calls)
+	}
+	dir, _ := os.Getwd()
+	if !strings.Contains(calls[0], "-v "+dir+":"+dir+" -w "+dir) || !strings.Contains(calls[0], "-e API_KEY agent claude -p do it") {
+		t.Errorf("unexpected run arguments: %s", calls[0])
+	}
+	if strings.Contains(calls[0], "s3cret") {
+		t.Errorf("expected env values to stay off the command line: %s", calls[0])
+	}
+
+	// The container's stdout streams into the attempt's output.
+	data, err := os.ReadFile(filepath.Join(planDir, output.AttemptFile()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "key=s3cret") {
+		t.Errorf("expected container output in the attempt file, got %q", data)
+	}
+}
+
+func TestClaudeRunner_ContainerCancelRemovesContainer(t *testing.T) {
+	runtime := writeFakeRuntime(t)
+	runner := NewClaudeRunner().WithContainer(config.Container{Image: "slow", Runtime: runtime, User: "1:1"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := runner.RunPromptWithSettings(ctx, "do it", plan.AgentSettings{}, nil); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	calls := readRuntimeCalls(t, runtime)
+	if len(calls) != 2 || !strings.HasPrefix(calls[1], "rm -f rafa-") {
+		t.Fatalf("expected the container to be removed, got %v", calls)
+	}
+	name := strings.TrimPrefix(calls[1], "rm -f ")
+	if !strings.Contains(calls[0], "--name "+name) {
+		t.Errorf("expected the started container %q to be removed, got %v", name, calls)
+	}
+}
+
+func TestClaudeReviewer_Container(t *testing.T) {
+	runtime := writeFakeRuntime(t)
+	reviewer := NewClaudeReviewer().WithContainer(config.Container{Image: "agent", Runtime: runtime, User: "1:1"})
+
+	// The fake runtime prints no verdict, so the review itself fails.
+	if _, err := reviewer.Review(context.Background(), "review it", nil); err == nil {
+		t.Fatal("expected an error without a verdict")
+	}
+	calls := readRuntimeCalls(t, runtime)
+	if len(calls) != 1 || !strings.HasPrefix(calls[0], "run --rm --name rafa-") || !strings.Contains(calls[0], " agent claude -p review it --allowedTools ") {
+		t.Errorf("expected the reviewer to run in the container, got %v", calls)
+	}
+}
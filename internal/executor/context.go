@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/design"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+)
+
+// Built-in project context sources, as named in the context.disable setting.
+const (
+	ContextCompletedTasks = "completed_tasks"
+	ContextChangedFiles   = "changed_files"
+	ContextDesignDoc      = "design_doc"
+)
+
+// defaultContextFiles are included when the configuration doesn't list any.
+var defaultContextFiles = []string{"CLAUDE.md", "CONTRIBUTING.md"}
+
+const (
+	// maxContextFileBytes caps each included project file.
+	maxContextFileBytes = 16 * 1024
+	// maxDesignExcerpts and maxDesignExcerptBytes cap the design doc
+	// sections included for a task.
+	maxDesignExcerpts     = 2
+	maxDesignExcerptBytes = 8 * 1024
+)
+
+// truncatedNote ends text cut to fit its cap.
+const truncatedNote = "\n[truncated]"
+
+// WithProjectContext selects the project context added to task prompts.
+func (e *Executor) WithProjectContext(c config.ProjectContext) *Executor {
+	e.projectContext = c
+	return e
+}
+
+// gatherContext collects the project context for task's prompt. Sources
+// that can't be read are left out.
+func (e *Executor) gatherContext(task *plan.Task) prompt.ContextData {
+	var data prompt.ContextData
+	files := e.projectContext.Files
+	if files == nil {
+		files = defaultContextFiles
+	}
+	for _, name := range files {
+		content, err := os.ReadFile(filepath.Join(e.repoRoot, name))
+		if err != nil {
+			continue
+		}
+		data.Files = append(data.Files, prompt.ContextFile{
+			Path:    name,
+			Content: truncate(strings.TrimSpace(string(content)), maxContextFileBytes),
+		})
+	}
+
+	completed := e.contextEnabled(ContextCompletedTasks)
+	changed := e.contextEnabled(ContextChangedFiles)
+	if completed || changed {
+		events, _ := plan.ReadProgressEvents(e.planDir)
+		commits := plan.TaskCommits(events)
+		fileSet := make(map[string]bool)
+		for i := range e.plan.Tasks {
+			t := &e.plan.Tasks[i]
+			if t.Status != plan.TaskStatusCompleted {
+				continue
+			}
+			commit := commits[t.ID]
+			if completed {
+				subject, _, _ := strings.Cut(commit.Message, "\n")
+				data.CompletedTasks = append(data.CompletedTasks, prompt.CompletedTask{ID: t.ID, Title: t.Title, CommitMessage: subject})
+			}
+			for _, f := range commit.Files {
+				fileSet[f] = true
+			}
+		}
+		if changed {
+			for f := range fileSet {
+				data.ChangedFiles = append(data.ChangedFiles, f)
+			}
+			sort.Strings(data.ChangedFiles)
+		}
+	}
+
+	// A task linked to its design doc section gets that section instead.
+	if e.contextEnabled(ContextDesignDoc) && e.designSection(task) == "" {
+		data.DesignExcerpts = e.designExcerpts(task)
+	}
+	return data
+}
+
+// contextEnabled reports whether a built-in context source is on.
+func (e *Executor) contextEnabled(source string) bool {
+	return !slices.Contains(e.projectContext.Disable, source)
+}
+
+// designSection returns the section of the plan's source document task
+// is linked to, or "" when it has none or the link is stale.
+func (e *Executor) designSection(task *plan.Task) string {
+	if task.Section == nil {
+		return ""
+	}
+	doc, err := e.plan.ReadSourceFile(e.repoRoot)
+	if err != nil {
+		return ""
+	}
+	text, _ := task.Section.Excerpt(doc)
+	return strings.TrimSpace(text)
+}
+
+// designExcerpts returns the sections of the plan's source document that
+// mention task.
+func (e *Executor) designExcerpts(task *plan.Task) []string {
+	doc, err := e.plan.ReadSourceFile(e.repoRoot)
+	if err != nil {
+		return nil
+	}
+
+	var excerpts []string
+	budget := maxDesignExcerptBytes
+	for _, s := range design.Relevant(design.Parse(doc), task.ID, task.Title, maxDesignExcerpts) {
+		if budget <= 0 {
+			break
+		}
+		text := truncate(strings.TrimSpace(s.Text), budget)
+		budget -= len(text)
+		excerpts = append(excerpts, text)
+	}
+	return excerpts
+}
+
+// truncate cuts s to at most max bytes, on a line boundary when there is one.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := s[:max]
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + truncatedNote
+}
+
+// changedFiles returns the files in a git status listing that belong to the
+// task rather than to rafa's own bookkeeping in .rafa.
+func changedFiles(status []string) []string {
+	var files []string
+	for _, f := range status {
+		if strings.HasPrefix(f, ".rafa/") {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files
+}
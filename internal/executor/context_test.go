@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// fileWritingRunner writes impl-<n>.go on its nth attempt, reports it done
+// and records the prompts it is given.
+type fileWritingRunner struct {
+	repoRoot string
+	prompts  []string
+}
+
+func (r *fileWritingRunner) Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error {
+	return fmt.Errorf("expected RunPrompt to be used")
+}
+
+func (r *fileWritingRunner) RunPrompt(ctx context.Context, prompt string, output OutputWriter) error {
+	r.prompts = append(r.prompts, prompt)
+	name := fmt.Sprintf("impl-%d.go", len(r.prompts))
+	if err := os.WriteFile(filepath.Join(r.repoRoot, name), []byte("package main\n"), 0644); err != nil {
+		return err
+	}
+	return writeReport(output, doneReport)
+}
+
+func TestExecutor_PromptIncludesCompletedTasks(t *testing.T) {
+	repoRoot, planDir := setupTestGitRepo(t)
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+		{ID: "task-2", Title: "Task 2", Status: plan.TaskStatusPending},
+	})
+	if err := plan.SavePlan(planDir, p); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sh", "-c", "git add -A && git commit -m 'add plan'")
+	cmd.Dir = repoRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to commit plan: %v\n%s", err, out)
+	}
+
+	runner := &fileWritingRunner{repoRoot: repoRoot}
+	if err := New(planDir, p).WithRunner(runner).WithEvents(&mockEvents{}).Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(runner.prompts) != 2 {
+		t.Fatalf("expected 2 prompts, got %d", len(runner.prompts))
+	}
+	if strings.Contains(runner.prompts[0], "### Completed Tasks") {
+		t.Errorf("first prompt should have no completed tasks:\n%s", runner.prompts[0])
+	}
+	for _, want := range []string{
+		"### Completed Tasks\nThese tasks of the plan are done and committed. Build on their work instead of redoing it:\n- task-1: Task 1 ([rafa] Complete task task-1: Task 1)\n",
+		"### Files Changed So Far\n- impl-1.go\n",
+	} {
+		if !strings.Contains(runner.prompts[1], want) {
+			t.Errorf("second prompt missing %q:\n%s", want, runner.prompts[1])
+		}
+	}
+}
+
+func TestExecutor_PromptIncludesProjectFilesAndDesignDoc(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Session storage", Status: plan.TaskStatusPending},
+	})
+	p.SourceFile = "docs/design.md"
+	planDir, root := createHookTestPlanDir(t, p)
+
+	if err := os.WriteFile(filepath.Join(root, "CLAUDE.md"), []byte("Run make test before finishing.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	doc := "# Auth\n\n## Session Storage\nSessions live in Redis.\n\n## Login\nPOST /login.\n"
+	if err := os.WriteFile(filepath.Join(root, "docs", "design.md"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := New(planDir, p).RenderPrompt("task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Source: docs/design.md\n\n### CLAUDE.md\nRun make test before finishing.\n\n",
+		"### Design Doc Excerpts\nThe parts of the source document that mention this task:\n\n## Session Storage\nSessions live in Redis.\n\n## Your Task",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("prompt missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "POST /login") {
+		t.Errorf("prompt should only include the relevant section:\n%s", text)
+	}
+
+	// Everything can be turned off.
+	text, err = New(planDir, p).
+		WithProjectContext(config.ProjectContext{Files: []string{}, Disable: []string{ContextCompletedTasks, ContextChangedFiles, ContextDesignDoc}}).
+		RenderPrompt("task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "Source: docs/design.md\n## Your Task") {
+		t.Errorf("expected no project context:\n%s", text)
+	}
+}
+
+func TestExecutor_PromptIncludesLinkedDesignSection(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Session storage", Status: plan.TaskStatusPending, Section: &plan.SectionRef{Anchor: "login"}},
+	})
+	p.SourceFile = "docs/design.md"
+	planDir, root := createHookTestPlanDir(t, p)
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	doc := "# Auth\n\n## Session Storage\nSessions live in Redis.\n\n## Login\nPOST /login.\n"
+	if err := os.WriteFile(filepath.Join(root, "docs", "design.md"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := New(planDir, p).RenderPrompt("task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "The task was planned from this section of docs/design.md. Follow it:\n<design-section>\n## Login\nPOST /login.\n</design-section>\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("prompt missing %q:\n%s", want, text)
+	}
+	// The linked section replaces the excerpts matched by title.
+	if strings.Contains(text, "### Design Doc Excerpts") || strings.Contains(text, "Sessions live in Redis") {
+		t.Errorf("expected no heuristic excerpts:\n%s", text)
+	}
+
+	// A stale link falls back to the excerpts.
+	p.Tasks[0].Section = &plan.SectionRef{Anchor: "removed"}
+	text, err = New(planDir, p).RenderPrompt("task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(text, "<design-section>") || !strings.Contains(text, "Sessions live in Redis") {
+		t.Errorf("expected excerpts for a stale link:\n%s", text)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("got %q", got)
+	}
+	if got := truncate("line one\nline two\nline three", 20); got != "line one\nline two"+truncatedNote {
+		t.Errorf("expected a cut on a line boundary, got %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// Control lets callers outside the executor (such as the HTTP API) pause,
+// resume and skip work while a plan runs. Pausing takes effect before the next
+// attempt starts; the running attempt is left to finish. Skipping stops the
+// running attempt and moves on to the next task, leaving the skipped task
+// pending for a later run.
+//
+// Control methods are safe for concurrent use. A nil *Control never pauses or
+// skips.
+type Control struct {
+	mu            sync.Mutex
+	paused        bool
+	resumed       chan struct{}      // Closed when a pause ends
+	cancelAttempt context.CancelFunc // Set while an attempt is running
+	skip          bool               // A skip was requested for the running attempt
+}
+
+// NewControl creates a Control in the running (unpaused) state.
+func NewControl() *Control {
+	return &Control{}
+}
+
+// Pause holds the executor before its next attempt until Resume is called.
+func (c *Control) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		return
+	}
+	c.paused = true
+	c.resumed = make(chan struct{})
+}
+
+// Resume ends a pause.
+func (c *Control) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return
+	}
+	c.paused = false
+	close(c.resumed)
+}
+
+// Paused reports whether the executor is held before its next attempt.
+func (c *Control) Paused() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Skip stops the running attempt and moves on to the next task. It reports
+// false when no attempt is running.
+func (c *Control) Skip() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancelAttempt == nil {
+		return false
+	}
+	c.skip = true
+	c.cancelAttempt()
+	return true
+}
+
+// waitWhilePaused blocks while the executor is paused. It returns ctx's error
+// if the run is cancelled while waiting.
+func (c *Control) waitWhilePaused(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	if !c.paused {
+		c.mu.Unlock()
+		return nil
+	}
+	resumed := c.resumed
+	c.mu.Unlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startAttempt returns the context for a new attempt, which Skip cancels.
+// The returned function must be called when the attempt ends; it reports
+// whether the attempt was skipped.
+func (c *Control) startAttempt(ctx context.Context) (context.Context, func() bool) {
+	if c == nil {
+		return ctx, func() bool { return false }
+	}
+	attemptCtx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.cancelAttempt = cancel
+	c.skip = false
+	c.mu.Unlock()
+
+	return attemptCtx, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		cancel()
+		c.cancelAttempt = nil
+		skipped := c.skip
+		c.skip = false
+		// A skip racing with the end of the run doesn't count as one.
+		return skipped && ctx.Err() == nil
+	}
+}
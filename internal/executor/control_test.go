@@ -0,0 +1,174 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// funcRunner runs fn for every attempt.
+type funcRunner func(ctx context.Context, task *plan.Task) error
+
+func (f funcRunner) Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error {
+	return f(ctx, task)
+}
+
+func TestControl_SkipStopsTaskAndContinues(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+		{ID: "task-2", Title: "Task 2", Status: plan.TaskStatusPending},
+	})
+	planDir := createTestPlanDir(t, p)
+
+	control := NewControl()
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		if task.ID != "task-1" {
+			return nil
+		}
+		if !control.Skip() {
+			t.Error("expected Skip to find the running attempt")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	events := &mockEvents{}
+
+	err := New(planDir, p).
+		WithRunner(runner).
+		WithControl(control).
+		WithEvents(MultiEvents(events)).
+		WithAllowDirty(true).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if p.Tasks[0].Status != plan.TaskStatusPending || p.Tasks[0].Attempts != 1 {
+		t.Errorf("expected skipped task to be pending after 1 attempt, got %s/%d", p.Tasks[0].Status, p.Tasks[0].Attempts)
+	}
+	if p.Tasks[1].Status != plan.TaskStatusCompleted {
+		t.Errorf("expected next task to run, got %s", p.Tasks[1].Status)
+	}
+	if p.Status != plan.PlanStatusInProgress {
+		t.Errorf("expected plan to stay in progress with a skipped task, got %s", p.Status)
+	}
+	if len(events.taskFails) != 0 {
+		t.Errorf("expected a skip not to count as a failure, got %+v", events.taskFails)
+	}
+	if control.Skip() {
+		t.Error("expected Skip to report false with no attempt running")
+	}
+
+	data, err := os.ReadFile(filepath.Join(planDir, "progress.log"))
+	if err != nil {
+		t.Fatalf("failed to read progress log: %v", err)
+	}
+	if !strings.Contains(string(data), `"event":"task_skipped"`) {
+		t.Errorf("expected task_skipped event in progress.log:\n%s", data)
+	}
+}
+
+func TestControl_SkipRevertsChanges(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+		{ID: "task-2", Title: "Task 2", Status: plan.TaskStatusPending},
+	})
+	planDir, root := createGitPlanRepo(t, p)
+
+	control := NewControl()
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		if task.ID != "task-1" {
+			writeRepoFile(t, root, "src/two.go", "package src\n")
+			return nil
+		}
+		writeRepoFile(t, root, "src/one.go", "package src\n")
+		writeRepoFile(t, root, "README.md", "half done\n")
+		control.Skip()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := New(planDir, p).WithRunner(runner).WithControl(control).WithEvents(&mockEvents{}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	files := commitFiles(t, root)["[rafa] Complete task task-2: Task 2"]
+	if !strings.Contains(files, "src/two.go") || strings.Contains(files, "src/one.go") || strings.Contains(files, "README.md") {
+		t.Errorf("expected the next task's commit to hold only its own changes, got:\n%s", files)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "README.md")); string(data) != "readme\n" {
+		t.Errorf("expected README.md to be restored, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "src", "one.go")); !os.IsNotExist(err) {
+		t.Errorf("expected the skipped task's new file to be removed, got %v", err)
+	}
+}
+
+func TestControl_PauseHoldsNextAttempt(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+	})
+	planDir := createTestPlanDir(t, p)
+
+	control := NewControl()
+	control.Pause()
+	if !control.Paused() {
+		t.Fatal("expected Paused after Pause")
+	}
+
+	started := make(chan struct{}, 1)
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		started <- struct{}{}
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- New(planDir, p).WithRunner(runner).WithControl(control).WithAllowDirty(true).Run(context.Background())
+	}()
+
+	select {
+	case <-started:
+		t.Fatal("expected no attempt to start while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	control.Resume()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the attempt to start after Resume")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestControl_CancelWhilePaused(t *testing.T) {
+	control := NewControl()
+	control.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := control.waitWhilePaused(ctx); err == nil {
+		t.Error("expected cancellation to end the wait")
+	}
+}
+
+func TestControl_NilNeverPausesOrSkips(t *testing.T) {
+	var control *Control
+	if control.Paused() {
+		t.Error("expected nil control not to be paused")
+	}
+	if err := control.waitWhilePaused(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	ctx, end := control.startAttempt(context.Background())
+	if ctx == nil || end() {
+		t.Error("expected nil control to never skip")
+	}
+}
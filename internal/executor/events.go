@@ -27,3 +27,96 @@ type ExecutorEvents interface {
 	// OnPlanFailed is called when a task exhausts retries
 	OnPlanFailed(task *plan.Task, reason string)
 }
+
+// SkipEvents is optionally implemented by ExecutorEvents sinks that want to
+// know when a task is skipped through Control.
+type SkipEvents interface {
+	// OnTaskSkipped is called when the running attempt of task was stopped
+	// by a skip request and the task left pending.
+	OnTaskSkipped(task *plan.Task)
+}
+
+// ReviewEvents is optionally implemented by ExecutorEvents sinks that want
+// to know when attempts are reviewed (see WithReviewer).
+type ReviewEvents interface {
+	// OnReviewStart is called when the reviewer starts on a successful
+	// attempt's changes.
+	OnReviewStart(task *plan.Task, attempt int)
+
+	// OnReviewComplete is called with the reviewer's verdict, or with the
+	// error when the review couldn't be done.
+	OnReviewComplete(task *plan.Task, attempt int, review *plan.Review, err error)
+}
+
+// MultiEvents fans events out to every non-nil sink, in order.
+func MultiEvents(sinks ...ExecutorEvents) ExecutorEvents {
+	var m multiEvents
+	for _, s := range sinks {
+		if s != nil {
+			m = append(m, s)
+		}
+	}
+	return m
+}
+
+type multiEvents []ExecutorEvents
+
+func (m multiEvents) OnTaskStart(taskNum, total int, task *plan.Task, attempt int) {
+	for _, s := range m {
+		s.OnTaskStart(taskNum, total, task, attempt)
+	}
+}
+
+func (m multiEvents) OnTaskComplete(task *plan.Task) {
+	for _, s := range m {
+		s.OnTaskComplete(task)
+	}
+}
+
+func (m multiEvents) OnTaskFailed(task *plan.Task, attempt int, err error) {
+	for _, s := range m {
+		s.OnTaskFailed(task, attempt, err)
+	}
+}
+
+func (m multiEvents) OnOutput(line string) {
+	for _, s := range m {
+		s.OnOutput(line)
+	}
+}
+
+func (m multiEvents) OnPlanComplete(succeeded, total int, duration time.Duration) {
+	for _, s := range m {
+		s.OnPlanComplete(succeeded, total, duration)
+	}
+}
+
+func (m multiEvents) OnTaskSkipped(task *plan.Task) {
+	for _, s := range m {
+		if skip, ok := s.(SkipEvents); ok {
+			skip.OnTaskSkipped(task)
+		}
+	}
+}
+
+func (m multiEvents) OnReviewStart(task *plan.Task, attempt int) {
+	for _, s := range m {
+		if review, ok := s.(ReviewEvents); ok {
+			review.OnReviewStart(task, attempt)
+		}
+	}
+}
+
+func (m multiEvents) OnReviewComplete(task *plan.Task, attempt int, review *plan.Review, err error) {
+	for _, s := range m {
+		if r, ok := s.(ReviewEvents); ok {
+			r.OnReviewComplete(task, attempt, review, err)
+		}
+	}
+}
+
+func (m multiEvents) OnPlanFailed(task *plan.Task, reason string) {
+	for _, s := range m {
+		s.OnPlanFailed(task, reason)
+	}
+}
@@ -336,3 +336,21 @@ func TestExecutor_WithEvents_MultipleTasks(t *testing.T) {
 		t.Errorf("expected 1 OnPlanComplete event, got: %d", len(events.planCompletes))
 	}
 }
+
+func TestMultiEvents_FansOutAndSkipsNil(t *testing.T) {
+	first := &mockEvents{}
+	second := &mockEvents{}
+	events := MultiEvents(first, nil, second)
+
+	task := &plan.Task{ID: "t01"}
+	events.OnTaskStart(1, 2, task, 1)
+	events.OnTaskFailed(task, 1, errors.New("boom"))
+	events.OnTaskComplete(task)
+	events.OnPlanComplete(1, 2, time.Second)
+
+	for i, m := range []*mockEvents{first, second} {
+		if len(m.taskStarts) != 1 || len(m.taskFails) != 1 || len(m.taskCompletes) != 1 || len(m.planCompletes) != 1 {
+			t.Errorf("sink %d did not receive every event: %+v", i, m)
+		}
+	}
+}
@@ -2,35 +2,61 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/pablasso/rafa/internal/config"
 	"github.com/pablasso/rafa/internal/git"
 	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+	"github.com/pablasso/rafa/internal/telemetry"
 )
 
 // MaxAttempts is the maximum number of times to retry a failed task.
 const MaxAttempts = 5
 
+// ErrTaskSkipped is returned by executeTask when the running attempt was
+// stopped by Control.Skip.
+var ErrTaskSkipped = errors.New("task skipped")
+
 // Runner defines the interface for executing tasks.
 type Runner interface {
 	Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error
 }
 
+// BackendNamer is implemented by runners that can name their agent backend
+// (e.g. "claude"). The name is recorded in progress.log so metrics can be
+// grouped by backend.
+type BackendNamer interface {
+	Backend() string
+}
+
 // Executor orchestrates the execution of plan tasks.
 type Executor struct {
-	planDir    string
-	repoRoot   string
-	plan       *plan.Plan
-	logger     *plan.ProgressLogger
-	runner     Runner
-	lock       *plan.PlanLock
-	startTime  time.Time
-	allowDirty bool
-	saveHook   func()         // Optional hook called after each plan save (for testing)
-	events     ExecutorEvents // nil when no event sink is configured
-	output     *OutputCapture // Optional external output capture (for TUI)
+	planDir        string
+	repoRoot       string
+	plan           *plan.Plan
+	logger         *plan.ProgressLogger
+	runner         Runner
+	lock           *plan.PlanLock
+	startTime      time.Time
+	allowDirty     bool
+	saveHook       func()                      // Optional hook called after each plan save (for testing)
+	events         ExecutorEvents              // nil when no event sink is configured
+	output         *OutputCapture              // Optional external output capture (for TUI)
+	tracer         *telemetry.Tracer           // nil when tracing is disabled
+	control        *Control                    // nil when the run can't be paused or skipped
+	hooks          config.Hooks                // Lifecycle hooks; empty hooks are not run
+	prompts        *prompt.Set                 // nil renders the built-in templates
+	projectContext config.ProjectContext       // Context sources for task prompts
+	failures       map[string][]prompt.Failure // Failed attempts by task ID, for retry prompts
+	reviewer       Reviewer                    // nil commits attempts without review
+	agent          plan.AgentConfig            // Project agent settings, under the plan's and task's
+	paths          plan.PathPolicy             // Project path policy, under the plan's and task's
+	wantsReport    bool                        // Whether the current attempt's prompt asked for a completion report
 }
 
 // New creates a new Executor for the given plan directory and plan.
@@ -81,6 +107,21 @@ func (e *Executor) WithOutput(output *OutputCapture) *Executor {
 	return e
 }
 
+// WithTracer records the run as OpenTelemetry spans. When the executor creates
+// its own output capture, tool use and usage are traced too; callers providing
+// an output capture should forward its stream hooks to the tracer.
+func (e *Executor) WithTracer(t *telemetry.Tracer) *Executor {
+	e.tracer = t
+	return e
+}
+
+// WithControl lets c pause the run before each attempt and skip the running
+// task.
+func (e *Executor) WithControl(c *Control) *Executor {
+	e.control = c
+	return e
+}
+
 // notifySave calls the save hook if one is configured.
 func (e *Executor) notifySave() {
 	if e.saveHook != nil {
@@ -103,7 +144,7 @@ func (e *Executor) Run(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("failed to check git status: %w", err)
 		}
-		// Filter out our lock file from the dirty files list
+		// Filter out our lock file and schema migration writes from the dirty files list
 		dirtyFiles := e.filterOutLockFile(status.Files)
 		if len(dirtyFiles) > 0 {
 			return e.workspaceDirtyError(dirtyFiles)
@@ -151,18 +192,34 @@ func (e *Executor) Run(ctx context.Context) error {
 
 	// Log plan started and record start time
 	e.startTime = time.Now()
-	if err := e.logger.PlanStarted(e.plan.ID); err != nil {
+	backend := ""
+	if namer, ok := e.runner.(BackendNamer); ok {
+		backend = namer.Backend()
+	}
+	if err := e.logger.PlanStarted(e.plan.ID, backend); err != nil {
 		return fmt.Errorf("failed to log plan started: %w", err)
 	}
+	e.tracer.StartRun(ctx, e.plan, backend)
+	// Ends the run span on early returns; no-op once the run was ended below.
+	defer e.tracer.EndRun(plan.RunOutcomeInterrupted, nil)
 
 	// Build plan context once
 	planContext := e.buildPlanContext()
+	e.loadFailures()
 
 	// Use provided output capture or create our own.
 	output := e.output
 	if output == nil {
 		var err error
-		output, err = NewOutputCapture(e.planDir)
+		if e.tracer != nil {
+			output, err = NewOutputCaptureWithEventsAndHooks(e.planDir, nil, StreamHooks{
+				OnToolUse:    e.tracer.ToolUse,
+				OnToolResult: e.tracer.ToolResult,
+				OnUsage:      e.tracer.Usage,
+			})
+		} else {
+			output, err = NewOutputCapture(e.planDir)
+		}
 		if err != nil {
 			// Output capture is non-critical, log warning and continue
 			if e.events == nil {
@@ -176,6 +233,10 @@ func (e *Executor) Run(ctx context.Context) error {
 	}
 	// Note: If output was provided externally, caller is responsible for closing it
 
+	if err := e.runBeforeHook(ctx, HookBeforePlan, hookRun{}, output); err != nil {
+		return e.blockRun(&e.plan.Tasks[firstIdx], err)
+	}
+
 	// Execute tasks from first pending
 	for i := firstIdx; i < len(e.plan.Tasks); i++ {
 		task := &e.plan.Tasks[i]
@@ -186,6 +247,13 @@ func (e *Executor) Run(ctx context.Context) error {
 		}
 
 		err := e.executeTask(ctx, task, i, planContext, output)
+		if errors.Is(err, ErrTaskSkipped) {
+			continue
+		}
+		if err != nil && isHookError(err) && ctx.Err() == nil {
+			e.runAfterHook(ctx, HookAfterPlan, hookRun{outcome: plan.RunOutcomeFailed}, output)
+			return e.blockRun(task, err)
+		}
 		if err != nil {
 			if ctx.Err() != nil {
 				// Context cancelled - reset task to pending
@@ -198,6 +266,8 @@ func (e *Executor) Run(ctx context.Context) error {
 					e.notifySave()
 				}
 				e.logger.PlanCancelled(task.ID)
+				e.tracer.EndRun(plan.RunOutcomeCancelled, nil)
+				e.runAfterHook(ctx, HookAfterPlan, hookRun{outcome: plan.RunOutcomeCancelled}, output)
 				return nil
 			}
 
@@ -211,6 +281,8 @@ func (e *Executor) Run(ctx context.Context) error {
 				e.notifySave()
 			}
 			e.logger.PlanFailed(task.ID, task.Attempts)
+			e.tracer.EndRun(plan.RunOutcomeFailed, err)
+			e.runAfterHook(ctx, HookAfterPlan, hookRun{outcome: plan.RunOutcomeFailed}, output)
 			// Emit OnPlanFailed event for TUI integration
 			if e.events != nil {
 				e.events.OnPlanFailed(task, fmt.Sprintf("failed after %d attempts", task.Attempts))
@@ -219,8 +291,12 @@ func (e *Executor) Run(ctx context.Context) error {
 		}
 	}
 
-	// All tasks completed
-	e.plan.Status = plan.PlanStatusCompleted
+	// Every task was attempted. Skipped tasks stay pending, leaving the plan
+	// in progress so the next run picks them up.
+	allCompleted := e.plan.AllTasksCompleted()
+	if allCompleted {
+		e.plan.Status = plan.PlanStatusCompleted
+	}
 	if err := plan.SavePlan(e.planDir, e.plan); err != nil {
 		return fmt.Errorf("failed to save plan: %w", err)
 	}
@@ -228,11 +304,14 @@ func (e *Executor) Run(ctx context.Context) error {
 
 	duration := time.Since(e.startTime)
 	e.logger.PlanCompleted(len(e.plan.Tasks), e.countCompleted(), duration)
+	e.tracer.EndRun(plan.RunOutcomeCompleted, nil)
+	// Before the final commit, so files the hook changes are committed too.
+	e.runAfterHook(ctx, HookAfterPlan, hookRun{outcome: plan.RunOutcomeCompleted}, output)
 
 	// Commit any remaining metadata (plan completion status)
 	// CommitAll returns nil when there's nothing to commit (e.g., agent already committed)
 	// We only warn on error since the agent might have already committed everything.
-	if !e.allowDirty {
+	if !e.allowDirty && allCompleted {
 		msg := fmt.Sprintf("[rafa] Complete plan: %s (%d tasks)", e.plan.Name, len(e.plan.Tasks))
 		if err := git.CommitAll(e.repoRoot, msg); err != nil {
 			if e.events == nil {
@@ -257,6 +336,15 @@ func (e *Executor) executeTask(ctx context.Context, task *plan.Task, idx int, pl
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if err := e.control.waitWhilePaused(ctx); err != nil {
+			return err
+		}
+		if err := e.runBeforeHook(ctx, HookBeforeTask, hookRun{task: task, idx: idx, attempt: task.Attempts + 1}, output); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
 
 		// Increment attempts and set in_progress
 		task.Attempts++
@@ -274,18 +362,43 @@ func (e *Executor) executeTask(ctx context.Context, task *plan.Task, idx int, pl
 				idx+1, len(e.plan.Tasks), task.Title, task.Attempts, MaxAttempts)
 		}
 
-		// Log task started
-		if err := e.logger.TaskStarted(task.ID, task.Attempts); err != nil {
-			return fmt.Errorf("failed to log task started: %w", err)
-		}
-
-		// Write task header to output log
+		// Start the attempt's output file, then log task started with the
+		// file name so readers can find the attempt's output.
+		agent := e.agentSettings(task)
+		var outputFile string
 		if output != nil {
 			output.WriteTaskHeader(task.ID, task.Attempts)
+			outputFile = output.AttemptFile()
 		}
+		logErr := e.logger.TaskStarted(task.ID, task.Attempts, plan.TaskStartedInfo{OutputFile: outputFile, Agent: agent})
+		if logErr != nil {
+			return fmt.Errorf("failed to log task started: %w", logErr)
+		}
+		e.tracer.StartAttempt(task, task.Attempts)
 
 		// Run the task
-		err := e.runner.Run(ctx, task, planContext, task.Attempts, MaxAttempts, output)
+		attemptCtx, endAttempt := e.control.startAttempt(ctx)
+		err := e.runAttempt(attemptCtx, task, idx, planContext, agent, output)
+		if skipped := endAttempt(); skipped && err != nil {
+			return e.skipTask(task)
+		}
+		var report *plan.CompletionReport
+		if err == nil {
+			report, err = e.checkCompletion(task, output)
+		}
+		if err == nil {
+			err = e.checkPaths(task, output)
+		}
+		if err == nil && e.reviewer != nil {
+			err = e.reviewAttempt(ctx, task, report, output)
+		}
+		if err == nil {
+			// Runs before the commit so formatters and code generators
+			// land in the task's commit, which is why their changes are
+			// checked against the path policy as well.
+			e.runAfterHook(ctx, HookAfterTaskSuccess, hookRun{task: task, idx: idx, attempt: task.Attempts}, output)
+			err = e.checkPaths(task, output)
+		}
 		if err == nil {
 			// Task succeeded - update metadata and commit everything
 			task.Status = plan.TaskStatusCompleted
@@ -293,30 +406,37 @@ func (e *Executor) executeTask(ctx context.Context, task *plan.Task, idx int, pl
 				return fmt.Errorf("failed to save plan: %w", saveErr)
 			}
 			e.notifySave()
-			if logErr := e.logger.TaskCompleted(task.ID); logErr != nil {
+			// Finish the attempt's output first, so its compressed file
+			// lands in the task's commit.
+			if output != nil {
+				output.WriteTaskFooter(task.ID, true)
+			}
+			// Record the commit before making it, so later prompts can
+			// summarize the task and progress.log lands in the commit.
+			commitMsg := e.getCommitMessage(task, report)
+			var files []string
+			if !e.allowDirty {
+				if status, statusErr := git.GetStatus(e.repoRoot); statusErr == nil {
+					files = changedFiles(status.Files)
+				}
+			}
+			if logErr := e.logger.TaskCompleted(task.ID, plan.TaskCommit{Message: commitMsg, Files: files}); logErr != nil {
 				return fmt.Errorf("failed to log task completed: %w", logErr)
 			}
 
 			// Commit all changes (implementation + metadata) unless allowDirty
 			if !e.allowDirty {
-				commitMsg := e.getCommitMessage(task, output)
-				if commitErr := git.CommitAll(e.repoRoot, commitMsg); commitErr != nil {
-					return fmt.Errorf("failed to commit: %w", commitErr)
-				}
-
-				// Verify workspace is clean after commit (catches git hooks, etc.)
-				status, checkErr := git.GetStatus(e.repoRoot)
-				if checkErr != nil {
-					return fmt.Errorf("failed to check git status after commit: %w", checkErr)
-				}
-				if !status.Clean {
-					return fmt.Errorf("workspace not clean after commit (possibly git hooks modified files): %v", status.Files)
+				if commitErr := e.commitTask(commitMsg); commitErr != nil {
+					// Supersede the task_completed event logged above.
+					if logErr := e.logger.TaskFailed(task.ID, task.Attempts); logErr != nil && e.events == nil {
+						fmt.Printf("Warning: failed to log task failed: %v\n", logErr)
+					}
+					e.tracer.EndAttempt(commitErr)
+					return commitErr
 				}
 			}
 
-			if output != nil {
-				output.WriteTaskFooter(task.ID, true)
-			}
+			e.tracer.EndAttempt(nil)
 			// Emit OnTaskComplete event for TUI integration
 			if e.events != nil {
 				e.events.OnTaskComplete(task)
@@ -325,6 +445,8 @@ func (e *Executor) executeTask(ctx context.Context, task *plan.Task, idx int, pl
 		}
 
 		// Task failed
+		e.recordFailure(task, err)
+		e.tracer.EndAttempt(err)
 		if logErr := e.logger.TaskFailed(task.ID, task.Attempts); logErr != nil {
 			if e.events == nil {
 				fmt.Printf("Warning: failed to log task failed: %v\n", logErr)
@@ -336,6 +458,7 @@ func (e *Executor) executeTask(ctx context.Context, task *plan.Task, idx int, pl
 		} else {
 			fmt.Printf("Task failed: %v\n", err)
 		}
+		e.runAfterHook(ctx, HookAfterTaskFailure, hookRun{task: task, idx: idx, attempt: task.Attempts, err: err}, output)
 		if output != nil {
 			output.WriteTaskFooter(task.ID, false)
 		}
@@ -366,6 +489,54 @@ func (e *Executor) executeTask(ctx context.Context, task *plan.Task, idx int, pl
 	return fmt.Errorf("max attempts reached")
 }
 
+// blockRun ends the run because a before_* hook failed. The task is left
+// pending and the plan keeps its status, since no attempt was made.
+func (e *Executor) blockRun(task *plan.Task, err error) error {
+	if task.Status != plan.TaskStatusPending {
+		task.Status = plan.TaskStatusPending
+		if saveErr := plan.SavePlan(e.planDir, e.plan); saveErr != nil {
+			if e.events == nil {
+				fmt.Printf("Warning: failed to save plan: %v\n", saveErr)
+			}
+		} else {
+			e.notifySave()
+		}
+	}
+	e.logger.PlanFailed(task.ID, task.Attempts)
+	e.tracer.EndRun(plan.RunOutcomeFailed, err)
+	if e.events != nil {
+		e.events.OnPlanFailed(task, err.Error())
+	}
+	return err
+}
+
+// skipTask records that the running attempt of task was stopped by a skip
+// request. The task goes back to pending so a later run retries it, and its
+// changes are reverted so the next task's commit, path check and review
+// don't take them for its own.
+func (e *Executor) skipTask(task *plan.Task) error {
+	if err := e.revertChanges(); err != nil {
+		return fmt.Errorf("failed to revert skipped task's changes: %w", err)
+	}
+	task.Status = plan.TaskStatusPending
+	if err := plan.SavePlan(e.planDir, e.plan); err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	e.notifySave()
+	if err := e.logger.TaskSkipped(task.ID, task.Attempts); err != nil {
+		return fmt.Errorf("failed to log task skipped: %w", err)
+	}
+	e.tracer.EndAttempt(ErrTaskSkipped)
+	if e.events != nil {
+		if skip, ok := e.events.(SkipEvents); ok {
+			skip.OnTaskSkipped(task)
+		}
+	} else {
+		fmt.Printf("Task skipped: %s\n", task.Title)
+	}
+	return ErrTaskSkipped
+}
+
 // buildPlanContext returns a context string describing the plan.
 func (e *Executor) buildPlanContext() string {
 	return fmt.Sprintf("Plan: %s\nDescription: %s\nSource: %s",
@@ -408,27 +579,102 @@ func (e *Executor) workspaceDirtyError(files []string) error {
 	return fmt.Errorf("%s", msg)
 }
 
+// commitTask commits all changes with message and verifies the workspace is
+// clean afterwards, which catches git hooks that modify files.
+func (e *Executor) commitTask(message string) error {
+	if err := git.CommitAll(e.repoRoot, message); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	status, err := git.GetStatus(e.repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to check git status after commit: %w", err)
+	}
+	if !status.Clean {
+		return fmt.Errorf("workspace not clean after commit (possibly git hooks modified files): %v", status.Files)
+	}
+	return nil
+}
+
+// revertChanges undoes the uncommitted changes outside .rafa. Nothing is
+// reverted with AllowDirty, since the changes may not be the agent's.
+func (e *Executor) revertChanges() error {
+	if e.allowDirty {
+		return nil
+	}
+	paths, err := git.ChangedPaths(e.repoRoot)
+	if err != nil {
+		return err
+	}
+	files := changedFiles(paths)
+	if len(files) == 0 {
+		return nil
+	}
+	return git.RevertPaths(e.repoRoot, files)
+}
+
 // filterOutLockFile removes the run.lock file from a list of dirty files.
 // This is needed because the lock is created before we check workspace cleanliness.
+// A schema migration is also ignored: loading a plan written with an older
+// schema upgrades plan.json in place and backs it up, and those writes are
+// committed together with the first task. plan.json is only ignored when it
+// holds nothing but the migration, so hand edits still count as dirty.
 func (e *Executor) filterOutLockFile(files []string) []string {
-	lockPath := ".rafa/plans/" + filepath.Base(e.planDir) + "/run.lock"
+	planPrefix := ".rafa/plans/" + filepath.Base(e.planDir) + "/"
+	migrated := false
+	for _, f := range files {
+		var version int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(f, planPrefix), "plan.json.v%d.bak", &version); err == nil &&
+			strings.HasPrefix(f, planPrefix) && plan.IsMigrationOnly(e.planDir, version) {
+			migrated = true
+		}
+	}
+
 	var filtered []string
 	for _, f := range files {
-		if f != lockPath {
-			filtered = append(filtered, f)
+		if strings.HasPrefix(f, planPrefix) {
+			name := strings.TrimPrefix(f, planPrefix)
+			if name == "run.lock" {
+				continue
+			}
+			if migrated && (name == "plan.json" || (strings.HasPrefix(name, "plan.json.v") && strings.HasSuffix(name, ".bak"))) {
+				continue
+			}
 		}
+		filtered = append(filtered, f)
 	}
 	return filtered
 }
 
-// getCommitMessage extracts the agent's suggested commit message from OutputCapture,
-// or falls back to a default message format '[rafa] Complete task <id>: <title>'.
-// The [rafa] prefix enables easy filtering in git log.
-func (e *Executor) getCommitMessage(task *plan.Task, output *OutputCapture) string {
-	if output != nil {
-		if msg := output.ExtractCommitMessage(); msg != "" {
-			return msg
+// checkCompletion reads the agent's completion report for the attempt and
+// stores it on task. It fails the attempt when the report can't be parsed
+// or doesn't show every acceptance criterion met, or is missing although
+// the prompt asked for one. An attempt without a report passes otherwise,
+// for runners and templates that don't ask for one.
+func (e *Executor) checkCompletion(task *plan.Task, output *OutputCapture) (*plan.CompletionReport, error) {
+	task.Report = nil
+	if output == nil {
+		return nil, nil
+	}
+	report, err := output.CompletionReport()
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		if e.wantsReport {
+			return nil, fmt.Errorf("no completion report: end the final message with the line %s followed by the JSON report", plan.CompletionMarker)
 		}
+		return nil, nil
+	}
+	task.Report = report
+	return report, report.Verify(task.AcceptanceCriteria)
+}
+
+// getCommitMessage returns the commit message from the agent's completion
+// report, or falls back to a default message format '[rafa] Complete task <id>: <title>'.
+// The [rafa] prefix enables easy filtering in git log.
+func (e *Executor) getCommitMessage(task *plan.Task, report *plan.CompletionReport) string {
+	if report != nil && report.CommitMessage != "" {
+		return report.CommitMessage
 	}
 	return fmt.Sprintf("[rafa] Complete task %s: %s", task.ID, task.Title)
 }
@@ -6,14 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/pablasso/rafa/internal/git"
 	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+	"github.com/pablasso/rafa/internal/telemetry"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // mockRunnerCall records the arguments of a mockRunner.Run call.
@@ -656,74 +663,33 @@ func (f runnerFunc) Run(ctx context.Context, task *plan.Task, planContext string
 	return f(ctx, task, planContext, attempt, maxAttempts, output)
 }
 
-func TestExecutor_GetCommitMessage_UsesAgentSuggestion(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Create an output capture with a suggested commit message
-	oc, err := NewOutputCapture(tmpDir)
-	if err != nil {
-		t.Fatalf("NewOutputCapture() error: %v", err)
-	}
-	oc.Stdout().Write([]byte("Some output\n"))
-	oc.Stdout().Write([]byte("SUGGESTED_COMMIT_MESSAGE: Add user authentication feature\n"))
-	oc.Stdout().Write([]byte("More output\n"))
-	oc.logFile.Sync()
-
+func TestExecutor_GetCommitMessage_UsesReport(t *testing.T) {
 	task := &plan.Task{
 		ID:    "t01",
 		Title: "Implement login",
 	}
 
 	executor := &Executor{}
-	msg := executor.getCommitMessage(task, oc)
+	msg := executor.getCommitMessage(task, &plan.CompletionReport{CommitMessage: "Add user authentication feature"})
 
 	expected := "Add user authentication feature"
 	if msg != expected {
 		t.Errorf("getCommitMessage() = %q, want %q", msg, expected)
 	}
-
-	oc.Close()
 }
 
 func TestExecutor_GetCommitMessage_FallbackToDefault(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Create an output capture without a suggested commit message
-	oc, err := NewOutputCapture(tmpDir)
-	if err != nil {
-		t.Fatalf("NewOutputCapture() error: %v", err)
-	}
-	oc.Stdout().Write([]byte("Some output without commit message\n"))
-	oc.logFile.Sync()
-
 	task := &plan.Task{
 		ID:    "t01",
 		Title: "Implement login",
 	}
 
 	executor := &Executor{}
-	msg := executor.getCommitMessage(task, oc)
-
 	expected := "[rafa] Complete task t01: Implement login"
-	if msg != expected {
-		t.Errorf("getCommitMessage() = %q, want %q", msg, expected)
-	}
-
-	oc.Close()
-}
-
-func TestExecutor_GetCommitMessage_HandlesNilOutput(t *testing.T) {
-	task := &plan.Task{
-		ID:    "t02",
-		Title: "Fix bug in parser",
-	}
-
-	executor := &Executor{}
-	msg := executor.getCommitMessage(task, nil)
-
-	expected := "[rafa] Complete task t02: Fix bug in parser"
-	if msg != expected {
-		t.Errorf("getCommitMessage() = %q, want %q", msg, expected)
+	for _, report := range []*plan.CompletionReport{nil, {}} {
+		if msg := executor.getCommitMessage(task, report); msg != expected {
+			t.Errorf("getCommitMessage(%+v) = %q, want %q", report, msg, expected)
+		}
 	}
 }
 
@@ -1193,7 +1159,7 @@ func TestRun_ExecutorCommitsAfterTask(t *testing.T) {
 }
 
 // TestRun_UsesAgentSuggestedMessage verifies that the executor uses the
-// agent's SUGGESTED_COMMIT_MESSAGE when available.
+// commit message from the agent's completion report when available.
 func TestRun_UsesAgentSuggestedMessage(t *testing.T) {
 	repoRoot, planDir := setupTestGitRepo(t)
 
@@ -1229,9 +1195,9 @@ func TestRun_UsesAgentSuggestedMessage(t *testing.T) {
 			return err
 		}
 
-		// Write suggested commit message to output
+		// Write a completion report to output
 		if output != nil {
-			output.Stdout().Write([]byte("SUGGESTED_COMMIT_MESSAGE: Add awesome new feature\n"))
+			output.Stdout().Write([]byte(plan.CompletionMarker + "\n{\"commitMessage\": \"Add awesome new feature\", \"criteria\": []}\n"))
 		}
 		return nil
 	})
@@ -1313,6 +1279,124 @@ func TestRun_DefaultMessageWhenNoSuggestion(t *testing.T) {
 	}
 }
 
+// reportRunner emits the next of its completion reports on each attempt,
+// as streamed text deltas, and records the prompts it is given.
+type reportRunner struct {
+	reports []string
+	prompts []string
+}
+
+func (r *reportRunner) Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error {
+	return fmt.Errorf("expected RunPrompt to be used")
+}
+
+func (r *reportRunner) RunPrompt(ctx context.Context, prompt string, output OutputWriter) error {
+	report := r.reports[len(r.prompts)]
+	r.prompts = append(r.prompts, prompt)
+	return writeReport(output, report)
+}
+
+// doneReport is a completion report that meets a task's only acceptance
+// criterion, for runners whose tests aren't about the report.
+const doneReport = plan.CompletionMarker + "\n" + `{"criteria": [{"criterion": "Done", "met": true}]}`
+
+// writeReport streams "All done." and report to output as text deltas,
+// splitting the report mid-JSON the way the stream delivers it.
+func writeReport(output OutputWriter, report string) error {
+	half := len(report) / 2
+	for _, text := range []string{"All done.\n\n", report[:half], report[half:]} {
+		data, err := json.Marshal(map[string]any{
+			"type":  "stream_event",
+			"event": map[string]any{"type": "content_block_delta", "delta": map[string]any{"type": "text_delta", "text": text}},
+		})
+		if err != nil {
+			return err
+		}
+		output.Stdout().Write(append(data, '\n'))
+	}
+	return nil
+}
+
+func TestRun_FailsAttemptOnUnmetCriteria(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "t01", Title: "Task 1", AcceptanceCriteria: []string{"Tests pass", "Docs updated"}, Status: plan.TaskStatusPending},
+	})
+	planDir := createTestPlanDir(t, p)
+	runner := &reportRunner{reports: []string{
+		plan.CompletionMarker + "\n```json\n" + `{"commitMessage": "Add task 1", "criteria": [{"criterion": "Tests pass", "met": true}, {"criterion": "Docs updated", "met": false, "evidence": "README not touched"}]}` + "\n```",
+		plan.CompletionMarker + "\n" + `{"commitMessage": "Add task 1", "criteria": [{"criterion": "Tests pass", "met": true}]}`,
+		plan.CompletionMarker + "\n" + `{"commitMessage": "Add task 1", "criteria": [{"criterion": "Tests pass", "met": true, "evidence": "go test ./..."}, {"criterion": "Docs updated", "met": true}], "risks": ["No migration"]}`,
+	}}
+
+	err := New(planDir, p).WithRunner(runner).WithEvents(&mockEvents{}).WithAllowDirty(true).Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(runner.prompts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(runner.prompts))
+	}
+	for _, want := range []string{
+		"- Attempt 1 failed: acceptance criteria not met: Docs updated (README not touched)",
+		"- Attempt 2 failed: completion report doesn't cover 1 of 2 acceptance criteria: Docs updated",
+	} {
+		if !strings.Contains(runner.prompts[2], want) {
+			t.Errorf("retry prompt missing %q:\n%s", want, runner.prompts[2])
+		}
+	}
+
+	saved, err := plan.LoadPlan(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := saved.Tasks[0].Report
+	if report == nil || report.CommitMessage != "Add task 1" || len(report.Criteria) != 2 || report.Criteria[0].Evidence != "go test ./..." || len(report.Risks) != 1 {
+		t.Errorf("expected the final report on the task, got %+v", report)
+	}
+}
+
+func TestRun_FailsAttemptWithoutRequestedReport(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "t01", Title: "Task 1", Status: plan.TaskStatusPending},
+	})
+	planDir := createTestPlanDir(t, p)
+	runner := &reportRunner{reports: []string{"", doneReport}}
+
+	e := New(planDir, p).WithRunner(runner).WithEvents(&mockEvents{}).WithAllowDirty(true)
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(runner.prompts) != 2 {
+		t.Fatalf("expected the missing report to fail the first attempt, got %d attempts", len(runner.prompts))
+	}
+	if failures := e.failures["t01"]; len(failures) != 1 || !strings.Contains(failures[0].Error, "no completion report") {
+		t.Errorf("expected the missing report in the failure history, got %+v", failures)
+	}
+
+	// A template that doesn't ask for a report doesn't get one checked.
+	p = createTestPlan([]plan.Task{
+		{ID: "t01", Title: "Task 1", Status: plan.TaskStatusPending},
+	})
+	planDir = createTestPlanDir(t, p)
+	rafaDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(rafaDir, prompt.DirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rafaDir, prompt.DirName, prompt.TaskTemplate), []byte("Do {{.Task.ID}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	prompts, err := prompt.Load(rafaDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner = &reportRunner{reports: []string{""}}
+	if err := New(planDir, p).WithRunner(runner).WithPrompts(prompts).WithEvents(&mockEvents{}).WithAllowDirty(true).Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(runner.prompts) != 1 {
+		t.Errorf("expected the attempt to pass without a report, got %d attempts", len(runner.prompts))
+	}
+}
+
 // TestRun_TaskFailure_NothingCommitted verifies that when a task fails,
 // no commit is made and the workspace remains dirty.
 func TestRun_TaskFailure_NothingCommitted(t *testing.T) {
@@ -1583,3 +1667,223 @@ func TestRun_WorkspaceCleanAfterSuccess(t *testing.T) {
 		}
 	}
 }
+
+func TestExecutor_WritesPerAttemptOutput(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "t01", Title: "Task 1", Status: plan.TaskStatusPending},
+	})
+	planDir := createTestPlanDir(t, p)
+
+	executor := New(planDir, p).WithAllowDirty(true)
+	executor.runner = runnerFunc(func(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error {
+		fmt.Fprintf(output.Stdout(), "output of attempt %d\n", attempt)
+		if attempt == 1 {
+			return errors.New("first attempt fails")
+		}
+		return nil
+	})
+
+	if err := executor.Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	attempts, err := plan.ListAttemptOutputs(planDir)
+	if err != nil {
+		t.Fatalf("ListAttemptOutputs() error: %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 indexed attempts, got %+v", attempts)
+	}
+	wantStatus := []string{plan.AttemptStatusFailed, plan.AttemptStatusCompleted}
+	for i, a := range attempts {
+		if a.TaskID != "t01" || a.Attempt != i+1 || a.Status != wantStatus[i] {
+			t.Errorf("attempt %d = %+v", i, a)
+		}
+		if _, err := os.Stat(filepath.Join(planDir, a.File+".gz")); err != nil {
+			t.Errorf("expected compressed output for attempt %d: %v", i+1, err)
+		}
+
+		rc, err := plan.OpenAttemptOutput(planDir, a)
+		if err != nil {
+			t.Fatalf("OpenAttemptOutput() error: %v", err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		want := fmt.Sprintf("output of attempt %d", i+1)
+		if !strings.Contains(string(content), want) {
+			t.Errorf("attempt %d output missing %q, got:\n%s", i+1, want, content)
+		}
+		other := fmt.Sprintf("output of attempt %d", 2-i)
+		if strings.Contains(string(content), other) {
+			t.Errorf("attempt %d output should not contain %q", i+1, other)
+		}
+	}
+}
+
+// streamRunner writes agent stream lines to the attempt output, then
+// returns the next error from Responses.
+type streamRunner struct {
+	Lines     []string
+	Responses []error
+	calls     int
+}
+
+func (r *streamRunner) Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error {
+	for _, line := range r.Lines {
+		fmt.Fprintln(output.Stdout(), line)
+	}
+	var err error
+	if r.calls < len(r.Responses) {
+		err = r.Responses[r.calls]
+	}
+	r.calls++
+	return err
+}
+
+func TestExecutor_WithTracer(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+	})
+	planDir := createTestPlanDir(t, p)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	runner := &streamRunner{
+		Lines: []string{
+			`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"tool_1","name":"Read","input":{"file_path":"main.go"}}]}}`,
+			`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"tool_1"}]}}`,
+			`{"type":"result","usage":{"input_tokens":100,"output_tokens":20},"total_cost_usd":0.01}`,
+		},
+		Responses: []error{errors.New("tests failed"), nil},
+	}
+	err := New(planDir, p).
+		WithRunner(runner).
+		WithTracer(telemetry.NewTracer(provider)).
+		WithAllowDirty(true).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	names := make(map[string]int)
+	for _, span := range recorder.Ended() {
+		names[span.Name()]++
+	}
+	if names["plan Test Plan"] != 1 || names["task task-1"] != 2 || names["tool Read"] != 2 {
+		t.Errorf("expected a run span, 2 attempt spans and a tool span per attempt, got %v", names)
+	}
+	for _, span := range recorder.Ended() {
+		if span.Name() != "plan Test Plan" {
+			continue
+		}
+		for _, kv := range span.Attributes() {
+			if kv.Key == telemetry.AttrOutcome && kv.Value.AsString() != plan.RunOutcomeCompleted {
+				t.Errorf("expected completed outcome, got %s", kv.Value.AsString())
+			}
+			if kv.Key == telemetry.AttrInputTokens && kv.Value.AsInt64() != 200 {
+				t.Errorf("expected 200 input tokens across attempts, got %d", kv.Value.AsInt64())
+			}
+		}
+	}
+}
+
+func TestFilterOutLockFile(t *testing.T) {
+	root := t.TempDir()
+	planDir := filepath.Join(root, ".rafa", "plans", "abc123-test")
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"id":"abc123","name":"test","tasks":[{"id":"t01","title":"First","description":"","acceptanceCriteria":[]}]}`
+	if err := os.WriteFile(filepath.Join(planDir, "plan.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plan.LoadPlan(planDir); err != nil {
+		t.Fatalf("failed to migrate plan: %v", err)
+	}
+
+	e := New(planDir, nil)
+	dirty := []string{
+		".rafa/plans/abc123-test/run.lock",
+		".rafa/plans/abc123-test/plan.json",
+		".rafa/plans/abc123-test/plan.json.v0.bak",
+		"main.go",
+	}
+	if got := e.filterOutLockFile(dirty); !reflect.DeepEqual(got, []string{"main.go"}) {
+		t.Errorf("expected the lock and migration to be ignored, got %v", got)
+	}
+
+	// A hand edit on top of the migration keeps plan.json dirty.
+	data, err := os.ReadFile(filepath.Join(planDir, "plan.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(data), `"First"`, `"Edited"`, 1)
+	if err := os.WriteFile(filepath.Join(planDir, "plan.json"), []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		".rafa/plans/abc123-test/plan.json",
+		".rafa/plans/abc123-test/plan.json.v0.bak",
+		"main.go",
+	}
+	if got := e.filterOutLockFile(dirty); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the edited plan to stay dirty, got %v", got)
+	}
+
+	// So does an edit without a migration.
+	if got := e.filterOutLockFile(dirty[:2]); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("expected plan.json to stay dirty, got %v", got)
+	}
+}
+
+func TestExecutor_CommitsCompressedAttemptOutput(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending, AcceptanceCriteria: []string{"done"}},
+		{ID: "task-2", Title: "Task 2", Status: plan.TaskStatusPending, AcceptanceCriteria: []string{"done"}},
+	})
+	planDir, root := createGitPlanRepo(t, p)
+	output, err := NewOutputCapture(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+	// Plan folders are created with an empty output.log.
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "add output.log"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		writeRepoFile(t, root, "src/"+task.ID+".go", "package src\n")
+		return nil
+	})
+	if err := New(planDir, p).WithRunner(runner).WithEvents(&mockEvents{}).WithOutput(output).Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Each task's commit holds its own compressed attempt output and nothing
+	// of the previous attempt's.
+	commits := commitFiles(t, root)
+	for _, id := range []string{"task-1", "task-2"} {
+		files := commits["[rafa] Complete task "+id+": Task "+id[len(id)-1:]]
+		if !strings.Contains(files, "src/"+id+".go") || !strings.Contains(files, "-"+id+"-attempt1.log.gz") {
+			t.Errorf("expected %s's commit to include its compressed output, got:\n%s", id, files)
+		}
+		if strings.Contains(files, "-attempt1.log\n") {
+			t.Errorf("expected no uncompressed attempt output in %s's commit, got:\n%s", id, files)
+		}
+	}
+	status, err := git.GetStatus(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range status.Files {
+		if strings.Contains(f, "/output/") {
+			t.Errorf("expected all attempt output to be committed, got %v", status.Files)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// Lifecycle hook names, as used in .rafa/config.json and RAFA_HOOK.
+const (
+	HookBeforePlan       = "before_plan"
+	HookBeforeTask       = "before_task"
+	HookAfterTaskSuccess = "after_task_success"
+	HookAfterTaskFailure = "after_task_failure"
+	HookAfterPlan        = "after_plan"
+)
+
+// hookTimeout bounds a single hook run.
+const hookTimeout = 10 * time.Minute
+
+// HookError is returned when a before_* hook exits non-zero and blocks the
+// plan or task.
+type HookError struct {
+	Hook string
+	Err  error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook failed: %v", e.Hook, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// hookPayload is the JSON a hook receives on stdin.
+type hookPayload struct {
+	Hook    string    `json:"hook"`
+	Plan    hookPlan  `json:"plan"`
+	Task    *hookTask `json:"task,omitempty"`
+	Outcome string    `json:"outcome,omitempty"` // after_plan only
+	Error   string    `json:"error,omitempty"`   // after_task_failure only
+}
+
+type hookPlan struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Dir    string `json:"dir"`
+	Status string `json:"status"`
+}
+
+type hookTask struct {
+	ID                 string   `json:"id"`
+	Title              string   `json:"title"`
+	Description        string   `json:"description"`
+	AcceptanceCriteria []string `json:"acceptanceCriteria"`
+	Index              int      `json:"index"` // 1-based
+	Attempt            int      `json:"attempt"`
+	MaxAttempts        int      `json:"maxAttempts"`
+}
+
+// WithHooks runs the configured lifecycle hooks during the run.
+func (e *Executor) WithHooks(h config.Hooks) *Executor {
+	e.hooks = h
+	return e
+}
+
+// hookCommand returns the command configured for a hook.
+func (e *Executor) hookCommand(name string) string {
+	switch name {
+	case HookBeforePlan:
+		return e.hooks.BeforePlan
+	case HookBeforeTask:
+		return e.hooks.BeforeTask
+	case HookAfterTaskSuccess:
+		return e.hooks.AfterTaskSuccess
+	case HookAfterTaskFailure:
+		return e.hooks.AfterTaskFailure
+	case HookAfterPlan:
+		return e.hooks.AfterPlan
+	}
+	return ""
+}
+
+// hookRun describes what a hook runs for.
+type hookRun struct {
+	task    *plan.Task // nil for plan hooks
+	idx     int        // 0-based index of task
+	attempt int
+	outcome string // after_plan only
+	err     error  // after_task_failure only
+}
+
+// runHook runs a hook with plan and task metadata in RAFA_* environment
+// variables and as JSON on stdin. The hook's output goes to the run output.
+// Hooks run from the repository root and are not run when unset.
+func (e *Executor) runHook(ctx context.Context, name string, run hookRun, output *OutputCapture) error {
+	command := e.hookCommand(name)
+	if command == "" {
+		return nil
+	}
+
+	payload := hookPayload{
+		Hook:    name,
+		Plan:    hookPlan{ID: e.plan.ID, Name: e.plan.Name, Dir: e.planDir, Status: e.plan.Status},
+		Outcome: run.outcome,
+	}
+	env := []string{
+		"RAFA_HOOK=" + name,
+		"RAFA_PLAN_ID=" + e.plan.ID,
+		"RAFA_PLAN_NAME=" + e.plan.Name,
+		"RAFA_PLAN_DIR=" + e.planDir,
+		"RAFA_PLAN_STATUS=" + e.plan.Status,
+	}
+	if task := run.task; task != nil {
+		payload.Task = &hookTask{
+			ID:                 task.ID,
+			Title:              task.Title,
+			Description:        task.Description,
+			AcceptanceCriteria: task.AcceptanceCriteria,
+			Index:              run.idx + 1,
+			Attempt:            run.attempt,
+			MaxAttempts:        MaxAttempts,
+		}
+		env = append(env,
+			"RAFA_TASK_ID="+task.ID,
+			"RAFA_TASK_TITLE="+task.Title,
+			"RAFA_TASK_INDEX="+strconv.Itoa(run.idx+1),
+			"RAFA_ATTEMPT="+strconv.Itoa(run.attempt),
+			"RAFA_MAX_ATTEMPTS="+strconv.Itoa(MaxAttempts),
+		)
+	}
+	if run.outcome != "" {
+		env = append(env, "RAFA_OUTCOME="+run.outcome)
+	}
+	if run.err != nil {
+		payload.Error = run.err.Error()
+		env = append(env, "RAFA_ERROR="+payload.Error)
+	}
+	stdin, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = e.repoRoot
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	out := e.hookOutput(output)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	fmt.Fprintf(out, "[rafa] Running %s hook\n", name)
+	return cmd.Run()
+}
+
+// hookOutput returns where hook output is written: the run output when
+// captured, stdout in plain CLI mode, nowhere otherwise.
+func (e *Executor) hookOutput(output *OutputCapture) io.Writer {
+	if output != nil {
+		// Stderr passes text through to the TUI unparsed.
+		return output.Stderr()
+	}
+	if e.events == nil {
+		return os.Stdout
+	}
+	return io.Discard
+}
+
+// runAfterHook runs an after_* hook, whose failure is only reported. It
+// also runs when ctx was cancelled, so after_plan sees cancelled runs.
+func (e *Executor) runAfterHook(ctx context.Context, name string, run hookRun, output *OutputCapture) {
+	if err := e.runHook(context.WithoutCancel(ctx), name, run, output); err != nil {
+		fmt.Fprintf(e.hookOutput(output), "[rafa] Warning: %s hook failed: %v\n", name, err)
+	}
+}
+
+// runBeforeHook runs a before_* hook, returning a *HookError if it fails.
+func (e *Executor) runBeforeHook(ctx context.Context, name string, run hookRun, output *OutputCapture) error {
+	if err := e.runHook(ctx, name, run, output); err != nil {
+		return &HookError{Hook: name, Err: err}
+	}
+	return nil
+}
+
+// isHookError reports whether err is a blocking hook failure.
+func isHookError(err error) bool {
+	var hookErr *HookError
+	return errors.As(err, &hookErr)
+}
@@ -0,0 +1,189 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// createHookTestPlanDir lays the plan out as .rafa/plans/<id> inside a repo
+// root, so hooks run from a known directory. It returns the plan dir and
+// the repo root.
+func createHookTestPlanDir(t *testing.T, p *plan.Plan) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	planDir := filepath.Join(root, ".rafa", "plans", "abc123-hooks")
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.SavePlan(planDir, p); err != nil {
+		t.Fatalf("failed to save test plan: %v", err)
+	}
+	return planDir, root
+}
+
+// recordHook appends the hook's name and metadata to hooks.log in the repo
+// root.
+const recordHook = `echo "$RAFA_HOOK $RAFA_TASK_ID $RAFA_ATTEMPT $RAFA_OUTCOME" >> hooks.log`
+
+func readHookLog(t *testing.T, root string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, "hooks.log"))
+	if err != nil {
+		t.Fatalf("failed to read hook log: %v", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	return lines
+}
+
+func TestHooks_RunAtLifecyclePoints(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+		{ID: "task-2", Title: "Task 2", Status: plan.TaskStatusPending},
+	})
+	planDir, root := createHookTestPlanDir(t, p)
+
+	calls := 0
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		calls++
+		if calls == 1 {
+			return errors.New("tests failed")
+		}
+		return nil
+	})
+	hooks := config.Hooks{
+		BeforePlan:       recordHook,
+		BeforeTask:       recordHook + "; cat > before_task.json",
+		AfterTaskSuccess: recordHook,
+		AfterTaskFailure: recordHook + `; echo "$RAFA_ERROR" > failure.txt`,
+		AfterPlan:        recordHook,
+	}
+
+	err := New(planDir, p).WithRunner(runner).WithHooks(hooks).WithEvents(&mockEvents{}).WithAllowDirty(true).Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	want := []string{
+		"before_plan",
+		"before_task task-1 1",
+		"after_task_failure task-1 1",
+		"before_task task-1 2",
+		"after_task_success task-1 2",
+		"before_task task-2 1",
+		"after_task_success task-2 1",
+		"after_plan completed",
+	}
+	got := readHookLog(t, root)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("hook calls:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	failure, err := os.ReadFile(filepath.Join(root, "failure.txt"))
+	if err != nil || strings.TrimSpace(string(failure)) != "tests failed" {
+		t.Errorf("expected RAFA_ERROR in after_task_failure, got %q (%v)", failure, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "before_task.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload hookPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("invalid hook payload %q: %v", data, err)
+	}
+	if payload.Hook != HookBeforeTask || payload.Plan.Name != "Test Plan" || payload.Plan.Dir != planDir {
+		t.Errorf("unexpected plan payload: %+v", payload)
+	}
+	if payload.Task == nil || payload.Task.ID != "task-2" || payload.Task.Index != 2 || payload.Task.MaxAttempts != MaxAttempts {
+		t.Errorf("unexpected task payload: %+v", payload.Task)
+	}
+}
+
+func TestHooks_BeforeTaskFailureBlocksTask(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+	})
+	planDir, root := createHookTestPlanDir(t, p)
+
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		t.Error("expected the task not to run")
+		return nil
+	})
+	events := &mockEvents{}
+	hooks := config.Hooks{
+		BeforeTask: "echo database is down; exit 2",
+		AfterPlan:  recordHook,
+	}
+
+	err := New(planDir, p).WithRunner(runner).WithHooks(hooks).WithEvents(events).WithAllowDirty(true).Run(context.Background())
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || hookErr.Hook != HookBeforeTask {
+		t.Fatalf("expected before_task HookError, got %v", err)
+	}
+
+	if p.Tasks[0].Status != plan.TaskStatusPending || p.Tasks[0].Attempts != 0 {
+		t.Errorf("expected blocked task to stay pending with no attempts, got %s/%d", p.Tasks[0].Status, p.Tasks[0].Attempts)
+	}
+	if len(events.planFails) != 1 || !strings.Contains(events.planFails[0].reason, "before_task hook failed") {
+		t.Errorf("expected plan failed event, got %+v", events.planFails)
+	}
+	if got := readHookLog(t, root); len(got) != 1 || got[0] != "after_plan failed" {
+		t.Errorf("expected after_plan to run with failed outcome, got %v", got)
+	}
+}
+
+func TestHooks_BeforePlanFailureBlocksRun(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+	})
+	planDir, _ := createHookTestPlanDir(t, p)
+
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		t.Error("expected no task to run")
+		return nil
+	})
+	err := New(planDir, p).
+		WithRunner(runner).
+		WithHooks(config.Hooks{BeforePlan: "exit 1"}).
+		WithEvents(&mockEvents{}).
+		WithAllowDirty(true).
+		Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "before_plan hook failed") {
+		t.Fatalf("expected before_plan failure, got %v", err)
+	}
+}
+
+func TestHooks_AfterHookFailureDoesNotBlock(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending},
+	})
+	planDir, _ := createHookTestPlanDir(t, p)
+
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error { return nil })
+	err := New(planDir, p).
+		WithRunner(runner).
+		WithHooks(config.Hooks{AfterTaskSuccess: "exit 1", AfterPlan: "exit 1"}).
+		WithEvents(&mockEvents{}).
+		WithAllowDirty(true).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected after_* failures to be ignored, got %v", err)
+	}
+	if p.Tasks[0].Status != plan.TaskStatusCompleted {
+		t.Errorf("expected task to complete, got %s", p.Tasks[0].Status)
+	}
+}
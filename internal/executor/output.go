@@ -8,10 +8,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
 )
 
-const outputLogFileName = "output.log"
+const outputLogFileName = plan.OutputLogFileName
 
 const streamChunkFlushBytes = 768
 
@@ -43,14 +46,54 @@ func (h StreamHooks) hasCallbacks() bool {
 		h.OnAssistantBoundary != nil
 }
 
-// OutputCapture manages output to both terminal and log file.
+// OutputCapture manages output to both terminal and log files.
+// Each task attempt is written to its own file under the plan's output/
+// folder (see WriteTaskHeader); output written outside an attempt goes to
+// the legacy output.log.
 type OutputCapture struct {
+	planDir    string
 	logFile    *os.File
+	sink       *attemptSink
 	multiOut   io.Writer
 	multiErr   io.Writer
 	eventsChan chan string // For TUI consumption; nil when not streaming
 }
 
+// attemptSink routes writes to the current attempt's file, or to output.log
+// when no attempt is active.
+type attemptSink struct {
+	mu       sync.Mutex
+	fallback *os.File
+	attempt  *os.File
+}
+
+func (s *attemptSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attempt != nil {
+		return s.attempt.Write(p)
+	}
+	return s.fallback.Write(p)
+}
+
+// swap replaces the current attempt file and returns the previous one.
+func (s *attemptSink) swap(f *os.File) *os.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.attempt
+	s.attempt = f
+	return prev
+}
+
+func (s *attemptSink) current() *os.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attempt != nil {
+		return s.attempt
+	}
+	return s.fallback
+}
+
 // NewOutputCapture creates an output capture for the given plan directory.
 // Opens output.log in append mode to preserve history across runs.
 func NewOutputCapture(planDir string) (*OutputCapture, error) {
@@ -75,8 +118,11 @@ func NewOutputCaptureWithEventsAndHooks(planDir string, eventsChan chan string,
 		return nil, err
 	}
 
+	sink := &attemptSink{fallback: f}
 	oc := &OutputCapture{
+		planDir:    planDir,
 		logFile:    f,
+		sink:       sink,
 		eventsChan: eventsChan,
 	}
 
@@ -85,11 +131,11 @@ func NewOutputCaptureWithEventsAndHooks(planDir string, eventsChan chan string,
 	// In TUI mode, we only write to the log file and stream to the channel
 	// (not to stdout/stderr, which would corrupt the TUI display)
 	if eventsChan != nil || hooks.hasCallbacks() {
-		stdoutUnderlying := io.Writer(f)
-		stderrUnderlying := io.Writer(f)
+		stdoutUnderlying := io.Writer(sink)
+		stderrUnderlying := io.Writer(sink)
 		if eventsChan == nil {
-			stdoutUnderlying = io.MultiWriter(os.Stdout, f)
-			stderrUnderlying = io.MultiWriter(os.Stderr, f)
+			stdoutUnderlying = io.MultiWriter(os.Stdout, sink)
+			stderrUnderlying = io.MultiWriter(os.Stderr, sink)
 		}
 
 		streamingOut := &streamingWriter{
@@ -111,8 +157,8 @@ func NewOutputCaptureWithEventsAndHooks(planDir string, eventsChan chan string,
 			oc.multiErr = stderrUnderlying
 		}
 	} else {
-		oc.multiOut = io.MultiWriter(os.Stdout, f)
-		oc.multiErr = io.MultiWriter(os.Stderr, f)
+		oc.multiOut = io.MultiWriter(os.Stdout, sink)
+		oc.multiErr = io.MultiWriter(os.Stderr, sink)
 	}
 
 	return oc, nil
@@ -256,7 +302,9 @@ type toolUseEvent struct {
 }
 
 type toolResultEvent struct {
-	ToolID string
+	ToolID  string
+	IsError bool
+	Output  string
 }
 
 type usageEvent struct {
@@ -284,6 +332,7 @@ type streamEvent struct {
 		OutputTokens int64 `json:"output_tokens"`
 	} `json:"usage,omitempty"`
 	TotalCostUSD float64 `json:"total_cost_usd,omitempty"`
+	Result       string  `json:"result,omitempty"`
 }
 
 type streamDelta struct {
@@ -305,6 +354,33 @@ type streamContent struct {
 	Name      string                 `json:"name,omitempty"`
 	Input     map[string]interface{} `json:"input,omitempty"`
 	ToolUseID string                 `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage        `json:"content,omitempty"` // tool_result output: a string or text blocks
+	IsError   bool                   `json:"is_error,omitempty"`
+}
+
+// toolResultText returns the text of a tool_result content block.
+func toolResultText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" && b.Text != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
 }
 
 // FormatStreamLine parses a JSON stream line and extracts displayable text.
@@ -384,7 +460,9 @@ func parseStreamLineDetails(line string) parsedStreamLine {
 				if c.Type == "tool_result" {
 					return parsedStreamLine{
 						ToolResult: &toolResultEvent{
-							ToolID: c.ToolUseID,
+							ToolID:  c.ToolUseID,
+							IsError: c.IsError,
+							Output:  toolResultText(c.Content),
 						},
 						Flush: true,
 					}
@@ -443,8 +521,14 @@ func (oc *OutputCapture) Stderr() io.Writer {
 	return oc.multiErr
 }
 
-// Close closes the log file. Safe to call when no log file is open.
+// Close closes the log files. An attempt that is still open (e.g. after a
+// cancelled run) is left uncompressed. Safe to call when no log file is open.
 func (oc *OutputCapture) Close() error {
+	if oc.sink != nil {
+		if f := oc.sink.swap(nil); f != nil {
+			f.Close()
+		}
+	}
 	if oc.logFile != nil {
 		return oc.logFile.Close()
 	}
@@ -456,18 +540,44 @@ func (oc *OutputCapture) EventsChan() chan string {
 	return oc.eventsChan
 }
 
-// WriteTaskHeader writes a header line to the log for a new task attempt.
+// WriteTaskHeader starts a new task attempt: it opens the attempt's own
+// output file (see plan.NewAttemptOutputFile) and writes a header line to it.
+// If the file cannot be created, the attempt is written to output.log.
 // Safe to call when no log file is open.
 func (oc *OutputCapture) WriteTaskHeader(taskID string, attempt int) {
 	if oc.logFile == nil {
 		return
 	}
+
+	started := time.Now()
+	if oc.sink != nil && oc.planDir != "" {
+		oc.finishAttempt()
+		rel := plan.NewAttemptOutputFile(taskID, attempt, started)
+		fullPath := filepath.Join(oc.planDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err == nil {
+			if f, err := os.OpenFile(fullPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+				oc.sink.swap(f)
+			}
+		}
+	}
+
+	w := oc.currentFile()
 	header := fmt.Sprintf("\n=== Task %s, Attempt %d ===\n", taskID, attempt)
-	oc.logFile.WriteString(header)
-	oc.logFile.WriteString(fmt.Sprintf("Started: %s\n\n", time.Now().Format(time.RFC3339)))
+	w.WriteString(header)
+	w.WriteString(fmt.Sprintf("Started: %s\n\n", started.Format(time.RFC3339)))
+}
+
+// WriteReviewHeader marks the start of the reviewer's output within the
+// current attempt. Safe to call when no log file is open.
+func (oc *OutputCapture) WriteReviewHeader() {
+	if oc.logFile == nil {
+		return
+	}
+	oc.currentFile().WriteString("\n=== Review ===\n\n")
 }
 
-// WriteTaskFooter writes a footer line to the log after task completion.
+// WriteTaskFooter writes a footer line for the current attempt, then closes
+// and compresses the attempt's output file.
 // Safe to call when no log file is open.
 func (oc *OutputCapture) WriteTaskFooter(taskID string, success bool) {
 	if oc.logFile == nil {
@@ -478,107 +588,106 @@ func (oc *OutputCapture) WriteTaskFooter(taskID string, success bool) {
 		result = "FAILED"
 	}
 	footer := fmt.Sprintf("\n=== Task %s: %s ===\n\n", taskID, result)
-	oc.logFile.WriteString(footer)
+	oc.currentFile().WriteString(footer)
+	oc.finishAttempt()
 }
 
-const (
-	commitMessagePrefix = "SUGGESTED_COMMIT_MESSAGE:"
-	maxLinesToSearch    = 100
-)
-
-// ExtractCommitMessage searches the captured output for a suggested commit message.
-// It handles both JSON stream format and plain text format.
-// For JSON, it searches text_delta events for the commit message prefix.
-// Returns the trimmed message after the prefix, or empty string if no message is found.
-// Searches the last 100 lines for efficiency. If multiple messages exist, returns the
-// most recent one. Callers should ensure the log file is synced before calling.
-func (oc *OutputCapture) ExtractCommitMessage() string {
-	// Get the log file path from the open file
-	logPath := oc.logFile.Name()
-
-	// Open for reading (the file is opened write-only, so we need a separate read handle)
-	f, err := os.Open(logPath)
-	if err != nil {
+// AttemptFile returns the plan-relative path of the current attempt's output
+// file, or "" when output is going to output.log.
+func (oc *OutputCapture) AttemptFile() string {
+	if oc.sink == nil {
 		return ""
 	}
-	defer f.Close()
-
-	// Read all lines into a slice
-	var lines []string
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	f := oc.sink.current()
+	if f == nil || f == oc.logFile {
+		return ""
 	}
-	if scanner.Err() != nil {
+	rel, err := filepath.Rel(oc.planDir, f.Name())
+	if err != nil {
 		return ""
 	}
+	return filepath.ToSlash(rel)
+}
 
-	// Determine start index for searching (last 100 lines)
-	start := 0
-	if len(lines) > maxLinesToSearch {
-		start = len(lines) - maxLinesToSearch
+// finishAttempt closes and compresses the current attempt file, if any.
+// Compression is best effort; the plain file is kept if it fails.
+func (oc *OutputCapture) finishAttempt() {
+	if oc.sink == nil {
+		return
 	}
-
-	// Search from most recent lines first (reverse order)
-	for i := len(lines) - 1; i >= start; i-- {
-		line := lines[i]
-
-		// Try to extract commit message from this line (handles both JSON and plain text)
-		if msg := extractCommitMessageFromLine(line); msg != "" {
-			return msg
-		}
+	f := oc.sink.swap(nil)
+	if f == nil {
+		return
 	}
-
-	return ""
+	name := f.Name()
+	f.Close()
+	_ = plan.CompressOutputFile(name)
 }
 
-// extractCommitMessageFromLine extracts a commit message from a single line.
-// Handles both JSON stream format and plain text format.
-func extractCommitMessageFromLine(line string) string {
-	// First, check for plain text format
-	if strings.HasPrefix(line, commitMessagePrefix) {
-		return strings.TrimSpace(strings.TrimPrefix(line, commitMessagePrefix))
+// currentFile returns the file receiving output: the open attempt file or
+// output.log.
+func (oc *OutputCapture) currentFile() *os.File {
+	if oc.sink == nil {
+		return oc.logFile
 	}
+	return oc.sink.current()
+}
 
-	// Try to parse as JSON (Claude stream-json format)
-	var event streamEvent
-	if err := json.Unmarshal([]byte(line), &event); err != nil {
-		return ""
+// CompletionReport parses the completion report from the current attempt's
+// output (or output.log outside an attempt). It returns nil and no error
+// when the agent gave no report. The report is looked for in the agent's
+// complete messages first, then in its final result and streamed text, so
+// a report split across stream deltas is still found. Callers should
+// ensure the log file is synced before calling.
+func (oc *OutputCapture) CompletionReport() (*plan.CompletionReport, error) {
+	// Only the current attempt is searched when it has its own file.
+	logPath := oc.currentFile().Name()
+
+	// Open for reading (the file is opened write-only, so we need a separate read handle)
+	f, err := os.Open(logPath)
+	if err != nil {
+		return nil, nil
 	}
+	defer f.Close()
 
-	// Check stream_event with text_delta
-	if event.Type == "stream_event" && event.Event != nil {
-		if event.Event.Type == "content_block_delta" && event.Event.Delta != nil {
-			if event.Event.Delta.Type == "text_delta" {
-				text := event.Event.Delta.Text
-				if strings.Contains(text, commitMessagePrefix) {
-					// Extract the message from the delta text
-					idx := strings.Index(text, commitMessagePrefix)
-					msg := text[idx+len(commitMessagePrefix):]
-					// Handle case where message might span multiple deltas - just get this part
-					msg = strings.TrimSpace(msg)
-					// Remove trailing newline if present
-					msg = strings.TrimSuffix(msg, "\n")
-					return msg
-				}
-			}
+	for _, text := range agentTexts(f) {
+		if report, err := plan.ParseCompletionReport(text); report != nil || err != nil {
+			return report, err
 		}
 	}
+	return nil, nil
+}
 
-	// Check assistant message content
-	if event.Type == "assistant" && event.Message != nil {
-		for _, c := range event.Message.Content {
-			if c.Type == "text" && strings.Contains(c.Text, commitMessagePrefix) {
-				idx := strings.Index(c.Text, commitMessagePrefix)
-				msg := c.Text[idx+len(commitMessagePrefix):]
-				// Take up to the first newline
-				if nlIdx := strings.Index(msg, "\n"); nlIdx != -1 {
-					msg = msg[:nlIdx]
+// agentTexts returns the agent's text in stream-json output, most reliable
+// source first: its complete messages, its final result, its streamed text
+// deltas, and lines that aren't stream-json at all.
+func agentTexts(r io.Reader) []string {
+	var messages, results, deltas, plain strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxReportLineBytes)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var event streamEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil || event.Type == "" {
+			plain.WriteString(line + "\n")
+			continue
+		}
+		switch {
+		case event.Type == "assistant" && event.Message != nil:
+			for _, c := range event.Message.Content {
+				if c.Type == "text" {
+					messages.WriteString(c.Text + "\n")
 				}
-				return strings.TrimSpace(msg)
 			}
+		case event.Type == "result":
+			results.WriteString(event.Result + "\n")
+		case event.Type == "stream_event" && event.Event != nil && event.Event.Delta != nil && event.Event.Delta.Type == "text_delta":
+			deltas.WriteString(event.Event.Delta.Text)
 		}
 	}
-
-	return ""
+	return []string{messages.String(), results.String(), deltas.String(), plain.String()}
 }
+
+// maxReportLineBytes bounds the output lines read for the completion
+// report; tool results can make stream-json lines long.
+const maxReportLineBytes = 16 * 1024 * 1024
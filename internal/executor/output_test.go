@@ -7,8 +7,26 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
 )
 
+// readPlanOutput reads all captured output of a plan, across output.log and
+// the per-attempt files.
+func readPlanOutput(t *testing.T, planDir string) string {
+	t.Helper()
+	rc, err := plan.OpenOutputLog(planDir)
+	if err != nil {
+		t.Fatalf("OpenOutputLog() error: %v", err)
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	return string(content)
+}
+
 func TestOutputCapture_WritesToFile(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -98,14 +116,7 @@ func TestOutputCapture_TaskHeaders(t *testing.T) {
 
 	oc.Close()
 
-	// Read the log file
-	logPath := filepath.Join(tmpDir, outputLogFileName)
-	content, err := os.ReadFile(logPath)
-	if err != nil {
-		t.Fatalf("failed to read log file: %v", err)
-	}
-
-	contentStr := string(content)
+	contentStr := readPlanOutput(t, tmpDir)
 
 	// Verify header format
 	if !strings.Contains(contentStr, "=== Task t01, Attempt 1 ===") {
@@ -175,13 +186,7 @@ func TestOutputCapture_AppendsToExistingFile(t *testing.T) {
 	oc.WriteTaskHeader("t01", 1)
 	oc.Close()
 
-	// Read the log file
-	content, err := os.ReadFile(logPath)
-	if err != nil {
-		t.Fatalf("failed to read log file: %v", err)
-	}
-
-	contentStr := string(content)
+	contentStr := readPlanOutput(t, tmpDir)
 
 	// Verify original content is preserved
 	if !strings.Contains(contentStr, "Previous run content") {
@@ -299,146 +304,61 @@ func TestOutputCapture_WriterInterfaces(t *testing.T) {
 	}
 }
 
-func TestOutputCapture_ExtractCommitMessage_Found(t *testing.T) {
+func TestOutputCapture_CompletionReport_PlainText(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	oc, err := NewOutputCapture(tmpDir)
 	if err != nil {
 		t.Fatalf("NewOutputCapture() error: %v", err)
 	}
+	defer oc.Close()
 
-	// Write some output including a commit message
 	oc.Stdout().Write([]byte("Some output\n"))
-	oc.Stdout().Write([]byte("More output\n"))
-	oc.Stdout().Write([]byte("SUGGESTED_COMMIT_MESSAGE: Add new feature for user authentication\n"))
+	oc.Stdout().Write([]byte(plan.CompletionMarker + "\n"))
+	oc.Stdout().Write([]byte(`{"commitMessage": "  Add new feature for user authentication  ", "criteria": [{"criterion": "Tests pass", "met": true}]}` + "\n"))
 	oc.Stdout().Write([]byte("Final output\n"))
 
-	// Need to sync the file before reading
-	oc.logFile.Sync()
-
-	msg := oc.ExtractCommitMessage()
-	expected := "Add new feature for user authentication"
-	if msg != expected {
-		t.Errorf("ExtractCommitMessage() = %q, want %q", msg, expected)
-	}
-
-	oc.Close()
-}
-
-func TestOutputCapture_ExtractCommitMessage_NotFound(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	oc, err := NewOutputCapture(tmpDir)
+	report, err := oc.CompletionReport()
 	if err != nil {
-		t.Fatalf("NewOutputCapture() error: %v", err)
+		t.Fatalf("CompletionReport() error: %v", err)
 	}
-
-	// Write output without a commit message
-	oc.Stdout().Write([]byte("Some output\n"))
-	oc.Stdout().Write([]byte("More output\n"))
-	oc.Stdout().Write([]byte("No commit message here\n"))
-
-	oc.logFile.Sync()
-
-	msg := oc.ExtractCommitMessage()
-	if msg != "" {
-		t.Errorf("ExtractCommitMessage() = %q, want empty string", msg)
+	if report == nil || report.CommitMessage != "Add new feature for user authentication" || len(report.Criteria) != 1 {
+		t.Errorf("CompletionReport() = %+v", report)
 	}
-
-	oc.Close()
 }
 
-func TestOutputCapture_ExtractCommitMessage_EmptyOutput(t *testing.T) {
+func TestOutputCapture_CompletionReport_NotFound(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	oc, err := NewOutputCapture(tmpDir)
 	if err != nil {
 		t.Fatalf("NewOutputCapture() error: %v", err)
 	}
+	defer oc.Close()
 
-	// Don't write anything
-	oc.logFile.Sync()
-
-	msg := oc.ExtractCommitMessage()
-	if msg != "" {
-		t.Errorf("ExtractCommitMessage() = %q, want empty string", msg)
-	}
-
-	oc.Close()
-}
-
-func TestOutputCapture_ExtractCommitMessage_OnlyLast100Lines(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	oc, err := NewOutputCapture(tmpDir)
-	if err != nil {
-		t.Fatalf("NewOutputCapture() error: %v", err)
-	}
-
-	// Write a commit message that will be beyond the last 100 lines
-	oc.Stdout().Write([]byte("SUGGESTED_COMMIT_MESSAGE: Old message that should be ignored\n"))
-
-	// Write 100 more lines to push it out of range
-	for i := 0; i < 100; i++ {
-		oc.Stdout().Write([]byte("Filler line\n"))
-	}
-
-	oc.logFile.Sync()
-
-	msg := oc.ExtractCommitMessage()
-	if msg != "" {
-		t.Errorf("ExtractCommitMessage() = %q, want empty string (message should be outside last 100 lines)", msg)
-	}
-
-	oc.Close()
-}
-
-func TestOutputCapture_ExtractCommitMessage_TakesLastMessage(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	oc, err := NewOutputCapture(tmpDir)
-	if err != nil {
-		t.Fatalf("NewOutputCapture() error: %v", err)
+	if report, err := oc.CompletionReport(); report != nil || err != nil {
+		t.Errorf("CompletionReport() on empty output = %+v, %v", report, err)
 	}
 
-	// Write multiple commit messages - should return the last one
-	oc.Stdout().Write([]byte("SUGGESTED_COMMIT_MESSAGE: First message\n"))
-	oc.Stdout().Write([]byte("Some output\n"))
-	oc.Stdout().Write([]byte("SUGGESTED_COMMIT_MESSAGE: Second message\n"))
-	oc.Stdout().Write([]byte("More output\n"))
-	oc.Stdout().Write([]byte("SUGGESTED_COMMIT_MESSAGE: Third and final message\n"))
-
-	oc.logFile.Sync()
-
-	msg := oc.ExtractCommitMessage()
-	expected := "Third and final message"
-	if msg != expected {
-		t.Errorf("ExtractCommitMessage() = %q, want %q", msg, expected)
+	oc.Stdout().Write([]byte("No report here\n"))
+	if report, err := oc.CompletionReport(); report != nil || err != nil {
+		t.Errorf("CompletionReport() = %+v, %v, want nil", report, err)
 	}
-
-	oc.Close()
 }
 
-func TestOutputCapture_ExtractCommitMessage_TrimsWhitespace(t *testing.T) {
+func TestOutputCapture_CompletionReport_Malformed(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	oc, err := NewOutputCapture(tmpDir)
 	if err != nil {
 		t.Fatalf("NewOutputCapture() error: %v", err)
 	}
+	defer oc.Close()
 
-	// Write a commit message with extra whitespace
-	oc.Stdout().Write([]byte("SUGGESTED_COMMIT_MESSAGE:   Message with spaces   \n"))
-
-	oc.logFile.Sync()
-
-	msg := oc.ExtractCommitMessage()
-	expected := "Message with spaces"
-	if msg != expected {
-		t.Errorf("ExtractCommitMessage() = %q, want %q", msg, expected)
+	oc.Stdout().Write([]byte(plan.CompletionMarker + "\n{\"commitMessage\": \n"))
+	if _, err := oc.CompletionReport(); err == nil {
+		t.Error("expected an error for a truncated report")
 	}
-
-	oc.Close()
 }
 
 func TestOutputCapture_WithEventsChan_StreamsOutput(t *testing.T) {
@@ -1014,49 +934,118 @@ func TestStreamingWriter_Events_AssistantBoundaryChunkAfterText(t *testing.T) {
 	}
 }
 
-func TestExtractCommitMessage_JSONFormat(t *testing.T) {
+func TestOutputCapture_CompletionReport_StreamDeltas(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	oc, err := NewOutputCapture(tmpDir)
 	if err != nil {
 		t.Fatalf("NewOutputCapture() error: %v", err)
 	}
+	defer oc.Close()
 
-	// Write JSON stream events including one with commit message
+	// The report arrives split across deltas.
 	oc.Stdout().Write([]byte(`{"type":"system","subtype":"init"}` + "\n"))
-	oc.Stdout().Write([]byte(`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"Working on the task..."}}}` + "\n"))
-	oc.Stdout().Write([]byte(`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"SUGGESTED_COMMIT_MESSAGE: Add JSON streaming support"}}}` + "\n"))
+	oc.Stdout().Write([]byte(`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"Done.\nRAFA_COMPLETION_"}}}` + "\n"))
+	oc.Stdout().Write([]byte(`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"REPORT\n{\"commitMessage\": \"Add JSON"}}}` + "\n"))
+	oc.Stdout().Write([]byte(`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":" streaming support\", \"criteria\": []}"}}}` + "\n"))
 	oc.Stdout().Write([]byte(`{"type":"result","subtype":"success"}` + "\n"))
 
-	oc.logFile.Sync()
+	report, err := oc.CompletionReport()
+	if err != nil {
+		t.Fatalf("CompletionReport() error: %v", err)
+	}
+	if report == nil || report.CommitMessage != "Add JSON streaming support" {
+		t.Errorf("CompletionReport() = %+v", report)
+	}
+}
+
+func TestOutputCapture_CompletionReport_AssistantMessage(t *testing.T) {
+	tmpDir := t.TempDir()
 
-	msg := oc.ExtractCommitMessage()
-	expected := "Add JSON streaming support"
-	if msg != expected {
-		t.Errorf("ExtractCommitMessage() = %q, want %q", msg, expected)
+	oc, err := NewOutputCapture(tmpDir)
+	if err != nil {
+		t.Fatalf("NewOutputCapture() error: %v", err)
 	}
+	defer oc.Close()
 
-	oc.Close()
+	// A quoted marker in an earlier message is superseded by the final report.
+	oc.Stdout().Write([]byte(`{"type":"assistant","message":{"content":[{"type":"text","text":"I'll finish with RAFA_COMPLETION_REPORT as asked."}]}}` + "\n"))
+	oc.Stdout().Write([]byte(`{"type":"assistant","message":{"content":[{"type":"text","text":"Done!\nRAFA_COMPLETION_REPORT\n` + "```json" + `\n{\"commitMessage\": \"Implement feature X\", \"criteria\": [{\"criterion\": \"Tests pass\", \"met\": false}]}\n` + "```" + `\nAll tests pass."}]}}` + "\n"))
+
+	report, err := oc.CompletionReport()
+	if err != nil {
+		t.Fatalf("CompletionReport() error: %v", err)
+	}
+	if report == nil || report.CommitMessage != "Implement feature X" || len(report.Criteria) != 1 || report.Criteria[0].Met {
+		t.Errorf("CompletionReport() = %+v", report)
+	}
 }
 
-func TestExtractCommitMessage_AssistantMessage(t *testing.T) {
+func TestOutputCapture_CompletionReport_ResultEvent(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	oc, err := NewOutputCapture(tmpDir)
 	if err != nil {
 		t.Fatalf("NewOutputCapture() error: %v", err)
 	}
+	defer oc.Close()
 
-	// Write assistant message with commit message in content
-	oc.Stdout().Write([]byte(`{"type":"assistant","message":{"content":[{"type":"text","text":"Done!\nSUGGESTED_COMMIT_MESSAGE: Implement feature X\nAll tests pass."}]}}` + "\n"))
+	oc.Stdout().Write([]byte(`{"type":"result","subtype":"success","result":"RAFA_COMPLETION_REPORT {\"commitMessage\": \"Fix parser\", \"criteria\": []}"}` + "\n"))
 
-	oc.logFile.Sync()
+	report, err := oc.CompletionReport()
+	if err != nil {
+		t.Fatalf("CompletionReport() error: %v", err)
+	}
+	if report == nil || report.CommitMessage != "Fix parser" {
+		t.Errorf("CompletionReport() = %+v", report)
+	}
+}
 
-	msg := oc.ExtractCommitMessage()
-	expected := "Implement feature X"
-	if msg != expected {
-		t.Errorf("ExtractCommitMessage() = %q, want %q", msg, expected)
+func TestOutputCapture_PerAttemptFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oc, err := NewOutputCapture(tmpDir)
+	if err != nil {
+		t.Fatalf("NewOutputCapture() error: %v", err)
 	}
+	defer oc.Close()
 
-	oc.Close()
+	if got := oc.AttemptFile(); got != "" {
+		t.Errorf("AttemptFile() before header = %q, want empty", got)
+	}
+
+	oc.WriteTaskHeader("t01", 1)
+	first := oc.AttemptFile()
+	if !strings.HasPrefix(first, "output/") || !strings.HasSuffix(first, "-t01-attempt1.log") {
+		t.Fatalf("unexpected attempt file %q", first)
+	}
+	oc.Stdout().Write([]byte(plan.CompletionMarker + ` {"commitMessage": "From attempt one"}` + "\n"))
+	if report, _ := oc.CompletionReport(); report == nil || report.CommitMessage != "From attempt one" {
+		t.Errorf("CompletionReport() = %+v, want the attempt's report", report)
+	}
+	oc.WriteTaskFooter("t01", false)
+
+	if oc.AttemptFile() != "" {
+		t.Error("AttemptFile() should be empty after the footer")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, first+".gz")); err != nil {
+		t.Errorf("expected finished attempt to be compressed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, first)); !os.IsNotExist(err) {
+		t.Error("uncompressed attempt file should be removed")
+	}
+
+	// A new attempt must not pick up the previous attempt's report.
+	oc.WriteTaskHeader("t01", 2)
+	if report, _ := oc.CompletionReport(); report != nil {
+		t.Errorf("CompletionReport() on new attempt = %+v, want nil", report)
+	}
+
+	legacy, err := os.ReadFile(filepath.Join(tmpDir, outputLogFileName))
+	if err != nil {
+		t.Fatalf("failed to read output.log: %v", err)
+	}
+	if len(legacy) != 0 {
+		t.Errorf("attempt output should not be written to output.log, got: %s", legacy)
+	}
 }
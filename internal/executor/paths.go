@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pablasso/rafa/internal/git"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// WithPaths sets the project's path policy, which the plan's and the
+// task's policies in plan.json override.
+func (e *Executor) WithPaths(p plan.PathPolicy) *Executor {
+	e.paths = p
+	return e
+}
+
+// checkPaths checks the current attempt's changes against task's path
+// policy. Violations fail the attempt, or are reverted when the policy says
+// so. Rafa's own files under .rafa are exempt. Nothing is checked with
+// AllowDirty, since the changes may not be the agent's.
+func (e *Executor) checkPaths(task *plan.Task, output *OutputCapture) error {
+	policy := plan.ResolvePaths(&e.paths, e.plan.Paths, task.Paths)
+	if policy.IsZero() || e.allowDirty {
+		return nil
+	}
+	paths, err := git.ChangedPaths(e.repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to check changed paths: %w", err)
+	}
+	violations := policy.Check(changedFiles(paths))
+	if len(violations) == 0 {
+		return nil
+	}
+
+	descs := make([]string, len(violations))
+	for i, v := range violations {
+		descs[i] = v.String()
+	}
+	if !policy.Revert() {
+		return fmt.Errorf("changed paths outside the task's path policy: %s; undo these changes", strings.Join(descs, ", "))
+	}
+
+	reverted := make([]string, len(violations))
+	for i, v := range violations {
+		reverted[i] = v.Path
+	}
+	if err := git.RevertPaths(e.repoRoot, reverted); err != nil {
+		return err
+	}
+	note := fmt.Sprintf("Reverted changes outside the task's path policy: %s\n", strings.Join(descs, ", "))
+	if output != nil {
+		output.Stdout().Write([]byte("\n" + note))
+	} else if e.events == nil {
+		fmt.Print(note)
+	}
+	return nil
+}
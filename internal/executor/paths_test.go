@@ -0,0 +1,216 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// createGitPlanRepo lays the plan out in a git repository with README.md
+// and commits it, so attempts start from a clean workspace. It returns the
+// plan dir and the repo root.
+func createGitPlanRepo(t *testing.T, p *plan.Plan) (string, string) {
+	t.Helper()
+	planDir, root := createHookTestPlanDir(t, p)
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return planDir, root
+}
+
+func writeRepoFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// committedFiles returns the files committed since the initial commit.
+func committedFiles(t *testing.T, root string) string {
+	t.Helper()
+	cmd := exec.Command("git", "rev-list", "--max-parents=0", "HEAD")
+	cmd.Dir = root
+	initial, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git rev-list: %v", err)
+	}
+	cmd = exec.Command("git", "diff", "--name-only", strings.TrimSpace(string(initial)), "HEAD")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git diff: %v", err)
+	}
+	return string(out)
+}
+
+// commitFiles returns the files changed by each commit after the initial
+// one, keyed by commit subject.
+func commitFiles(t *testing.T, root string) map[string]string {
+	t.Helper()
+	cmd := exec.Command("git", "log", "--format=%x00%s", "--name-only")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git log: %v", err)
+	}
+	commits := make(map[string]string)
+	for _, entry := range strings.Split(string(out), "\x00")[1:] {
+		subject, files, _ := strings.Cut(entry, "\n")
+		commits[subject] = files
+	}
+	return commits
+}
+
+func TestExecutor_PathViolationFailsAttempt(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending, AcceptanceCriteria: []string{"done"}},
+	})
+	p.Paths = &plan.PathPolicy{Forbidden: []string{".github/**"}}
+	planDir, root := createGitPlanRepo(t, p)
+
+	calls := 0
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		calls++
+		writeRepoFile(t, root, "src/app.go", "package app\n")
+		if calls == 1 {
+			writeRepoFile(t, root, ".github/workflows/ci.yml", "on: push\n")
+		} else {
+			os.RemoveAll(filepath.Join(root, ".github"))
+		}
+		return nil
+	})
+
+	e := New(planDir, p).WithRunner(runner).WithEvents(&mockEvents{})
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("expected the violation to fail the first attempt, got %d attempts", calls)
+	}
+	failures := e.failures["task-1"]
+	if len(failures) != 1 || !strings.Contains(failures[0].Error, ".github/workflows/ci.yml (forbidden by .github/**)") {
+		t.Errorf("expected the violation in the retry prompt's failures, got %+v", failures)
+	}
+	if files := committedFiles(t, root); strings.Contains(files, ".github") || !strings.Contains(files, "src/app.go") {
+		t.Errorf("unexpected committed files:\n%s", files)
+	}
+}
+
+func TestExecutor_PathViolationReverted(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{
+			ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending, AcceptanceCriteria: []string{"done"},
+			Paths: &plan.PathPolicy{Allowed: []string{"src/**"}, OnViolation: plan.PathViolationRevert},
+		},
+	})
+	planDir, root := createGitPlanRepo(t, p)
+
+	calls := 0
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		calls++
+		writeRepoFile(t, root, "src/app.go", "package app\n")
+		writeRepoFile(t, root, "README.md", "rewritten\n")
+		writeRepoFile(t, root, "notes/todo.txt", "later\n")
+		return nil
+	})
+
+	if err := New(planDir, p).WithRunner(runner).WithEvents(&mockEvents{}).Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected reverting to keep the attempt, got %d attempts", calls)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "README.md")); string(data) != "readme\n" {
+		t.Errorf("expected README.md to be restored, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "notes/todo.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected the new file outside the policy to be removed, got %v", err)
+	}
+	if files := committedFiles(t, root); strings.Contains(files, "README.md") || !strings.Contains(files, "src/app.go") {
+		t.Errorf("unexpected committed files:\n%s", files)
+	}
+}
+
+func TestExecutor_PathPolicyCoversSuccessHook(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{
+			ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending, AcceptanceCriteria: []string{"done"},
+			Paths: &plan.PathPolicy{Allowed: []string{"src/**"}, OnViolation: plan.PathViolationRevert},
+		},
+	})
+	planDir, root := createGitPlanRepo(t, p)
+
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		writeRepoFile(t, root, "src/app.go", "package app\n")
+		return nil
+	})
+	hooks := config.Hooks{AfterTaskSuccess: "echo generated > gen.txt"}
+
+	if err := New(planDir, p).WithRunner(runner).WithHooks(hooks).WithEvents(&mockEvents{}).Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "gen.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected the hook's file outside the policy to be removed, got %v", err)
+	}
+	if files := committedFiles(t, root); strings.Contains(files, "gen.txt") || !strings.Contains(files, "src/app.go") {
+		t.Errorf("unexpected committed files:\n%s", files)
+	}
+}
+
+func TestExecutor_FailedCommitSupersedesCompletion(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", Status: plan.TaskStatusPending, AcceptanceCriteria: []string{"done"}},
+	})
+	planDir, root := createGitPlanRepo(t, p)
+	writeRepoFile(t, root, ".git/hooks/pre-commit", "#!/bin/sh\nexit 1\n")
+	if err := os.Chmod(filepath.Join(root, ".git", "hooks", "pre-commit"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := funcRunner(func(ctx context.Context, task *plan.Task) error {
+		writeRepoFile(t, root, "src/app.go", "package app\n")
+		return nil
+	})
+	if err := New(planDir, p).WithRunner(runner).WithEvents(&mockEvents{}).Run(context.Background()); err == nil {
+		t.Fatal("expected the commit failure to fail the run")
+	}
+
+	events, err := plan.ReadProgressEvents(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commits := plan.TaskCommits(events); len(commits) != 0 {
+		t.Errorf("expected the failed commit not to be recorded, got %+v", commits)
+	}
+	summary := plan.SummarizeProgress(events)
+	if len(summary.Tasks) != 1 || summary.Tasks[0].Succeeded() || summary.Tasks[0].Attempts[0].Status != plan.AttemptStatusFailed {
+		t.Errorf("expected the task's last outcome to be a failure, got %+v", summary.Tasks)
+	}
+}
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pablasso/rafa/internal/git"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+)
+
+// PromptRunner is implemented by runners that run a prompt rendered by the
+// executor. The executor renders the task template with the whole data model
+// (plan, failure history, repo info) and the project's overrides; runners
+// that don't implement it get the task and build their own prompt.
+type PromptRunner interface {
+	RunPrompt(ctx context.Context, prompt string, output OutputWriter) error
+}
+
+// WithPrompts renders task prompts from s instead of the built-in templates.
+func (e *Executor) WithPrompts(s *prompt.Set) *Executor {
+	e.prompts = s
+	return e
+}
+
+// RenderPrompt renders the prompt the next attempt of a task would get, for
+// previewing templates. Nothing is run or recorded.
+func (e *Executor) RenderPrompt(taskID string) (string, error) {
+	for i := range e.plan.Tasks {
+		task := &e.plan.Tasks[i]
+		if task.ID != taskID {
+			continue
+		}
+		e.loadFailures()
+		attempt := min(task.Attempts+1, MaxAttempts)
+		return e.renderPrompt(task, i, attempt, e.buildPlanContext())
+	}
+	return "", fmt.Errorf("task %s not found", taskID)
+}
+
+// runAttempt runs the current attempt of task with the runner, applying
+// agent when the runner supports settings.
+func (e *Executor) runAttempt(ctx context.Context, task *plan.Task, idx int, planContext string, agent plan.AgentSettings, output *OutputCapture) error {
+	e.wantsReport = false
+	runner, ok := e.runner.(PromptRunner)
+	if !ok {
+		return e.runner.Run(ctx, task, planContext, task.Attempts, MaxAttempts, output)
+	}
+	text, err := e.renderPrompt(task, idx, task.Attempts, planContext)
+	if err != nil {
+		return err
+	}
+	// The built-in template asks for a completion report; an override
+	// may not.
+	e.wantsReport = strings.Contains(text, plan.CompletionMarker)
+	if sr, ok := e.runner.(SettingsRunner); ok {
+		return sr.RunPromptWithSettings(ctx, text, agent, output)
+	}
+	return runner.RunPrompt(ctx, text, output)
+}
+
+// renderPrompt renders the task template for an attempt of task.
+func (e *Executor) renderPrompt(task *plan.Task, idx, attempt int, planContext string) (string, error) {
+	prompts := e.prompts
+	if prompts == nil {
+		prompts = prompt.Default()
+	}
+	// The branch is informational, so a detached HEAD just leaves it empty.
+	branch, _ := git.CurrentBranch(e.repoRoot)
+	return prompts.RenderTask(prompt.TaskData{
+		Plan: prompt.PlanData{
+			ID:          e.plan.ID,
+			Name:        e.plan.Name,
+			Description: e.plan.Description,
+			SourceFile:  e.plan.SourceFile,
+		},
+		Task:          *task,
+		DesignSection: e.designSection(task),
+		TaskNumber:    idx + 1,
+		TaskCount:     len(e.plan.Tasks),
+		Attempt:       attempt,
+		MaxAttempts:   MaxAttempts,
+		Failures:      e.failures[task.ID],
+		Paths:         plan.ResolvePaths(&e.paths, e.plan.Paths, task.Paths),
+		PlanContext:   planContext,
+		Context:       e.gatherContext(task),
+		Repo:          prompt.RepoData{Root: e.repoRoot, Branch: branch},
+	})
+}
+
+// loadFailures seeds the failure history with the failed attempts of earlier
+// runs. progress.log doesn't keep errors, so only the attempt is known.
+func (e *Executor) loadFailures() {
+	e.failures = make(map[string][]prompt.Failure)
+	stats, err := plan.NewProgressReader(e.planDir).Stats()
+	if err != nil {
+		// The history only enriches the prompt; run without it.
+		return
+	}
+	for _, t := range stats.Tasks {
+		for _, a := range t.Attempts {
+			if a.Status == plan.AttemptStatusFailed {
+				e.failures[t.TaskID] = append(e.failures[t.TaskID], prompt.Failure{Attempt: a.Attempt})
+			}
+		}
+	}
+}
+
+// recordFailure adds a failed attempt of task to the failure history.
+func (e *Executor) recordFailure(task *plan.Task, err error) {
+	if e.failures == nil {
+		e.failures = make(map[string][]prompt.Failure)
+	}
+	e.failures[task.ID] = append(e.failures[task.ID], prompt.Failure{Attempt: task.Attempts, Error: err.Error()})
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+)
+
+// promptRunner records the prompts it is given and fails the first attempts.
+type promptRunner struct {
+	prompts []string
+	fails   int
+}
+
+func (r *promptRunner) Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error {
+	return errors.New("expected RunPrompt to be used")
+}
+
+func (r *promptRunner) RunPrompt(ctx context.Context, prompt string, output OutputWriter) error {
+	r.prompts = append(r.prompts, prompt)
+	if len(r.prompts) <= r.fails {
+		return errors.New("go test ./... failed")
+	}
+	return writeReport(output, doneReport)
+}
+
+func TestExecutor_RetryPromptIncludesFailures(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "t01", Title: "Task 1", Description: "Do it", Status: plan.TaskStatusPending},
+	})
+	planDir := createTestPlanDir(t, p)
+	runner := &promptRunner{fails: 1}
+
+	err := New(planDir, p).WithRunner(runner).WithEvents(&mockEvents{}).WithAllowDirty(true).Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(runner.prompts) != 2 {
+		t.Fatalf("expected 2 prompts, got %d", len(runner.prompts))
+	}
+	if strings.Contains(runner.prompts[0], "failed:") {
+		t.Errorf("first prompt should have no failure history:\n%s", runner.prompts[0])
+	}
+	if !strings.Contains(runner.prompts[1], "- Attempt 1 failed: go test ./... failed") {
+		t.Errorf("retry prompt should list the failure:\n%s", runner.prompts[1])
+	}
+}
+
+func TestExecutor_WithPromptsOverride(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "t01", Title: "Task 1", Status: plan.TaskStatusPending},
+		{ID: "t02", Title: "Task 2", Status: plan.TaskStatusPending},
+	})
+	planDir := createTestPlanDir(t, p)
+
+	rafaDir := t.TempDir()
+	tmpl := "{{.Plan.Name}} {{.TaskNumber}}/{{.TaskCount}} {{.Task.ID}} attempt {{.Attempt}}"
+	if err := os.MkdirAll(filepath.Join(rafaDir, prompt.DirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rafaDir, prompt.DirName, prompt.TaskTemplate), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	prompts, err := prompt.Load(rafaDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &promptRunner{}
+	err = New(planDir, p).WithRunner(runner).WithPrompts(prompts).WithEvents(&mockEvents{}).WithAllowDirty(true).Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := []string{"Test Plan 1/2 t01 attempt 1", "Test Plan 2/2 t02 attempt 1"}
+	if strings.Join(runner.prompts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got prompts %q, want %q", runner.prompts, want)
+	}
+}
+
+func TestExecutor_RenderPrompt(t *testing.T) {
+	p := createTestPlan([]plan.Task{
+		{ID: "t01", Title: "Task 1", Description: "Do it", Status: plan.TaskStatusPending, Attempts: 2},
+	})
+	planDir := createTestPlanDir(t, p)
+
+	text, err := New(planDir, p).RenderPrompt("t01")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !strings.Contains(text, "**Attempt**: 3 of 5") || !strings.Contains(text, "Plan: Test Plan") {
+		t.Errorf("unexpected prompt:\n%s", text)
+	}
+
+	if _, err := New(planDir, p).RenderPrompt("t99"); err == nil {
+		t.Error("expected error for unknown task")
+	}
+}
@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pablasso/rafa/internal/ai"
+)
+
+// redactingWriter redacts secrets from agent output before passing it on.
+// It works a line at a time, so a secret split across writes is still
+// caught; Flush writes any unterminated last line.
+type redactingWriter struct {
+	w        io.Writer
+	redactor *ai.Redactor
+	buf      []byte
+}
+
+func newRedactingWriter(w io.Writer, redactor *ai.Redactor) *redactingWriter {
+	return &redactingWriter{w: w, redactor: redactor}
+}
+
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	rw.buf = append(rw.buf, p...)
+	idx := bytes.LastIndexByte(rw.buf, '\n')
+	if idx < 0 {
+		return len(p), nil
+	}
+	lines := rw.redactor.Redact(string(rw.buf[:idx+1]))
+	rw.buf = append(rw.buf[:0], rw.buf[idx+1:]...)
+	if _, err := io.WriteString(rw.w, lines); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered partial line.
+func (rw *redactingWriter) Flush() error {
+	if len(rw.buf) == 0 {
+		return nil
+	}
+	rest := rw.redactor.Redact(string(rw.buf))
+	rw.buf = rw.buf[:0]
+	_, err := io.WriteString(rw.w, rest)
+	return err
+}
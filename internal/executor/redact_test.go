@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/ai"
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+func TestRedactingWriter_SecretSplitAcrossWrites(t *testing.T) {
+	var out strings.Builder
+	w := newRedactingWriter(&out, ai.NewRedactor([]string{"hunter2hunter2"}))
+
+	for _, chunk := range []string{"pass=hunter2", "hunter2\nnext ", "hunter2hun", "ter2"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := out.String(); got != "pass=[REDACTED]\n" {
+		t.Errorf("expected complete lines only before Flush, got %q", got)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "pass=[REDACTED]\nnext [REDACTED]" {
+		t.Errorf("unexpected output after Flush: %q", got)
+	}
+}
+
+func TestClaudeRunner_RedactsSecretsFromOutput(t *testing.T) {
+	runtime := writeFakeRuntime(t)
+	planDir := t.TempDir()
+	output, err := NewOutputCapture(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+	output.WriteTaskHeader("t01", 1)
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("API_KEY=sk-from-dotenv\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runner := NewClaudeRunner().
+		WithContainer(config.Container{Image: "agent", Runtime: runtime, User: "1:1"}).
+		WithEnv(ai.EnvPolicy{Files: []string{envFile}})
+	if err := runner.RunPromptWithSettings(context.Background(), "do it", plan.AgentSettings{}, output); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The .env variable reaches the agent by name, and its value is
+	// redacted from what the agent prints.
+	calls := readRuntimeCalls(t, runtime)
+	if len(calls) != 1 || !strings.Contains(calls[0], "-e API_KEY") {
+		t.Fatalf("expected API_KEY to be passed into the container, got %v", calls)
+	}
+	data, err := os.ReadFile(filepath.Join(planDir, output.AttemptFile()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-from-dotenv") || !strings.Contains(string(data), "key=[REDACTED]") {
+		t.Errorf("expected the secret to be redacted, got %q", data)
+	}
+}
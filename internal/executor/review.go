@@ -0,0 +1,158 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pablasso/rafa/internal/ai"
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/git"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+)
+
+// Reviewer reviews a successful attempt's changes before they are
+// committed. The executor renders the review prompt; the reviewer runs it
+// in a session of its own and returns the verdict.
+type Reviewer interface {
+	Review(ctx context.Context, prompt string, output OutputWriter) (*plan.Review, error)
+}
+
+// maxReviewDiffBytes caps the diff included in the review prompt. The
+// reviewer can read the files for the rest.
+const maxReviewDiffBytes = 200 * 1024
+
+// reviewTools are the tools the Claude reviewer may use: it reads, it
+// doesn't edit.
+const reviewTools = "Read,Grep,Glob,Bash(git diff:*),Bash(git log:*),Bash(git show:*),Bash(git status:*)"
+
+// WithReviewer has r review every successful attempt before it is
+// committed. A rejection fails the attempt.
+func (e *Executor) WithReviewer(r Reviewer) *Executor {
+	e.reviewer = r
+	return e
+}
+
+// reviewAttempt has the reviewer judge the current attempt's changes and
+// stores the verdict on task. A rejection fails the attempt with the
+// reviewer's reasons, which the next attempt's prompt lists.
+func (e *Executor) reviewAttempt(ctx context.Context, task *plan.Task, report *plan.CompletionReport, output *OutputCapture) error {
+	task.Review = nil
+	diff, err := git.Diff(e.repoRoot, ".rafa")
+	if err != nil {
+		return fmt.Errorf("failed to diff changes for review: %w", err)
+	}
+	prompts := e.prompts
+	if prompts == nil {
+		prompts = prompt.Default()
+	}
+	text, err := prompts.RenderReview(prompt.ReviewData{
+		Plan: prompt.PlanData{
+			ID:          e.plan.ID,
+			Name:        e.plan.Name,
+			Description: e.plan.Description,
+			SourceFile:  e.plan.SourceFile,
+		},
+		Task:          *task,
+		DesignSection: e.designSection(task),
+		Attempt:       task.Attempts,
+		Report:        report,
+		Diff:          truncate(strings.TrimRight(diff, "\n"), maxReviewDiffBytes),
+		Repo:          prompt.RepoData{Root: e.repoRoot},
+	})
+	if err != nil {
+		return err
+	}
+
+	reviewEvents, _ := e.events.(ReviewEvents)
+	if reviewEvents != nil {
+		reviewEvents.OnReviewStart(task, task.Attempts)
+	}
+	var w OutputWriter
+	if output != nil {
+		output.WriteReviewHeader()
+		w = output
+	}
+	review, err := e.reviewer.Review(ctx, text, w)
+	if reviewEvents != nil {
+		reviewEvents.OnReviewComplete(task, task.Attempts, review, err)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("review failed: %w", err)
+	}
+
+	task.Review = review
+	if review.Approved() {
+		return nil
+	}
+	if len(review.Reasons) == 0 {
+		return fmt.Errorf("reviewer rejected the changes")
+	}
+	return fmt.Errorf("reviewer rejected the changes: %s", strings.Join(review.Reasons, "; "))
+}
+
+// ClaudeReviewer reviews changes via Claude Code CLI, restricted to
+// read-only tools.
+type ClaudeReviewer struct {
+	env       ai.EnvPolicy
+	container *config.Container // nil runs the reviewer on the host
+}
+
+// NewClaudeReviewer creates a new ClaudeReviewer.
+func NewClaudeReviewer() *ClaudeReviewer {
+	return &ClaudeReviewer{}
+}
+
+// WithEnv sets the reviewer's environment policy, which also redacts
+// secrets from its output.
+func (r *ClaudeReviewer) WithEnv(p ai.EnvPolicy) *ClaudeReviewer {
+	r.env = p
+	return r
+}
+
+// WithContainer runs the reviewer inside the container task agents use
+// instead of on the host.
+func (r *ClaudeReviewer) WithContainer(c config.Container) *ClaudeReviewer {
+	r.container = &c
+	return r
+}
+
+// Review implements Reviewer.
+func (r *ClaudeReviewer) Review(ctx context.Context, prompt string, output OutputWriter) (*plan.Review, error) {
+	args := []string{
+		"-p", prompt,
+		"--allowedTools", reviewTools,
+		"--output-format", "stream-json",
+		"--verbose",
+		"--include-partial-messages",
+	}
+
+	// Keep a copy of the stream to read the verdict from.
+	var stream bytes.Buffer
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	if output != nil {
+		stdout, stderr = output.Stdout(), output.Stderr()
+	}
+	if err := runClaude(ctx, args, r.env, nil, r.container, io.MultiWriter(stdout, &stream), stderr); err != nil {
+		return nil, err
+	}
+	return parseReviewStream(&stream)
+}
+
+// parseReviewStream reads the reviewer's verdict from its stream-json
+// output.
+func parseReviewStream(r io.Reader) (*plan.Review, error) {
+	for _, text := range agentTexts(r) {
+		if strings.Contains(text, plan.ReviewMarker) {
+			return plan.ParseReview(text)
+		}
+	}
+	return plan.ParseReview("")
+}
@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/ai"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// fakeReviewer returns its verdicts in order and records the prompts it is
+// given.
+type fakeReviewer struct {
+	verdicts []*plan.Review
+	prompts  []string
+}
+
+func (r *fakeReviewer) Review(ctx context.Context, prompt string, output OutputWriter) (*plan.Review, error) {
+	if len(r.prompts) >= len(r.verdicts) {
+		return nil, fmt.Errorf("unexpected review")
+	}
+	r.prompts = append(r.prompts, prompt)
+	return r.verdicts[len(r.prompts)-1], nil
+}
+
+// reviewEvents records review events on top of mockEvents.
+type reviewEvents struct {
+	mockEvents
+	reviews []string
+}
+
+func (e *reviewEvents) OnReviewStart(task *plan.Task, attempt int) {
+	e.reviews = append(e.reviews, fmt.Sprintf("start %s %d", task.ID, attempt))
+}
+
+func (e *reviewEvents) OnReviewComplete(task *plan.Task, attempt int, review *plan.Review, err error) {
+	e.reviews = append(e.reviews, fmt.Sprintf("complete %s %d %s", task.ID, attempt, review.Verdict))
+}
+
+func TestExecutor_ReviewRejectionFailsAttempt(t *testing.T) {
+	repoRoot, planDir := setupTestGitRepo(t)
+	p := createTestPlan([]plan.Task{
+		{ID: "task-1", Title: "Task 1", AcceptanceCriteria: []string{"Has tests"}, Status: plan.TaskStatusPending},
+	})
+	if err := plan.SavePlan(planDir, p); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sh", "-c", "git add -A && git commit -m 'add plan'")
+	cmd.Dir = repoRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to commit plan: %v\n%s", err, out)
+	}
+
+	runner := &fileWritingRunner{repoRoot: repoRoot}
+	reviewer := &fakeReviewer{verdicts: []*plan.Review{
+		{Verdict: plan.ReviewReject, Reasons: []string{"No tests", "Unused import"}},
+		{Verdict: plan.ReviewApprove},
+	}}
+	events := &reviewEvents{}
+	if err := New(planDir, p).WithRunner(runner).WithReviewer(reviewer).WithEvents(events).Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(runner.prompts) != 2 || len(reviewer.prompts) != 2 {
+		t.Fatalf("expected 2 attempts and 2 reviews, got %d and %d", len(runner.prompts), len(reviewer.prompts))
+	}
+	for _, want := range []string{"**ID**: task-1", "1. Has tests", "+++ b/impl-1.go"} {
+		if !strings.Contains(reviewer.prompts[0], want) {
+			t.Errorf("review prompt missing %q:\n%s", want, reviewer.prompts[0])
+		}
+	}
+	if strings.Contains(reviewer.prompts[0], "progress.log") {
+		t.Errorf("review diff should leave out .rafa:\n%s", reviewer.prompts[0])
+	}
+	if want := "- Attempt 1 failed: reviewer rejected the changes: No tests; Unused import"; !strings.Contains(runner.prompts[1], want) {
+		t.Errorf("retry prompt missing %q:\n%s", want, runner.prompts[1])
+	}
+
+	wantEvents := []string{"start task-1 1", "complete task-1 1 reject", "start task-1 2", "complete task-1 2 approve"}
+	if fmt.Sprint(events.reviews) != fmt.Sprint(wantEvents) {
+		t.Errorf("expected review events %v, got %v", wantEvents, events.reviews)
+	}
+	if len(events.taskFails) != 1 || len(events.taskCompletes) != 1 {
+		t.Errorf("expected one failed and one completed attempt, got %d and %d", len(events.taskFails), len(events.taskCompletes))
+	}
+
+	saved, err := plan.LoadPlan(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := saved.Tasks[0].Review; r == nil || !r.Approved() {
+		t.Errorf("expected the approving review on the task, got %+v", r)
+	}
+}
+
+func TestParseReviewStream(t *testing.T) {
+	stream := `{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"RAFA_REVIEW {\"verdict\": "}}}
+{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"\"reject\", \"reasons\": [\"Off by one\"]}"}}}
+`
+	review, err := parseReviewStream(strings.NewReader(stream))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if review.Approved() || len(review.Reasons) != 1 || review.Reasons[0] != "Off by one" {
+		t.Errorf("unexpected review %+v", review)
+	}
+
+	if _, err := parseReviewStream(strings.NewReader(`{"type":"result","result":"Looks fine"}` + "\n")); err == nil {
+		t.Error("expected an error without a verdict")
+	}
+}
+
+// fakeClaudeReviewer is a stand-in for the claude CLI that prints the
+// variables it got and approves.
+const fakeClaudeReviewer = `#!/bin/sh
+echo "{\"type\":\"result\",\"result\":\"aws=$AWS_SECRET_ACCESS_KEY key=$API_KEY RAFA_REVIEW {\\\"verdict\\\": \\\"approve\\\"}\"}"
+`
+
+func TestClaudeReviewer_EnvPolicyAndRedaction(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte(fakeClaudeReviewer), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret-value")
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("API_KEY=sk-from-dotenv\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	planDir := t.TempDir()
+	output, err := NewOutputCapture(planDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+	output.WriteTaskHeader("t01", 1)
+
+	review, err := NewClaudeReviewer().WithEnv(ai.EnvPolicy{Files: []string{envFile}}).Review(context.Background(), "review it", output)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !review.Approved() {
+		t.Errorf("expected an approval, got %+v", review)
+	}
+
+	// The denied host variable is kept from the reviewer, and the .env
+	// secret it gets is redacted from its output.
+	data, err := os.ReadFile(filepath.Join(planDir, output.AttemptFile()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "aws= key=[REDACTED]") {
+		t.Errorf("expected filtered and redacted reviewer output, got %q", data)
+	}
+}
@@ -3,92 +3,149 @@ package executor
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"os/exec"
+	"strconv"
 
 	"github.com/pablasso/rafa/internal/ai"
+	"github.com/pablasso/rafa/internal/config"
 	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
 )
 
 // ClaudeRunner executes tasks via Claude Code CLI.
-type ClaudeRunner struct{}
+type ClaudeRunner struct {
+	permissions ai.Permissions
+	container   *config.Container // nil runs the agent on the host
+	env         ai.EnvPolicy
+}
 
 // NewClaudeRunner creates a new ClaudeRunner.
 func NewClaudeRunner() *ClaudeRunner {
 	return &ClaudeRunner{}
 }
 
+// WithPermissions runs the agent under p instead of skipping permission
+// checks.
+func (r *ClaudeRunner) WithPermissions(p ai.Permissions) *ClaudeRunner {
+	r.permissions = p
+	return r
+}
+
+// WithEnv sets the agent's environment policy.
+func (r *ClaudeRunner) WithEnv(p ai.EnvPolicy) *ClaudeRunner {
+	r.env = p
+	return r
+}
+
+// Backend implements BackendNamer.
+func (r *ClaudeRunner) Backend() string {
+	return "claude"
+}
+
 // Run executes a single task via Claude Code CLI.
 func (r *ClaudeRunner) Run(ctx context.Context, task *plan.Task, planContext string, attempt, maxAttempts int, output OutputWriter) error {
-	prompt := r.buildPrompt(task, planContext, attempt, maxAttempts)
+	text, err := r.buildPrompt(task, planContext, attempt, maxAttempts)
+	if err != nil {
+		return err
+	}
+	return r.RunPrompt(ctx, text, output)
+}
 
-	cmd := ai.CommandContext(ctx, "claude",
-		"-p", prompt,
-		"--dangerously-skip-permissions",
-		"--output-format", "stream-json",
-		"--verbose",
-		"--include-partial-messages",
-	)
+// RunPrompt implements PromptRunner.
+func (r *ClaudeRunner) RunPrompt(ctx context.Context, prompt string, output OutputWriter) error {
+	return r.RunPromptWithSettings(ctx, prompt, plan.AgentSettings{}, output)
+}
 
+// RunPromptWithSettings implements SettingsRunner.
+func (r *ClaudeRunner) RunPromptWithSettings(ctx context.Context, prompt string, agent plan.AgentSettings, output OutputWriter) error {
 	// Use OutputWriter if provided, otherwise fall back to os.Stdout/os.Stderr
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
 	if output != nil {
-		cmd.Stdout = output.Stdout()
-		cmd.Stderr = output.Stderr()
-	} else {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		stdout, stderr = output.Stdout(), output.Stderr()
 	}
+	return runClaude(ctx, claudeArgs(prompt, agent, r.permissions), r.env, agent.Env, r.container, stdout, stderr)
+}
 
-	err := cmd.Run()
+// runClaude runs the Claude CLI with args in the environment policy allows,
+// plus extraEnv, inside container when it isn't nil. Secrets are redacted
+// from the output before it reaches stdout and stderr. Task agents and the
+// reviewer share it, so both are isolated the same way.
+func runClaude(ctx context.Context, args []string, policy ai.EnvPolicy, extraEnv map[string]string, container *config.Container, stdout, stderr io.Writer) error {
+	env, injected, err := policy.Environ(extraEnv)
+	if err != nil {
+		return fmt.Errorf("failed to load agent environment: %w", err)
+	}
+
+	var cmd *exec.Cmd
+	var name string
+	if container != nil {
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		if name, err = newContainerName(); err != nil {
+			return err
+		}
+		cmd = ai.CommandContext(ctx, containerRuntime(*container),
+			containerRunArgs(*container, name, dir, injected, args)...)
+	} else {
+		cmd = ai.CommandContext(ctx, "claude", args...)
+	}
+	cmd.Env = env
+
+	// Secrets are redacted before the output is logged or displayed.
+	redactor := policy.Redactor(env)
+	redactOut := newRedactingWriter(stdout, redactor)
+	redactErr := newRedactingWriter(stderr, redactor)
+	cmd.Stdout = redactOut
+	cmd.Stderr = redactErr
+
+	err = cmd.Run()
+	redactOut.Flush()
+	redactErr.Flush()
 	if err != nil {
 		if ctx.Err() != nil {
+			if name != "" {
+				removeContainer(*container, name)
+			}
 			return ctx.Err()
 		}
+		if name != "" {
+			return fmt.Errorf("%s run exited with error: %w", containerRuntime(*container), err)
+		}
 		return fmt.Errorf("claude exited with error: %w", err)
 	}
 
 	return nil
 }
 
-// buildPrompt constructs the prompt for Claude CLI.
-func (r *ClaudeRunner) buildPrompt(task *plan.Task, planContext string, attempt, maxAttempts int) string {
-	var sb strings.Builder
-
-	sb.WriteString("You are executing a task as part of an automated plan.\n\n")
-	sb.WriteString("## Context\n")
-	sb.WriteString(planContext)
-	sb.WriteString("\n")
-
-	sb.WriteString("## Your Task\n")
-	sb.WriteString(fmt.Sprintf("**ID**: %s\n", task.ID))
-	sb.WriteString(fmt.Sprintf("**Title**: %s\n", task.Title))
-	sb.WriteString(fmt.Sprintf("**Attempt**: %d of %d\n", attempt, maxAttempts))
-	sb.WriteString(fmt.Sprintf("**Description**: %s\n\n", task.Description))
-
-	// Add retry note if not first attempt
-	if attempt > 1 {
-		sb.WriteString("**Note**: Previous attempts to complete this task failed. ")
-		sb.WriteString("Consider alternative approaches or investigate what went wrong. ")
-		sb.WriteString("Review any uncommitted changes from previous attempts - you may be able to continue from where they left off. ")
-		sb.WriteString("Use `git status` and `git diff` to see what was changed.\n\n")
+// claudeArgs returns the Claude CLI arguments for running prompt with agent
+// under perms.
+func claudeArgs(prompt string, agent plan.AgentSettings, perms ai.Permissions) []string {
+	args := []string{"-p", prompt}
+	args = append(args, perms.Args(agent.AllowedTools, agent.DisallowedTools)...)
+	args = append(args,
+		"--output-format", "stream-json",
+		"--verbose",
+		"--include-partial-messages",
+	)
+	if agent.Model != "" {
+		args = append(args, "--model", agent.Model)
 	}
-
-	sb.WriteString("## Acceptance Criteria\n")
-	sb.WriteString("You MUST verify ALL of the following before considering the task complete:\n")
-	for i, criterion := range task.AcceptanceCriteria {
-		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, criterion))
+	if agent.MaxTurns > 0 {
+		args = append(args, "--max-turns", strconv.Itoa(agent.MaxTurns))
 	}
-	sb.WriteString("\n")
-
-	sb.WriteString("## Instructions\n")
-	sb.WriteString("1. Implement the task as described\n")
-	sb.WriteString("2. Verify ALL acceptance criteria are met\n")
-	sb.WriteString("3. If you need additional context on requirements or implementation details, consult the Source document listed in the Context section above\n")
-	sb.WriteString("4. Before finalizing, perform a code review of your changes. If you have a code review skill available (e.g., `/code-review`), use it to review your implementation and assess what findings are worth addressing vs. acceptable trade-offs\n")
-	sb.WriteString("5. DO NOT commit your changes - the orchestrator will handle the commit\n")
-	sb.WriteString("6. Output a suggested commit message in this exact format: SUGGESTED_COMMIT_MESSAGE: <your descriptive commit message>\n\n")
-
-	sb.WriteString("IMPORTANT: Leave changes uncommitted. The orchestrator will commit after validating. Do not declare success unless ALL acceptance criteria are met.\n")
+	return append(args, agent.ExtraArgs...)
+}
 
-	return sb.String()
+// buildPrompt renders the default task template for Claude CLI.
+func (r *ClaudeRunner) buildPrompt(task *plan.Task, planContext string, attempt, maxAttempts int) (string, error) {
+	return prompt.Default().RenderTask(prompt.TaskData{
+		Task:        *task,
+		Attempt:     attempt,
+		MaxAttempts: maxAttempts,
+		PlanContext: planContext,
+	})
 }
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -11,6 +12,15 @@ import (
 	"github.com/pablasso/rafa/internal/testutil"
 )
 
+func buildTestPrompt(t *testing.T, runner *ClaudeRunner, task *plan.Task, planContext string, attempt, maxAttempts int) string {
+	t.Helper()
+	prompt, err := runner.buildPrompt(task, planContext, attempt, maxAttempts)
+	if err != nil {
+		t.Fatalf("failed to build prompt: %v", err)
+	}
+	return prompt
+}
+
 func TestClaudeRunner_BuildPrompt(t *testing.T) {
 	runner := NewClaudeRunner()
 	task := &plan.Task{
@@ -24,7 +34,7 @@ func TestClaudeRunner_BuildPrompt(t *testing.T) {
 	}
 	planContext := "This is the plan context."
 
-	prompt := runner.buildPrompt(task, planContext, 1, 3)
+	prompt := buildTestPrompt(t, runner, task, planContext, 1, 3)
 
 	// Verify prompt includes task ID
 	if !strings.Contains(prompt, "t01") {
@@ -67,7 +77,7 @@ func TestClaudeRunner_PromptIncludesAllCriteria(t *testing.T) {
 		AcceptanceCriteria: criteria,
 	}
 
-	prompt := runner.buildPrompt(task, "", 1, 1)
+	prompt := buildTestPrompt(t, runner, task, "", 1, 1)
 
 	for _, criterion := range criteria {
 		if !strings.Contains(prompt, criterion) {
@@ -97,7 +107,7 @@ func TestClaudeRunner_PromptIncludesAttemptNumber(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		prompt := runner.buildPrompt(task, "", tt.attempt, tt.maxAttempts)
+		prompt := buildTestPrompt(t, runner, task, "", tt.attempt, tt.maxAttempts)
 		if !strings.Contains(prompt, tt.want) {
 			t.Errorf("attempt %d of %d: prompt should include %q", tt.attempt, tt.maxAttempts, tt.want)
 		}
@@ -114,13 +124,13 @@ func TestClaudeRunner_PromptIncludesRetryNote(t *testing.T) {
 	}
 
 	// Attempt 2 should include retry note
-	prompt := runner.buildPrompt(task, "", 2, 3)
+	prompt := buildTestPrompt(t, runner, task, "", 2, 3)
 	if !strings.Contains(prompt, "Previous attempts") {
 		t.Error("prompt for attempt > 1 should include retry note")
 	}
 
 	// Attempt 3 should also include retry note
-	prompt = runner.buildPrompt(task, "", 3, 3)
+	prompt = buildTestPrompt(t, runner, task, "", 3, 3)
 	if !strings.Contains(prompt, "Previous attempts") {
 		t.Error("prompt for attempt > 1 should include retry note")
 	}
@@ -135,7 +145,7 @@ func TestClaudeRunner_PromptNoRetryNoteOnFirstAttempt(t *testing.T) {
 		AcceptanceCriteria: []string{"Criterion 1"},
 	}
 
-	prompt := runner.buildPrompt(task, "", 1, 3)
+	prompt := buildTestPrompt(t, runner, task, "", 1, 3)
 	if strings.Contains(prompt, "Previous attempts") {
 		t.Error("prompt for first attempt should not include retry note")
 	}
@@ -244,16 +254,16 @@ func TestClaudeRunner_PromptIncludesDoNotCommitInstruction(t *testing.T) {
 		AcceptanceCriteria: []string{"Criterion 1"},
 	}
 
-	prompt := runner.buildPrompt(task, "", 1, 3)
+	prompt := buildTestPrompt(t, runner, task, "", 1, 3)
 
 	// Verify prompt includes "DO NOT commit" instruction
 	if !strings.Contains(prompt, "DO NOT commit") {
 		t.Error("prompt should include 'DO NOT commit' instruction")
 	}
 
-	// Verify prompt includes instruction for suggested commit message
-	if !strings.Contains(prompt, "SUGGESTED_COMMIT_MESSAGE") {
-		t.Error("prompt should include 'SUGGESTED_COMMIT_MESSAGE' instruction")
+	// Verify prompt asks for a completion report
+	if !strings.Contains(prompt, plan.CompletionMarker) {
+		t.Errorf("prompt should include the %s instruction", plan.CompletionMarker)
 	}
 
 	// Verify prompt includes note about orchestrator handling commit
@@ -277,7 +287,7 @@ func TestClaudeRunner_PromptRetryNoteIncludesUncommittedChanges(t *testing.T) {
 	}
 
 	// Test retry attempt includes note about uncommitted changes
-	prompt := runner.buildPrompt(task, "", 2, 3)
+	prompt := buildTestPrompt(t, runner, task, "", 2, 3)
 
 	if !strings.Contains(prompt, "uncommitted changes from previous attempts") {
 		t.Error("retry prompt should include note about reviewing uncommitted changes from previous attempts")
@@ -287,3 +297,49 @@ func TestClaudeRunner_PromptRetryNoteIncludesUncommittedChanges(t *testing.T) {
 		t.Error("retry prompt should include suggestion to use git status")
 	}
 }
+
+func TestClaudeArgs(t *testing.T) {
+	stream := []string{"--output-format", "stream-json", "--verbose", "--include-partial-messages"}
+	base := append([]string{"-p", "do it", "--dangerously-skip-permissions"}, stream...)
+	if got := claudeArgs("do it", plan.AgentSettings{}, ai.Permissions{}); !reflect.DeepEqual(got, base) {
+		t.Errorf("claudeArgs() with defaults = %v, want %v", got, base)
+	}
+
+	agent := plan.AgentSettings{
+		Model:           "opus",
+		MaxTurns:        25,
+		AllowedTools:    []string{"WebFetch"},
+		DisallowedTools: []string{"Bash(git push:*)"},
+		ExtraArgs:       []string{"--append-system-prompt", "Be brief."},
+	}
+	got := claudeArgs("do it", agent, ai.Permissions{})
+	want := append([]string{"-p", "do it", "--dangerously-skip-permissions",
+		"--allowedTools", "WebFetch",
+		"--disallowedTools", "Bash(git push:*)",
+	}, stream...)
+	want = append(want,
+		"--model", "opus",
+		"--max-turns", "25",
+		"--append-system-prompt", "Be brief.",
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("claudeArgs() = %v, want %v", got, want)
+	}
+
+	// A restricted policy replaces skipping permissions, and the task's
+	// tools are added to it.
+	perms := ai.Permissions{Mode: ai.PermissionsRestricted, Tools: []string{"Read", "Edit"}, Bash: []string{"go test:*"}, Deny: []string{"WebSearch"}}
+	got = claudeArgs("do it", agent, perms)
+	want = append([]string{"-p", "do it",
+		"--allowedTools", "Read(./**),Edit(./**),Bash(go test:*),WebFetch",
+		"--disallowedTools", "WebSearch,Bash(git push:*)",
+	}, stream...)
+	want = append(want,
+		"--model", "opus",
+		"--max-turns", "25",
+		"--append-system-prompt", "Be brief.",
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("claudeArgs() restricted = %v, want %v", got, want)
+	}
+}
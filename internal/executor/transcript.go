@@ -0,0 +1,193 @@
+package executor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// TranscriptEntryKind identifies the kind of a transcript entry.
+type TranscriptEntryKind string
+
+const (
+	TranscriptText       TranscriptEntryKind = "text"
+	TranscriptToolUse    TranscriptEntryKind = "tool_use"
+	TranscriptToolResult TranscriptEntryKind = "tool_result"
+	TranscriptUsage      TranscriptEntryKind = "usage"
+)
+
+// Transcript attempt outcomes, taken from the attempt's footer line.
+const (
+	TranscriptSucceeded  = "succeeded"
+	TranscriptFailed     = "failed"
+	TranscriptIncomplete = "incomplete" // No footer: still running or interrupted
+)
+
+// TranscriptEntry is one readable event of an agent session: a block of
+// assistant text, a tool call, a tool result or a usage summary.
+type TranscriptEntry struct {
+	Kind         TranscriptEntryKind
+	Text         string // Assistant text, or tool result output
+	ToolID       string
+	ToolName     string // Set on tool calls and, when known, on their results
+	ToolTarget   string
+	IsError      bool // Tool result reported an error
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// TranscriptAttempt is the transcript of a single task attempt.
+type TranscriptAttempt struct {
+	TaskID    string
+	Attempt   int
+	StartedAt time.Time
+	Outcome   string
+	Entries   []TranscriptEntry
+}
+
+var (
+	transcriptHeaderPattern  = regexp.MustCompile(`^=== Task ([^,]+), Attempt ([0-9]+) ===$`)
+	transcriptFooterPattern  = regexp.MustCompile(`^=== Task ([^:]+): (SUCCESS|FAILED) ===$`)
+	transcriptStartedPattern = regexp.MustCompile(`^Started: (\S+)$`)
+)
+
+// LoadTranscript reads a plan's captured output (see plan.OpenOutputLog) and
+// parses it into per-attempt transcripts. A plan without output yields an
+// empty list.
+func LoadTranscript(planDir string) ([]TranscriptAttempt, error) {
+	attempts, err := plan.ListAttemptOutputs(planDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress log: %w", err)
+	}
+	return ReadTranscript(planDir, attempts)
+}
+
+// ReadTranscript is LoadTranscript for callers that have already listed the
+// plan's attempts (see plan.AttemptOutputs), so progress.log isn't read again.
+func ReadTranscript(planDir string, attempts []plan.AttemptOutput) ([]TranscriptAttempt, error) {
+	r, err := plan.OpenOutputs(planDir, attempts)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer r.Close()
+	return ParseTranscript(r)
+}
+
+// ParseTranscript parses output in the output.log format (task headers and
+// footers around raw stream-json lines) into per-attempt transcripts, in the
+// order the attempts appear. Lines outside an attempt are ignored.
+func ParseTranscript(r io.Reader) ([]TranscriptAttempt, error) {
+	var attempts []TranscriptAttempt
+	var current *TranscriptAttempt
+	var text strings.Builder
+	toolNames := make(map[string]string)
+
+	flushText := func() {
+		if current == nil || text.Len() == 0 {
+			text.Reset()
+			return
+		}
+		if trimmed := strings.Trim(text.String(), "\n"); strings.TrimSpace(trimmed) != "" {
+			current.Entries = append(current.Entries, TranscriptEntry{Kind: TranscriptText, Text: trimmed})
+		}
+		text.Reset()
+	}
+	finish := func() {
+		if current == nil {
+			return
+		}
+		flushText()
+		attempts = append(attempts, *current)
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := transcriptHeaderPattern.FindStringSubmatch(line); m != nil {
+			finish()
+			attempt, _ := strconv.Atoi(m[2])
+			current = &TranscriptAttempt{TaskID: m[1], Attempt: attempt, Outcome: TranscriptIncomplete}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if m := transcriptFooterPattern.FindStringSubmatch(line); m != nil && m[1] == current.TaskID {
+			current.Outcome = TranscriptFailed
+			if m[2] == "SUCCESS" {
+				current.Outcome = TranscriptSucceeded
+			}
+			finish()
+			continue
+		}
+		if current.StartedAt.IsZero() && len(current.Entries) == 0 && text.Len() == 0 {
+			if m := transcriptStartedPattern.FindStringSubmatch(line); m != nil {
+				if started, err := time.Parse(time.RFC3339, m[1]); err == nil {
+					current.StartedAt = started
+					continue
+				}
+			}
+		}
+
+		parsed := parseStreamLineDetails(line)
+		if parsed.Text != "" {
+			text.WriteString(parsed.Text)
+			if !strings.HasSuffix(parsed.Text, "\n") && parsed.Flush {
+				text.WriteString("\n")
+			}
+		}
+		if parsed.Flush || parsed.ToolUse != nil {
+			flushText()
+		}
+		// Streaming and the final assistant message both report a tool
+		// call; keep the first.
+		if tu := parsed.ToolUse; tu != nil && (tu.ID == "" || toolNames[tu.ID] == "") {
+			if tu.ID != "" {
+				toolNames[tu.ID] = tu.Name
+			}
+			current.Entries = append(current.Entries, TranscriptEntry{
+				Kind:       TranscriptToolUse,
+				ToolID:     tu.ID,
+				ToolName:   tu.Name,
+				ToolTarget: tu.Target,
+			})
+		}
+		if tr := parsed.ToolResult; tr != nil {
+			current.Entries = append(current.Entries, TranscriptEntry{
+				Kind:     TranscriptToolResult,
+				Text:     tr.Output,
+				ToolID:   tr.ToolID,
+				ToolName: toolNames[tr.ToolID],
+				IsError:  tr.IsError,
+			})
+		}
+		if u := parsed.Usage; u != nil {
+			current.Entries = append(current.Entries, TranscriptEntry{
+				Kind:         TranscriptUsage,
+				InputTokens:  u.InputTokens,
+				OutputTokens: u.OutputTokens,
+				CostUSD:      u.CostUSD,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	finish()
+	return attempts, nil
+}
@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const transcriptFixture = `
+=== Task t01, Attempt 1 ===
+Started: 2024-01-15T10:00:00Z
+
+{"type":"system","subtype":"init"}
+{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"Let me look "}}}
+{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"at the code."}}}
+{"type":"stream_event","event":{"type":"content_block_stop"}}
+{"type":"stream_event","event":{"type":"content_block_start","content_block":{"id":"tool_1","type":"tool_use","name":"Read","input":{"file_path":"main.go"}}}}
+{"type":"assistant","message":{"content":[{"id":"tool_1","type":"tool_use","name":"Read","input":{"file_path":"main.go"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"tool_1","content":"package main"}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"tool_2","is_error":true,"content":[{"type":"text","text":"boom"}]}]}}
+{"type":"result","subtype":"success","usage":{"input_tokens":100,"output_tokens":20},"total_cost_usd":0.5}
+
+=== Task t01: FAILED ===
+
+
+=== Task t01, Attempt 2 ===
+Started: 2024-01-15T10:05:00Z
+
+plain stderr line
+
+=== Task t01: SUCCESS ===
+
+
+=== Task t02, Attempt 1 ===
+Started: 2024-01-15T10:10:00Z
+
+`
+
+func TestParseTranscript(t *testing.T) {
+	attempts, err := ParseTranscript(strings.NewReader(transcriptFixture))
+	if err != nil {
+		t.Fatalf("ParseTranscript() error: %v", err)
+	}
+	if len(attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(attempts))
+	}
+
+	first := attempts[0]
+	if first.TaskID != "t01" || first.Attempt != 1 || first.Outcome != TranscriptFailed {
+		t.Errorf("first attempt = %+v", first)
+	}
+	if want := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC); !first.StartedAt.Equal(want) {
+		t.Errorf("StartedAt = %v, want %v", first.StartedAt, want)
+	}
+
+	want := []TranscriptEntry{
+		{Kind: TranscriptText, Text: "Let me look at the code."},
+		{Kind: TranscriptToolUse, ToolID: "tool_1", ToolName: "Read", ToolTarget: "main.go"},
+		{Kind: TranscriptToolResult, ToolID: "tool_1", ToolName: "Read", Text: "package main"},
+		{Kind: TranscriptToolResult, ToolID: "tool_2", Text: "boom", IsError: true},
+		{Kind: TranscriptUsage, InputTokens: 100, OutputTokens: 20, CostUSD: 0.5},
+	}
+	if len(first.Entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), first.Entries)
+	}
+	for i := range want {
+		if first.Entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, first.Entries[i], want[i])
+		}
+	}
+
+	second := attempts[1]
+	if second.Attempt != 2 || second.Outcome != TranscriptSucceeded {
+		t.Errorf("second attempt = %+v", second)
+	}
+	if len(second.Entries) != 1 || second.Entries[0].Text != "plain stderr line" {
+		t.Errorf("expected plain text entry, got %+v", second.Entries)
+	}
+
+	if attempts[2].TaskID != "t02" || attempts[2].Outcome != TranscriptIncomplete {
+		t.Errorf("third attempt = %+v", attempts[2])
+	}
+}
+
+func TestLoadTranscript(t *testing.T) {
+	t.Run("missing output", func(t *testing.T) {
+		attempts, err := LoadTranscript(t.TempDir())
+		if err != nil || len(attempts) != 0 {
+			t.Errorf("expected no attempts, got %+v, %v", attempts, err)
+		}
+	})
+
+	t.Run("reads output.log", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, outputLogFileName), []byte(transcriptFixture), 0644); err != nil {
+			t.Fatalf("failed to write output.log: %v", err)
+		}
+		attempts, err := LoadTranscript(dir)
+		if err != nil {
+			t.Fatalf("LoadTranscript() error: %v", err)
+		}
+		if len(attempts) != 3 {
+			t.Errorf("expected 3 attempts, got %d", len(attempts))
+		}
+	})
+}
@@ -2,7 +2,9 @@ package git
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -105,3 +107,201 @@ func CommitAll(dir string, message string) error {
 
 	return nil
 }
+
+// CommitPaths commits the uncommitted changes to paths (files, or folders
+// whose files are all included), and nothing else, with the given message.
+// Other staged changes stay staged.
+// Returns nil if paths have no changes to commit.
+// If dir is empty, uses the current working directory.
+func CommitPaths(dir string, message string, paths []string) error {
+	changed, err := ChangedPaths(dir)
+	if err != nil {
+		return err
+	}
+	var selected []string
+	for _, file := range changed {
+		for _, path := range paths {
+			path = filepath.ToSlash(filepath.Clean(path))
+			if file == path || strings.HasPrefix(file, path+"/") {
+				selected = append(selected, file)
+				break
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	paths = selected
+
+	addCmd := exec.Command("git", append([]string{"add", "-A", "--"}, paths...)...)
+	if dir != "" {
+		addCmd.Dir = dir
+	}
+	if err := addCmd.Run(); err != nil {
+		return fmt.Errorf("git add: %w", err)
+	}
+
+	diffCmd := exec.Command("git", append([]string{"diff", "--cached", "--quiet", "--"}, paths...)...)
+	if dir != "" {
+		diffCmd.Dir = dir
+	}
+	if err := diffCmd.Run(); err == nil {
+		return nil
+	}
+
+	commitCmd := exec.Command("git", append([]string{"commit", "-q", "-m", message, "--"}, paths...)...)
+	if dir != "" {
+		commitCmd.Dir = dir
+	}
+	if err := commitCmd.Run(); err != nil {
+		return fmt.Errorf("git commit: %w", err)
+	}
+	return nil
+}
+
+// CurrentBranch returns the name of the branch checked out in dir. It fails
+// when HEAD is detached.
+// If dir is empty, uses the current working directory.
+func CurrentBranch(dir string) (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--short", "HEAD")
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git symbolic-ref: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GitPath returns the path of name inside the git directory of the
+// repository at dir, such as .git/name, resolved as git rev-parse --git-path
+// does for worktrees. Files there aren't part of the working tree.
+// If dir is empty, uses the current working directory.
+func GitPath(dir, name string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", name)
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) && dir != "" {
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
+// Diff returns the uncommitted changes in dir against HEAD as a unified
+// diff, with untracked files shown as added. Paths under any of exclude
+// are left out.
+// If dir is empty, uses the current working directory.
+func Diff(dir string, exclude ...string) (string, error) {
+	pathspec := []string{"--", "."}
+	for _, path := range exclude {
+		pathspec = append(pathspec, ":(exclude)"+path)
+	}
+
+	cmd := exec.Command("git", append([]string{"diff", "HEAD"}, pathspec...)...)
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	var sb strings.Builder
+	sb.Write(output)
+
+	cmd = exec.Command("git", append([]string{"ls-files", "--others", "--exclude-standard"}, pathspec...)...)
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	output, err = cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git ls-files: %w", err)
+	}
+	for _, file := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if file == "" {
+			continue
+		}
+		cmd = exec.Command("git", "diff", "--no-index", "--", "/dev/null", file)
+		if dir != "" {
+			cmd.Dir = dir
+		}
+		// --no-index exits with 1 when the files differ, which they do.
+		fileDiff, err := cmd.Output()
+		if exitErr, ok := err.(*exec.ExitError); err != nil && (!ok || exitErr.ExitCode() != 1) {
+			return "", fmt.Errorf("git diff %s: %w", file, err)
+		}
+		sb.Write(fileDiff)
+	}
+	return sb.String(), nil
+}
+
+// ChangedPaths returns every path with uncommitted changes in dir: files in
+// untracked directories are listed one by one, and a rename lists both its
+// old and new path.
+// If dir is empty, uses the current working directory.
+func ChangedPaths(dir string) ([]string, error) {
+	cmd := exec.Command("git", "status", "--porcelain", "-z", "--untracked-files=all")
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+
+	var paths []string
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		paths = append(paths, entry[3:])
+		// With -z, a rename or copy is followed by its source path.
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+			if i < len(entries) && entries[i] != "" {
+				paths = append(paths, entries[i])
+			}
+		}
+	}
+	return paths, nil
+}
+
+// RevertPaths undoes the uncommitted changes to paths in dir: files in HEAD
+// are restored, and new files are unstaged and deleted.
+// If dir is empty, uses the current working directory.
+func RevertPaths(dir string, paths []string) error {
+	for _, path := range paths {
+		inHead := exec.Command("git", "cat-file", "-e", "HEAD:"+path)
+		if dir != "" {
+			inHead.Dir = dir
+		}
+		tracked := inHead.Run() == nil
+		var cmd *exec.Cmd
+		if tracked {
+			cmd = exec.Command("git", "checkout", "HEAD", "--", path)
+		} else {
+			cmd = exec.Command("git", "rm", "-q", "-f", "--cached", "--ignore-unmatch", "--", path)
+		}
+		if dir != "" {
+			cmd.Dir = dir
+		}
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to revert %s: %w", path, err)
+		}
+		if tracked {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, path)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to revert %s: %w", path, err)
+		}
+	}
+	return nil
+}
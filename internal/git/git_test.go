@@ -4,6 +4,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -430,3 +432,178 @@ func TestCommitAll(t *testing.T) {
 		}
 	})
 }
+
+func TestCurrentBranch(t *testing.T) {
+	t.Parallel()
+	dir := setupTestRepo(t)
+
+	cmd := exec.Command("git", "checkout", "-b", "feature/prompts")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to create branch: %v", err)
+	}
+
+	branch, err := CurrentBranch(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if branch != "feature/prompts" {
+		t.Errorf("expected feature/prompts, got %q", branch)
+	}
+}
+
+func TestGitPath(t *testing.T) {
+	t.Parallel()
+	dir := setupTestRepo(t)
+
+	path, err := GitPath(dir, "rafa/metrics.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != filepath.Join(dir, ".git", "rafa", "metrics.json") {
+		t.Errorf("unexpected path %q", path)
+	}
+
+	if _, err := GitPath(t.TempDir(), "rafa/metrics.json"); err == nil {
+		t.Error("expected an error outside a repository")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	dir := setupTestRepo(t)
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CommitAll(dir, "initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main // new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".rafa"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".rafa", "progress.log"), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := Diff(dir, ".rafa")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"+func main() {}", "+++ b/new.go", "+package main // new"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected diff to contain %q, got:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "progress.log") {
+		t.Errorf("expected .rafa to be excluded, got:\n%s", diff)
+	}
+}
+
+func TestChangedPathsAndRevertPaths(t *testing.T) {
+	t.Parallel()
+	dir := setupTestRepo(t)
+
+	for name, content := range map[string]string{"keep.go": "package keep\n", "old.go": "package old\n", "edit.go": "package edit\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := CommitAll(dir, "initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	// An edit, a staged rename and a new file in an untracked directory.
+	if err := os.WriteFile(filepath.Join(dir, "edit.go"), []byte("package edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "mv", "old.go", "renamed.go")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "gen", "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gen", "sub", "x.go"), []byte("package x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := ChangedPaths(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(paths)
+	want := []string{"edit.go", "gen/sub/x.go", "old.go", "renamed.go"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("ChangedPaths() = %v, want %v", paths, want)
+	}
+
+	if err := RevertPaths(dir, paths); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status, err := GetStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Clean {
+		t.Errorf("expected a clean workspace after reverting everything, got %v", status.Files)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "edit.go")); string(data) != "package edit\n" {
+		t.Errorf("expected edit.go to be restored, got %q", data)
+	}
+}
+
+func TestCommitPaths(t *testing.T) {
+	t.Parallel()
+	dir := setupTestRepo(t)
+
+	for name, content := range map[string]string{"out.log": "output\n", "other.go": "package other\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := CommitAll(dir, "initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "out.log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.go"), []byte("package edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CommitPaths(dir, "prune", []string{"out.log"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status, err := GetStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(status.Files, []string{"other.go"}) {
+		t.Errorf("expected only other.go to stay uncommitted, got %v", status.Files)
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%s", "--name-status")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(string(output)); !reflect.DeepEqual(got, []string{"prune", "D", "out.log"}) {
+		t.Errorf("unexpected last commit: %q", output)
+	}
+
+	// Nothing left to commit for the path.
+	if err := CommitPaths(dir, "again", []string{"out.log"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
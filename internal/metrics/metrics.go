@@ -0,0 +1,315 @@
+// Package metrics keeps a local history of task outcomes across plans and
+// aggregates it into trends: first-attempt success rate, average cost per task
+// and median task duration, grouped by week, agent backend or design doc.
+//
+// The history lives in the repository's git directory, outside the working
+// tree, and is refreshed from every plan's progress.log and captured output,
+// so it outlives plans that are deleted or whose output is pruned.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/git"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// StoreFileName is the metrics store's file name.
+const StoreFileName = "metrics.json"
+
+// storeVersion is the format version written to the store.
+const storeVersion = 1
+
+// legacyBackend is assumed for runs logged before plan_started recorded the
+// agent backend; Claude Code was the only backend then.
+const legacyBackend = "claude"
+
+// noDesignDoc groups plans that were not created from a design document.
+const noDesignDoc = "(none)"
+
+// TaskRecord is the outcome of one task of one plan.
+type TaskRecord struct {
+	PlanID    string    `json:"planId"` // Plan folder name ("shortID-name")
+	TaskID    string    `json:"taskId"`
+	DesignDoc string    `json:"designDoc"`
+	Backend   string    `json:"backend"`
+	StartedAt time.Time `json:"startedAt"` // Start of the first attempt
+	Attempts  int       `json:"attempts"`
+	Succeeded bool      `json:"succeeded"`
+	// FirstAttemptSuccess is true when the task succeeded on its first attempt.
+	FirstAttemptSuccess bool    `json:"firstAttemptSuccess"`
+	DurationSeconds     float64 `json:"durationSeconds"` // Sum of attempt durations
+	CostUSD             float64 `json:"costUsd"`
+}
+
+// Store is the persisted task history.
+type Store struct {
+	Version   int          `json:"version"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	Tasks     []TaskRecord `json:"tasks"`
+}
+
+// StorePath returns the path of the metrics store: rafa/metrics.json in the
+// git directory of the repository in the current directory. Keeping it out
+// of the working tree means refreshing it doesn't leave the workspace dirty
+// for the next run. Outside a git repository it is .rafa/metrics.json.
+func StorePath() string {
+	if path, err := git.GitPath("", "rafa/"+StoreFileName); err == nil {
+		return path
+	}
+	return filepath.Join(".rafa", StoreFileName)
+}
+
+// LoadStore reads the store at path. A missing file yields an empty store.
+func LoadStore(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Store{Version: storeVersion}, nil
+		}
+		return nil, fmt.Errorf("failed to read metrics store: %w", err)
+	}
+	var s Store
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse metrics store: %w", err)
+	}
+	return &s, nil
+}
+
+// Save atomically writes the store to path.
+func (s *Store) Save(path string) error {
+	s.Version = storeVersion
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics store: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics store directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metrics store: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write metrics store: %w", err)
+	}
+	return nil
+}
+
+// Merge adds records to the store, replacing earlier records of the same
+// plan and task. A task's cost is read from captured output, which rafa gc
+// prunes, and never goes down, so the larger of the stored and the new cost
+// is kept. Records are kept in start order.
+func (s *Store) Merge(records []TaskRecord) {
+	index := make(map[string]int, len(s.Tasks))
+	for i, r := range s.Tasks {
+		index[r.PlanID+"/"+r.TaskID] = i
+	}
+	for _, r := range records {
+		if i, ok := index[r.PlanID+"/"+r.TaskID]; ok {
+			r.CostUSD = max(r.CostUSD, s.Tasks[i].CostUSD)
+			s.Tasks[i] = r
+			continue
+		}
+		index[r.PlanID+"/"+r.TaskID] = len(s.Tasks)
+		s.Tasks = append(s.Tasks, r)
+	}
+	sort.SliceStable(s.Tasks, func(i, j int) bool {
+		return s.Tasks[i].StartedAt.Before(s.Tasks[j].StartedAt)
+	})
+}
+
+// Collect returns a record for every task of the plan in planDir that has
+// been attempted at least once.
+func Collect(planDir string) ([]TaskRecord, error) {
+	p, err := plan.ReadPlan(planDir)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := plan.NewProgressReader(planDir).Stats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress log: %w", err)
+	}
+	costs, err := taskCosts(planDir, stats)
+	if err != nil {
+		return nil, err
+	}
+	planID := filepath.Base(planDir)
+
+	designDoc := p.SourceFile
+	if designDoc == "" {
+		designDoc = noDesignDoc
+	}
+
+	var records []TaskRecord
+	for _, t := range stats.Tasks {
+		if len(t.Attempts) == 0 {
+			continue
+		}
+		backend := ""
+		if last := t.Attempts[len(t.Attempts)-1]; last.Run >= 0 && last.Run < len(stats.Runs) {
+			backend = stats.Runs[last.Run].Backend
+		}
+		if backend == "" {
+			backend = legacyBackend
+		}
+		records = append(records, TaskRecord{
+			PlanID:              planID,
+			TaskID:              t.TaskID,
+			DesignDoc:           designDoc,
+			Backend:             backend,
+			StartedAt:           t.Attempts[0].Started,
+			Attempts:            len(t.Attempts),
+			Succeeded:           t.Succeeded(),
+			FirstAttemptSuccess: t.AttemptsToSuccess == 1,
+			DurationSeconds:     t.Duration.Seconds(),
+			CostUSD:             costs[t.TaskID],
+		})
+	}
+	return records, nil
+}
+
+// taskCosts sums the cost reported in each task's captured output, reading
+// the attempts' output files from stats.
+func taskCosts(planDir string, stats *plan.ProgressStats) (map[string]float64, error) {
+	attempts, err := executor.ReadTranscript(planDir, plan.AttemptOutputs(stats))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output: %w", err)
+	}
+	costs := make(map[string]float64)
+	for _, a := range attempts {
+		for _, e := range a.Entries {
+			if e.Kind == executor.TranscriptUsage {
+				costs[a.TaskID] += e.CostUSD
+			}
+		}
+	}
+	return costs, nil
+}
+
+// CollectAll collects the records of every plan folder inside dirs (such as
+// .rafa/plans and .rafa/archive). Missing dirs and plans that cannot be read
+// are skipped.
+func CollectAll(dirs []string) ([]TaskRecord, error) {
+	var records []TaskRecord
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			planRecords, err := Collect(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			records = append(records, planRecords...)
+		}
+	}
+	return records, nil
+}
+
+// GroupBy selects how records are grouped.
+type GroupBy string
+
+const (
+	ByWeek      GroupBy = "week"
+	ByBackend   GroupBy = "backend"
+	ByDesignDoc GroupBy = "design"
+)
+
+// Groupings lists every GroupBy, in report order.
+var Groupings = []GroupBy{ByWeek, ByBackend, ByDesignDoc}
+
+// ParseGroupBy validates a grouping name.
+func ParseGroupBy(s string) (GroupBy, error) {
+	for _, g := range Groupings {
+		if string(g) == s {
+			return g, nil
+		}
+	}
+	return "", fmt.Errorf("unknown grouping %q (expected week, backend or design)", s)
+}
+
+// Group is the aggregate of the records sharing a key.
+type Group struct {
+	Key                     string  `json:"key"`
+	Tasks                   int     `json:"tasks"`
+	FirstAttemptSuccessRate float64 `json:"firstAttemptSuccessRate"` // 0 to 1
+	AvgCostUSD              float64 `json:"avgCostUsd"`
+	MedianDurationSeconds   float64 `json:"medianDurationSeconds"`
+}
+
+// Aggregate groups records and computes each group's trends. Groups are
+// sorted by key, so weeks are in chronological order.
+func Aggregate(records []TaskRecord, by GroupBy) []Group {
+	grouped := make(map[string][]TaskRecord)
+	for _, r := range records {
+		key := groupKey(r, by)
+		grouped[key] = append(grouped[key], r)
+	}
+
+	groups := make([]Group, 0, len(grouped))
+	for key, rs := range grouped {
+		g := Group{Key: key, Tasks: len(rs)}
+		firstTry := 0
+		cost := 0.0
+		durations := make([]float64, 0, len(rs))
+		for _, r := range rs {
+			if r.FirstAttemptSuccess {
+				firstTry++
+			}
+			cost += r.CostUSD
+			durations = append(durations, r.DurationSeconds)
+		}
+		g.FirstAttemptSuccessRate = float64(firstTry) / float64(len(rs))
+		g.AvgCostUSD = cost / float64(len(rs))
+		g.MedianDurationSeconds = median(durations)
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+func groupKey(r TaskRecord, by GroupBy) string {
+	switch by {
+	case ByBackend:
+		return r.Backend
+	case ByDesignDoc:
+		return r.DesignDoc
+	default:
+		return Week(r.StartedAt)
+	}
+}
+
+// Week returns the ISO week of t, e.g. "2024-W03".
+func Week(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
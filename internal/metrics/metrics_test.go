@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+const metricsOutputFixture = `
+=== Task t01, Attempt 1 ===
+Started: 2024-01-15T10:00:00Z
+
+{"type":"result","subtype":"success","usage":{"input_tokens":1000,"output_tokens":200},"total_cost_usd":0.5}
+
+=== Task t01: SUCCESS ===
+
+`
+
+const metricsProgressFixture = `{"timestamp":"2024-01-15T10:00:00Z","event":"plan_started","data":{"plan_id":"abc123","backend":"codex"}}
+{"timestamp":"2024-01-15T10:00:00Z","event":"task_started","data":{"task_id":"t01","attempt":1}}
+{"timestamp":"2024-01-15T10:02:00Z","event":"task_completed","data":{"task_id":"t01"}}
+{"timestamp":"2024-01-15T10:02:00Z","event":"task_started","data":{"task_id":"t02","attempt":1}}
+{"timestamp":"2024-01-15T10:03:00Z","event":"task_failed","data":{"task_id":"t02","attempt":1}}
+{"timestamp":"2024-01-15T10:03:00Z","event":"plan_failed","data":{"task_id":"t02","attempts":1}}
+`
+
+func writeMetricsPlan(t *testing.T, dir, folder, progress string) string {
+	t.Helper()
+	planDir := filepath.Join(dir, folder)
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		t.Fatalf("failed to create plan dir: %v", err)
+	}
+	p := &plan.Plan{
+		SchemaVersion: plan.CurrentSchemaVersion,
+		ID:            "abc123",
+		Name:          "auth",
+		SourceFile:    "docs/designs/auth.md",
+		Status:        plan.PlanStatusFailed,
+		Tasks: []plan.Task{
+			{ID: "t01", Title: "One", Status: plan.TaskStatusCompleted},
+			{ID: "t02", Title: "Two", Status: plan.TaskStatusFailed},
+			{ID: "t03", Title: "Never run", Status: plan.TaskStatusPending},
+		},
+	}
+	if err := plan.SavePlan(planDir, p); err != nil {
+		t.Fatalf("failed to save plan: %v", err)
+	}
+	files := map[string]string{"output.log": metricsOutputFixture, "progress.log": progress}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(planDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return planDir
+}
+
+func TestCollect(t *testing.T) {
+	planDir := writeMetricsPlan(t, t.TempDir(), "abc123-auth", metricsProgressFixture)
+
+	records, err := Collect(planDir)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected records for the 2 attempted tasks, got %+v", records)
+	}
+
+	t01 := records[0]
+	if t01.PlanID != "abc123-auth" || t01.TaskID != "t01" || t01.DesignDoc != "docs/designs/auth.md" || t01.Backend != "codex" {
+		t.Errorf("t01 = %+v", t01)
+	}
+	if !t01.Succeeded || !t01.FirstAttemptSuccess || t01.DurationSeconds != 120 || t01.CostUSD != 0.5 {
+		t.Errorf("t01 = %+v", t01)
+	}
+	if want := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC); !t01.StartedAt.Equal(want) {
+		t.Errorf("StartedAt = %v, want %v", t01.StartedAt, want)
+	}
+
+	t02 := records[1]
+	if t02.Succeeded || t02.FirstAttemptSuccess || t02.Attempts != 1 {
+		t.Errorf("t02 = %+v", t02)
+	}
+}
+
+func TestCollect_LegacyBackend(t *testing.T) {
+	legacy := `{"timestamp":"2024-01-15T10:00:00Z","event":"plan_started","data":{"plan_id":"abc123"}}
+{"timestamp":"2024-01-15T10:00:00Z","event":"task_started","data":{"task_id":"t01","attempt":1}}
+{"timestamp":"2024-01-15T10:02:00Z","event":"task_completed","data":{"task_id":"t01"}}
+`
+	records, err := Collect(writeMetricsPlan(t, t.TempDir(), "abc123-auth", legacy))
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if len(records) != 1 || records[0].Backend != legacyBackend {
+		t.Errorf("expected legacy backend, got %+v", records)
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	root := t.TempDir()
+	plans := filepath.Join(root, "plans")
+	archive := filepath.Join(root, "archive")
+	writeMetricsPlan(t, plans, "abc123-auth", metricsProgressFixture)
+	writeMetricsPlan(t, archive, "def456-auth", metricsProgressFixture)
+	if err := os.MkdirAll(filepath.Join(plans, "broken"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+
+	records, err := CollectAll([]string{plans, archive, filepath.Join(root, "missing")})
+	if err != nil {
+		t.Fatalf("CollectAll() error: %v", err)
+	}
+	if len(records) != 4 {
+		t.Errorf("expected 4 records from both folders, got %d", len(records))
+	}
+}
+
+func TestStore_MergeAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StoreFileName)
+
+	store, err := LoadStore(path)
+	if err != nil {
+		t.Fatalf("LoadStore() error: %v", err)
+	}
+	if len(store.Tasks) != 0 {
+		t.Fatalf("expected empty store, got %+v", store.Tasks)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	store.Merge([]TaskRecord{
+		{PlanID: "p1", TaskID: "t02", StartedAt: day(3), Attempts: 1, CostUSD: 0.5},
+		{PlanID: "p1", TaskID: "t01", StartedAt: day(1), Attempts: 1},
+	})
+	// The refresh after gc pruned t02's output has no cost to report.
+	store.Merge([]TaskRecord{{PlanID: "p1", TaskID: "t02", StartedAt: day(3), Attempts: 2, Succeeded: true}})
+	if err := store.Save(path); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := LoadStore(path)
+	if err != nil {
+		t.Fatalf("LoadStore() error: %v", err)
+	}
+	if len(loaded.Tasks) != 2 {
+		t.Fatalf("expected 2 records, got %+v", loaded.Tasks)
+	}
+	if loaded.Tasks[0].TaskID != "t01" || loaded.Tasks[1].Attempts != 2 || !loaded.Tasks[1].Succeeded || loaded.Tasks[1].CostUSD != 0.5 {
+		t.Errorf("unexpected records: %+v", loaded.Tasks)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	week1 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	week2 := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	records := []TaskRecord{
+		{StartedAt: week1, Backend: "claude", DesignDoc: "a.md", FirstAttemptSuccess: true, DurationSeconds: 60, CostUSD: 1},
+		{StartedAt: week1, Backend: "claude", DesignDoc: "b.md", FirstAttemptSuccess: false, DurationSeconds: 180, CostUSD: 3},
+		{StartedAt: week2, Backend: "codex", DesignDoc: "a.md", FirstAttemptSuccess: true, DurationSeconds: 30, CostUSD: 2},
+		{StartedAt: week2, Backend: "claude", DesignDoc: "a.md", FirstAttemptSuccess: true, DurationSeconds: 90, CostUSD: 0},
+	}
+
+	weeks := Aggregate(records, ByWeek)
+	if len(weeks) != 2 || weeks[0].Key != "2024-W01" || weeks[1].Key != "2024-W02" {
+		t.Fatalf("unexpected weeks: %+v", weeks)
+	}
+	if weeks[0].Tasks != 2 || weeks[0].FirstAttemptSuccessRate != 0.5 || weeks[0].AvgCostUSD != 2 || weeks[0].MedianDurationSeconds != 120 {
+		t.Errorf("week 1 = %+v", weeks[0])
+	}
+
+	backends := Aggregate(records, ByBackend)
+	if len(backends) != 2 || backends[0].Key != "claude" || backends[0].Tasks != 3 || backends[0].MedianDurationSeconds != 90 {
+		t.Errorf("unexpected backends: %+v", backends)
+	}
+
+	designs := Aggregate(records, ByDesignDoc)
+	if len(designs) != 2 || designs[0].Key != "a.md" || designs[0].FirstAttemptSuccessRate != 1 {
+		t.Errorf("unexpected designs: %+v", designs)
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	if g, err := ParseGroupBy("design"); err != nil || g != ByDesignDoc {
+		t.Errorf("ParseGroupBy(design) = %q, %v", g, err)
+	}
+	if _, err := ParseGroupBy("month"); err == nil {
+		t.Error("expected error for unknown grouping")
+	}
+}
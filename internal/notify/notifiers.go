@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// commandNotifier runs a shell command with the notification in RAFA_*
+// environment variables and as JSON on stdin.
+type commandNotifier struct {
+	command string
+	dir     string
+}
+
+func (c *commandNotifier) Notify(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.command)
+	cmd.Dir = c.dir
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"RAFA_EVENT="+n.Event,
+		"RAFA_PLAN_ID="+n.PlanID,
+		"RAFA_PLAN_NAME="+n.PlanName,
+		"RAFA_TASK_ID="+n.TaskID,
+		"RAFA_TASK_TITLE="+n.TaskTitle,
+		"RAFA_MESSAGE="+n.Message,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// webhookPayload is a Slack incoming-webhook message. The notification
+// fields ride along for webhooks that want structured data.
+type webhookPayload struct {
+	Text string `json:"text"`
+	Notification
+}
+
+// webhookNotifier POSTs the notification as JSON.
+type webhookNotifier struct {
+	url    string
+	client *http.Client // nil uses http.DefaultClient
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(webhookPayload{Text: n.Message, Notification: n})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// fileNotifier appends one JSON line per notification.
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (f *fileNotifier) Notify(ctx context.Context, n Notification) error {
+	line, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// bellNotifier rings the terminal bell.
+type bellNotifier struct {
+	w io.Writer
+}
+
+func (b *bellNotifier) Notify(ctx context.Context, n Notification) error {
+	_, err := io.WriteString(b.w, "\a")
+	return err
+}
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/config"
+)
+
+var testNotification = Notification{
+	Event:    EventPlanFailed,
+	PlanID:   "abc123-auth",
+	PlanName: "auth",
+	TaskID:   "t02",
+	Error:    "tests failed",
+	Message:  "rafa: auth failed on task t02: tests failed",
+}
+
+func TestWebhookNotifier_PostsSlackPayload(t *testing.T) {
+	var got map[string]interface{}
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("invalid payload %q: %v", body, err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	d, err := New([]config.Notification{{Type: TypeWebhook, URL: server.URL, Events: []string{EventPlanFailed}}}, t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := d.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+	if got["text"] != testNotification.Message {
+		t.Errorf("expected Slack text field, got %v", got["text"])
+	}
+	if got["event"] != EventPlanFailed || got["planId"] != "abc123-auth" || got["error"] != "tests failed" {
+		t.Errorf("expected notification fields in payload, got %v", got)
+	}
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	n := &webhookNotifier{url: server.URL}
+	err := n.Notify(context.Background(), testNotification)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
+
+func TestCommandNotifier(t *testing.T) {
+	dir := t.TempDir()
+	n := &commandNotifier{
+		command: `printf '%s|%s|%s\n' "$RAFA_EVENT" "$RAFA_PLAN_NAME" "$RAFA_MESSAGE" > env.txt; cat > stdin.json`,
+		dir:     dir,
+	}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	env, err := os.ReadFile(filepath.Join(dir, "env.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "plan_failed|auth|" + testNotification.Message + "\n"; string(env) != want {
+		t.Errorf("env = %q, want %q", env, want)
+	}
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Notification
+	if err := json.Unmarshal(stdin, &got); err != nil || got.TaskID != "t02" {
+		t.Errorf("expected notification JSON on stdin, got %q (%v)", stdin, err)
+	}
+}
+
+func TestCommandNotifier_Failure(t *testing.T) {
+	n := &commandNotifier{command: "echo no route to host >&2; exit 3", dir: t.TempDir()}
+	err := n.Notify(context.Background(), testNotification)
+	if err == nil || !strings.Contains(err.Error(), "no route to host") {
+		t.Errorf("expected command output in error, got %v", err)
+	}
+}
+
+func TestFileNotifier_AppendsLines(t *testing.T) {
+	baseDir := t.TempDir()
+	d, err := New([]config.Notification{{Type: TypeFile, Path: "logs/rafa.jsonl", Events: []string{EventPlanFailed}}}, baseDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := d.Notify(context.Background(), testNotification); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(baseDir, "logs", "rafa.jsonl"))
+	if err != nil {
+		t.Fatalf("expected file relative to base dir: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", data)
+	}
+	var got Notification
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil || got.Event != EventPlanFailed {
+		t.Errorf("unexpected line %q (%v)", lines[1], err)
+	}
+}
+
+func TestBellNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	n := &bellNotifier{w: &buf}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if buf.String() != "\a" {
+		t.Errorf("expected bell character, got %q", buf.String())
+	}
+}
@@ -0,0 +1,231 @@
+// Package notify tells the user about run events when they are away from the
+// terminal: by running a shell command, posting to a webhook, appending to a
+// file or ringing the terminal bell. Channels and the events they fire on are
+// configured in the notifications section of .rafa/config.json.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// Events that can be notified.
+const (
+	EventPlanCompleted = "plan_completed"
+	EventPlanFailed    = "plan_failed"
+	EventTaskCompleted = "task_completed" // A checkpoint: one more task is done
+	EventTaskFailed    = "task_failed"    // An attempt failed; it may be retried
+)
+
+// Notification channel types.
+const (
+	TypeCommand = "command"
+	TypeWebhook = "webhook"
+	TypeFile    = "file"
+	TypeBell    = "bell"
+)
+
+// defaultEvents are notified when a channel lists no events.
+var defaultEvents = []string{EventPlanCompleted, EventPlanFailed}
+
+// sendTimeout bounds how long one channel may take, since notifications are
+// sent from the executor's goroutine.
+const sendTimeout = 10 * time.Second
+
+// Notification describes one run event.
+type Notification struct {
+	Event           string    `json:"event"`
+	PlanID          string    `json:"planId"` // Plan folder name ("shortID-name")
+	PlanName        string    `json:"planName"`
+	TaskID          string    `json:"taskId,omitempty"`
+	TaskTitle       string    `json:"taskTitle,omitempty"`
+	Attempt         int       `json:"attempt,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	Succeeded       int       `json:"succeeded,omitempty"`
+	Total           int       `json:"total,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds,omitempty"`
+	Message         string    `json:"message"` // One-line human readable summary
+	Time            time.Time `json:"time"`
+}
+
+// Notifier delivers notifications over one channel.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+type target struct {
+	kind     string
+	events   map[string]bool
+	notifier Notifier
+}
+
+// Dispatcher sends each notification to the channels configured for its
+// event.
+type Dispatcher struct {
+	targets []target
+	now     func() time.Time
+}
+
+// New creates a Dispatcher for the configured channels. Relative command
+// directories and file paths are resolved against baseDir, the repository
+// root.
+func New(cfgs []config.Notification, baseDir string) (*Dispatcher, error) {
+	d := &Dispatcher{now: time.Now}
+	for i, cfg := range cfgs {
+		n, err := newNotifier(cfg, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("notification %d: %w", i+1, err)
+		}
+		events := cfg.Events
+		if len(events) == 0 {
+			events = defaultEvents
+		}
+		t := target{kind: cfg.Type, events: make(map[string]bool), notifier: n}
+		for _, ev := range events {
+			switch ev {
+			case EventPlanCompleted, EventPlanFailed, EventTaskCompleted, EventTaskFailed:
+				t.events[ev] = true
+			default:
+				return nil, fmt.Errorf("notification %d: unknown event %q", i+1, ev)
+			}
+		}
+		d.targets = append(d.targets, t)
+	}
+	return d, nil
+}
+
+func newNotifier(cfg config.Notification, baseDir string) (Notifier, error) {
+	switch cfg.Type {
+	case TypeCommand:
+		if cfg.Command == "" {
+			return nil, errors.New("command notification needs a command")
+		}
+		return &commandNotifier{command: cfg.Command, dir: baseDir}, nil
+	case TypeWebhook:
+		if cfg.URL == "" {
+			return nil, errors.New("webhook notification needs a url")
+		}
+		return &webhookNotifier{url: cfg.URL}, nil
+	case TypeFile:
+		if cfg.Path == "" {
+			return nil, errors.New("file notification needs a path")
+		}
+		path := cfg.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		return &fileNotifier{path: path}, nil
+	case TypeBell:
+		return &bellNotifier{w: os.Stderr}, nil
+	default:
+		return nil, fmt.Errorf("unknown notification type %q", cfg.Type)
+	}
+}
+
+// Notify sends n to every channel configured for its event, returning the
+// errors of channels that failed.
+func (d *Dispatcher) Notify(ctx context.Context, n Notification) error {
+	if d == nil {
+		return nil
+	}
+	if n.Time.IsZero() {
+		n.Time = d.now()
+	}
+	var errs []error
+	for _, t := range d.targets {
+		if !t.events[n.Event] {
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		if err := t.notifier.Notify(sendCtx, n); err != nil {
+			errs = append(errs, fmt.Errorf("%s notification failed: %w", t.kind, err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// Events returns executor events that notify about the run of one plan.
+// Delivery errors are passed to onError, which may be nil. It returns nil
+// when no channels are configured.
+func (d *Dispatcher) Events(planID, planName string, onError func(error)) executor.ExecutorEvents {
+	if d == nil || len(d.targets) == 0 {
+		return nil
+	}
+	return &planEvents{d: d, planID: planID, planName: planName, onError: onError}
+}
+
+// planEvents turns executor events into notifications.
+type planEvents struct {
+	d        *Dispatcher
+	planID   string
+	planName string
+	onError  func(error)
+}
+
+func (e *planEvents) send(n Notification) {
+	n.PlanID = e.planID
+	n.PlanName = e.planName
+	if err := e.d.Notify(context.Background(), n); err != nil && e.onError != nil {
+		e.onError(err)
+	}
+}
+
+func (e *planEvents) OnTaskStart(taskNum, total int, task *plan.Task, attempt int) {}
+
+func (e *planEvents) OnTaskComplete(task *plan.Task) {
+	e.send(Notification{
+		Event:     EventTaskCompleted,
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		Message:   fmt.Sprintf("rafa: %s: task %s completed (%s)", e.planName, task.ID, task.Title),
+	})
+}
+
+func (e *planEvents) OnTaskFailed(task *plan.Task, attempt int, err error) {
+	n := Notification{
+		Event:     EventTaskFailed,
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		Attempt:   attempt,
+		Message:   fmt.Sprintf("rafa: %s: task %s attempt %d failed", e.planName, task.ID, attempt),
+	}
+	if err != nil {
+		n.Error = err.Error()
+		n.Message += ": " + n.Error
+	}
+	e.send(n)
+}
+
+func (e *planEvents) OnOutput(line string) {}
+
+func (e *planEvents) OnPlanComplete(succeeded, total int, duration time.Duration) {
+	e.send(Notification{
+		Event:           EventPlanCompleted,
+		Succeeded:       succeeded,
+		Total:           total,
+		DurationSeconds: duration.Seconds(),
+		Message: fmt.Sprintf("rafa: %s completed: %d/%d tasks in %s",
+			e.planName, succeeded, total, duration.Round(time.Second)),
+	})
+}
+
+func (e *planEvents) OnPlanFailed(task *plan.Task, reason string) {
+	e.send(Notification{
+		Event:     EventPlanFailed,
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		Error:     reason,
+		Message:   fmt.Sprintf("rafa: %s failed on task %s: %s", e.planName, task.ID, reason),
+	})
+}
+
+var _ executor.ExecutorEvents = (*planEvents)(nil)
@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/config"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// recordingNotifier records notifications and returns err.
+type recordingNotifier struct {
+	got []Notification
+	err error
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n Notification) error {
+	r.got = append(r.got, n)
+	return r.err
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Notification
+		want string
+	}{
+		{"unknown type", config.Notification{Type: "pager"}, `unknown notification type "pager"`},
+		{"command without command", config.Notification{Type: TypeCommand}, "needs a command"},
+		{"webhook without url", config.Notification{Type: TypeWebhook}, "needs a url"},
+		{"file without path", config.Notification{Type: TypeFile}, "needs a path"},
+		{"unknown event", config.Notification{Type: TypeBell, Events: []string{"lunch"}}, `unknown event "lunch"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New([]config.Notification{tt.cfg}, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNew_DefaultEvents(t *testing.T) {
+	d, err := New([]config.Notification{{Type: TypeBell}}, t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	events := d.targets[0].events
+	if !events[EventPlanCompleted] || !events[EventPlanFailed] || events[EventTaskFailed] {
+		t.Errorf("expected plan events by default, got %v", events)
+	}
+}
+
+func TestDispatcher_RoutesByEvent(t *testing.T) {
+	planOnly := &recordingNotifier{}
+	failures := &recordingNotifier{err: errors.New("boom")}
+	d := &Dispatcher{
+		now: func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+		targets: []target{
+			{kind: TypeBell, events: map[string]bool{EventPlanCompleted: true}, notifier: planOnly},
+			{kind: TypeWebhook, events: map[string]bool{EventTaskFailed: true, EventPlanFailed: true}, notifier: failures},
+		},
+	}
+
+	var reported []error
+	events := d.Events("abc123-auth", "auth", func(err error) { reported = append(reported, err) })
+	task := &plan.Task{ID: "t02", Title: "Add logout"}
+	events.OnTaskStart(2, 2, task, 1)
+	events.OnTaskFailed(task, 1, errors.New("tests failed"))
+	events.OnTaskComplete(task)
+	events.OnPlanComplete(2, 2, 90*time.Second)
+
+	if len(planOnly.got) != 1 {
+		t.Fatalf("expected 1 plan notification, got %+v", planOnly.got)
+	}
+	n := planOnly.got[0]
+	if n.Event != EventPlanCompleted || n.PlanID != "abc123-auth" || n.Succeeded != 2 || n.Total != 2 {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+	if n.Message != "rafa: auth completed: 2/2 tasks in 1m30s" {
+		t.Errorf("unexpected message: %q", n.Message)
+	}
+	if !n.Time.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("expected dispatcher time, got %v", n.Time)
+	}
+
+	if len(failures.got) != 1 || failures.got[0].Event != EventTaskFailed || failures.got[0].Error != "tests failed" || failures.got[0].Attempt != 1 {
+		t.Fatalf("unexpected failure notifications: %+v", failures.got)
+	}
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "webhook notification failed: boom") {
+		t.Errorf("expected delivery error to be reported, got %v", reported)
+	}
+}
+
+func TestDispatcher_EventsNilWithoutTargets(t *testing.T) {
+	var d *Dispatcher
+	if d.Events("p", "p", nil) != nil {
+		t.Error("expected nil events from nil dispatcher")
+	}
+	empty, err := New(nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if empty.Events("p", "p", nil) != nil {
+		t.Error("expected nil events without channels")
+	}
+}
@@ -0,0 +1,104 @@
+package plan
+
+import (
+	"maps"
+	"slices"
+	"sort"
+)
+
+// AgentSettings are the agent options for a task attempt. Zero fields leave
+// the agent's own default.
+type AgentSettings struct {
+	Model           string            `json:"model,omitempty"`
+	MaxTurns        int               `json:"maxTurns,omitempty"`
+	AllowedTools    []string          `json:"allowedTools,omitempty"`
+	DisallowedTools []string          `json:"disallowedTools,omitempty"`
+	ExtraArgs       []string          `json:"extraArgs,omitempty"` // Passed to the agent CLI as is
+	Env             map[string]string `json:"env,omitempty"`       // Added to the agent's environment
+}
+
+// AgentConfig is agent settings with escalation rules, as set in the
+// project configuration, on a plan or on a task.
+type AgentConfig struct {
+	AgentSettings
+	// Escalate applies stronger settings to later attempts. Each rule
+	// applies once the task has failed AfterFailures times.
+	Escalate []Escalation `json:"escalate,omitempty"`
+}
+
+// Escalation is an escalation rule: settings that take over from the
+// attempt after the AfterFailures-th failure of a task.
+type Escalation struct {
+	AfterFailures int `json:"afterFailures"`
+	AgentSettings
+}
+
+// ResolveAgent returns the effective agent settings for an attempt (1-based)
+// from configs ordered from least to most specific, such as the project
+// configuration, the plan and the task; nil configs are skipped. Set fields
+// of a more specific config override, except Env, which is merged. The most
+// specific config with escalation rules supplies them, and every rule due
+// by the attempt is applied in order of AfterFailures.
+func ResolveAgent(attempt int, configs ...*AgentConfig) AgentSettings {
+	var settings AgentSettings
+	var rules []Escalation
+	for _, c := range configs {
+		if c == nil {
+			continue
+		}
+		settings = settings.merge(c.AgentSettings)
+		if len(c.Escalate) > 0 {
+			rules = c.Escalate
+		}
+	}
+
+	rules = slices.Clone(rules)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].AfterFailures < rules[j].AfterFailures })
+	for _, r := range rules {
+		if attempt-1 >= r.AfterFailures {
+			settings = settings.merge(r.AgentSettings)
+		}
+	}
+	return settings
+}
+
+// merge returns s with the set fields of o applied over it.
+func (s AgentSettings) merge(o AgentSettings) AgentSettings {
+	if o.Model != "" {
+		s.Model = o.Model
+	}
+	if o.MaxTurns != 0 {
+		s.MaxTurns = o.MaxTurns
+	}
+	if o.AllowedTools != nil {
+		s.AllowedTools = o.AllowedTools
+	}
+	if o.DisallowedTools != nil {
+		s.DisallowedTools = o.DisallowedTools
+	}
+	if o.ExtraArgs != nil {
+		s.ExtraArgs = o.ExtraArgs
+	}
+	if len(o.Env) > 0 {
+		env := maps.Clone(s.Env)
+		if env == nil {
+			env = make(map[string]string, len(o.Env))
+		}
+		maps.Copy(env, o.Env)
+		s.Env = env
+	}
+	return s
+}
+
+// IsZero reports whether no setting is set.
+func (s AgentSettings) IsZero() bool {
+	return s.Model == "" && s.MaxTurns == 0 && s.AllowedTools == nil &&
+		s.DisallowedTools == nil && s.ExtraArgs == nil && len(s.Env) == 0
+}
+
+// EnvNames returns the names of the environment variables s sets, sorted.
+func (s AgentSettings) EnvNames() []string {
+	names := slices.Collect(maps.Keys(s.Env))
+	sort.Strings(names)
+	return names
+}
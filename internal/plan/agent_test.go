@@ -0,0 +1,69 @@
+package plan
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestResolveAgent(t *testing.T) {
+	project := &AgentConfig{AgentSettings: AgentSettings{
+		Model:    "sonnet",
+		MaxTurns: 50,
+		Env:      map[string]string{"GOFLAGS": "-mod=mod", "CI": "1"},
+	}}
+	planConfig := &AgentConfig{
+		AgentSettings: AgentSettings{Model: "haiku", ExtraArgs: []string{"--debug"}},
+		Escalate: []Escalation{
+			{AfterFailures: 3, AgentSettings: AgentSettings{MaxTurns: 200}},
+			{AfterFailures: 2, AgentSettings: AgentSettings{Model: "opus", MaxTurns: 100}},
+		},
+	}
+	task := &AgentConfig{AgentSettings: AgentSettings{
+		DisallowedTools: []string{"WebFetch"},
+		Env:             map[string]string{"CI": "0"},
+	}}
+
+	got := ResolveAgent(1, project, planConfig, nil, task)
+	want := AgentSettings{
+		Model:           "haiku",
+		MaxTurns:        50,
+		DisallowedTools: []string{"WebFetch"},
+		ExtraArgs:       []string{"--debug"},
+		Env:             map[string]string{"GOFLAGS": "-mod=mod", "CI": "0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("attempt 1: got %+v, want %+v", got, want)
+	}
+	if project.Env["CI"] != "1" {
+		t.Error("resolving should not modify the configs")
+	}
+
+	if got := ResolveAgent(3, project, planConfig, task); got.Model != "opus" || got.MaxTurns != 100 {
+		t.Errorf("attempt 3: expected the first escalation, got %+v", got)
+	}
+	if got := ResolveAgent(4, project, planConfig, task); got.Model != "opus" || got.MaxTurns != 200 {
+		t.Errorf("attempt 4: expected both escalations, got %+v", got)
+	}
+
+	// A task's own rules replace the plan's.
+	task.Escalate = []Escalation{{AfterFailures: 1, AgentSettings: AgentSettings{Model: "sonnet"}}}
+	if got := ResolveAgent(4, project, planConfig, task); got.Model != "sonnet" || got.MaxTurns != 50 {
+		t.Errorf("expected the task's escalation only, got %+v", got)
+	}
+
+	if got := ResolveAgent(1); !got.IsZero() {
+		t.Errorf("expected zero settings without configs, got %+v", got)
+	}
+}
+
+func TestAgentConfig_JSON(t *testing.T) {
+	data := `{"model": "haiku", "maxTurns": 30, "escalate": [{"afterFailures": 2, "model": "opus"}]}`
+	var c AgentConfig
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Model != "haiku" || c.MaxTurns != 30 || len(c.Escalate) != 1 || c.Escalate[0].AfterFailures != 2 || c.Escalate[0].Model != "opus" {
+		t.Errorf("unexpected config %+v", c)
+	}
+}
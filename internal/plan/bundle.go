@@ -0,0 +1,454 @@
+package plan
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pablasso/rafa/internal/util"
+)
+
+// BundleFormatVersion is the version of the bundle layout written by ExportBundle.
+const BundleFormatVersion = 1
+
+// sourceSnapshotDir is the plan sub-folder holding an imported design doc snapshot.
+const sourceSnapshotDir = "source"
+
+// BundleFormat selects the on-disk encoding of a plan bundle.
+type BundleFormat string
+
+const (
+	BundleFormatTarGz BundleFormat = "tar.gz"
+	BundleFormatJSON  BundleFormat = "json"
+)
+
+// ParseBundleFormat validates a bundle format name.
+func ParseBundleFormat(s string) (BundleFormat, error) {
+	switch BundleFormat(s) {
+	case BundleFormatTarGz, BundleFormatJSON:
+		return BundleFormat(s), nil
+	default:
+		return "", fmt.Errorf("invalid bundle format %q (expected tar.gz|json)", s)
+	}
+}
+
+// Bundle is a portable snapshot of a plan folder: plan.json, its logs and a
+// copy of the design document it was created from.
+type Bundle struct {
+	FormatVersion int               `json:"formatVersion"`
+	ExportedAt    time.Time         `json:"exportedAt"`
+	Plan          *Plan             `json:"plan"`
+	Files         map[string][]byte `json:"files"` // Plan-folder files keyed by slash-separated relative path
+	Source        *BundleSource     `json:"source,omitempty"`
+}
+
+// BundleSource is a snapshot of the plan's design document.
+type BundleSource struct {
+	Path    string `json:"path"` // Plan.SourceFile at export time
+	Content []byte `json:"content"`
+}
+
+// bundleManifest is stored as manifest.json inside tar.gz bundles.
+type bundleManifest struct {
+	FormatVersion int       `json:"formatVersion"`
+	ExportedAt    time.Time `json:"exportedAt"`
+	SourcePath    string    `json:"sourcePath,omitempty"`
+}
+
+// ExportBundle snapshots the plan folder at planDir. The design document is
+// resolved relative to the repository root that contains .rafa/plans/.
+// Lock files, plan.json backups and temp files are not exported. The plan
+// folder is left untouched, even when plan.json needs a schema migration.
+func ExportBundle(planDir string) (*Bundle, error) {
+	p, err := ReadPlan(planDir)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		FormatVersion: BundleFormatVersion,
+		ExportedAt:    time.Now(),
+		Plan:          p,
+		Files:         make(map[string][]byte),
+	}
+
+	err = filepath.WalkDir(planDir, func(filePath string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(planDir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == sourceSnapshotDir {
+				// Snapshots are exported through Bundle.Source.
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isBundledPlanFile(rel) || !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		b.Files[rel] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan folder: %w", err)
+	}
+
+	if p.SourceFile != "" {
+		repoRoot := filepath.Dir(filepath.Dir(filepath.Dir(planDir)))
+		sourcePath := p.SourceFile
+		if !filepath.IsAbs(sourcePath) {
+			sourcePath = filepath.Join(repoRoot, sourcePath)
+		}
+		// A missing design doc is not fatal; the plan is still self-contained.
+		if content, err := os.ReadFile(sourcePath); err == nil {
+			b.Source = &BundleSource{Path: p.SourceFile, Content: content}
+		}
+	}
+
+	return b, nil
+}
+
+// isBundledPlanFile reports whether a plan-folder file belongs in a bundle.
+func isBundledPlanFile(rel string) bool {
+	name := path.Base(rel)
+	switch {
+	case rel == "plan.json":
+		return false // Stored as Bundle.Plan
+	case name == lockFileName:
+		return false
+	case strings.HasPrefix(name, "plan.json."):
+		return false // Migration backups and temp files
+	}
+	return true
+}
+
+// WriteBundle encodes b to w in the given format.
+func WriteBundle(w io.Writer, b *Bundle, format BundleFormat) error {
+	switch format {
+	case BundleFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b)
+	case BundleFormatTarGz:
+		return writeTarBundle(w, b)
+	default:
+		return fmt.Errorf("invalid bundle format %q", format)
+	}
+}
+
+func writeTarBundle(w io.Writer, b *Bundle) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	addFile := func(name string, data []byte) error {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: b.ExportedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	manifest := bundleManifest{FormatVersion: b.FormatVersion, ExportedAt: b.ExportedAt}
+	if b.Source != nil {
+		manifest.SourcePath = b.Source.Path
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	planData, err := json.MarshalIndent(b.Plan, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := addFile("manifest.json", manifestData); err != nil {
+		return err
+	}
+	if err := addFile("plan.json", planData); err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(b.Files) {
+		if err := addFile("files/"+name, b.Files[name]); err != nil {
+			return err
+		}
+	}
+	if b.Source != nil {
+		if err := addFile("source/"+path.Base(filepath.ToSlash(b.Source.Path)), b.Source.Content); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ReadBundle decodes a bundle written by WriteBundle. The format is detected
+// from the content (gzip magic bytes for tar.gz, otherwise JSON).
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+
+	var b *Bundle
+	var err error
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		b, err = readTarBundle(br)
+	} else {
+		b, err = readJSONBundle(br)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	if b.FormatVersion == 0 || b.FormatVersion > BundleFormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format version %d", b.FormatVersion)
+	}
+	if b.Plan == nil {
+		return nil, errors.New("bundle does not contain a plan")
+	}
+	return b, nil
+}
+
+func readJSONBundle(r io.Reader) (*Bundle, error) {
+	// Decode the plan separately so it goes through schema migration and
+	// unknown-field checks just like plan.json on disk.
+	var raw struct {
+		Bundle
+		Plan json.RawMessage `json:"plan"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	b := raw.Bundle
+	if len(raw.Plan) > 0 && string(raw.Plan) != "null" {
+		p, _, err := decodePlan(raw.Plan)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plan: %w", err)
+		}
+		b.Plan = p
+	}
+	return &b, nil
+}
+
+func readTarBundle(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	b := &Bundle{Files: make(map[string][]byte)}
+	var manifest bundleManifest
+	var sourceContent []byte
+	hasSource := false
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(hdr.Name)
+		switch {
+		case name == "manifest.json":
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return nil, fmt.Errorf("invalid manifest.json: %w", err)
+			}
+		case name == "plan.json":
+			p, _, err := decodePlan(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid plan.json: %w", err)
+			}
+			b.Plan = p
+		case strings.HasPrefix(name, "files/"):
+			b.Files[strings.TrimPrefix(name, "files/")] = data
+		case strings.HasPrefix(name, "source/"):
+			sourceContent = data
+			hasSource = true
+		}
+	}
+
+	b.FormatVersion = manifest.FormatVersion
+	b.ExportedAt = manifest.ExportedAt
+	if hasSource {
+		b.Source = &BundleSource{Path: manifest.SourcePath, Content: sourceContent}
+	}
+	return b, nil
+}
+
+// ImportBundle writes b into a new folder under .rafa/plans/ and returns the
+// folder path. The plan gets a fresh ID and a collision-free name (via
+// ResolvePlanName), and plan_id references in progress.log are rewritten.
+// The design doc snapshot is stored in the plan's source/ folder; if the
+// original SourceFile does not exist in this repository, the plan is pointed
+// at the snapshot instead.
+func ImportBundle(b *Bundle) (string, error) {
+	if b.Plan == nil {
+		return "", errors.New("bundle does not contain a plan")
+	}
+
+	id, err := util.GenerateShortID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate plan ID: %w", err)
+	}
+	name, err := ResolvePlanName(b.Plan.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve plan name: %w", err)
+	}
+
+	p := *b.Plan
+	p.Tasks = append([]Task(nil), b.Plan.Tasks...)
+	oldID := p.ID
+	p.ID = id
+	p.Name = name
+
+	planDir := filepath.Join(rafaDir, plansDir, fmt.Sprintf("%s-%s", p.ID, p.Name))
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create plan folder: %w", err)
+	}
+	// Don't leave a half-written plan behind.
+	if err := writeImportedPlan(planDir, b, &p, oldID); err != nil {
+		os.RemoveAll(planDir)
+		return "", err
+	}
+	return planDir, nil
+}
+
+// writeImportedPlan writes the files of b and the imported plan p into
+// planDir.
+func writeImportedPlan(planDir string, b *Bundle, p *Plan, oldID string) error {
+	for rel, data := range b.Files {
+		target, err := safeJoin(planDir, rel)
+		if err != nil {
+			return err
+		}
+		if rel == progressLogFileName {
+			data = rewriteProgressPlanID(data, oldID, p.ID)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create plan folder: %w", err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+	}
+
+	if b.Source != nil && b.Source.Path != "" {
+		snapshotName := filepath.Base(filepath.FromSlash(b.Source.Path))
+		snapshotPath := filepath.Join(planDir, sourceSnapshotDir, snapshotName)
+		if err := os.MkdirAll(filepath.Dir(snapshotPath), 0755); err != nil {
+			return fmt.Errorf("failed to create source snapshot folder: %w", err)
+		}
+		if err := os.WriteFile(snapshotPath, b.Source.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write source snapshot: %w", err)
+		}
+		if _, err := os.Stat(p.SourceFile); err != nil {
+			p.SourceFile = filepath.ToSlash(snapshotPath)
+		}
+	}
+
+	if err := SavePlan(planDir, p); err != nil {
+		return err
+	}
+	// Ensure the standard log files exist even if the bundle omitted them.
+	for _, logName := range []string{progressLogFileName, OutputLogFileName} {
+		logPath := filepath.Join(planDir, logName)
+		if _, err := os.Stat(logPath); os.IsNotExist(err) {
+			if err := os.WriteFile(logPath, []byte{}, 0644); err != nil {
+				return fmt.Errorf("failed to create %s: %w", logName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// safeJoin joins a bundle-relative path onto dir, rejecting paths that would
+// escape it.
+func safeJoin(dir, rel string) (string, error) {
+	clean := path.Clean("/" + rel)
+	if clean == "/" || rel == "" || strings.Contains(rel, "\\") || clean != "/"+rel {
+		return "", fmt.Errorf("invalid file path in bundle: %q", rel)
+	}
+	return filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(clean, "/"))), nil
+}
+
+// rewriteProgressPlanID replaces oldID with newID in plan_id fields of
+// progress.log lines. Lines that are not valid JSON are kept verbatim.
+func rewriteProgressPlanID(data []byte, oldID, newID string) []byte {
+	if oldID == "" || oldID == newID {
+		return data
+	}
+
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			out.Write(line)
+			continue
+		}
+		var event ProgressEvent
+		if err := json.Unmarshal(line, &event); err != nil || event.Data["plan_id"] != oldID {
+			out.Write(line)
+			continue
+		}
+		event.Data["plan_id"] = newID
+		rewritten, err := json.Marshal(event)
+		if err != nil {
+			out.Write(line)
+			continue
+		}
+		out.Write(rewritten)
+		if bytes.HasSuffix(line, []byte("\n")) {
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
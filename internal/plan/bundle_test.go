@@ -0,0 +1,242 @@
+package plan
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupBundlePlan creates a repo with a design doc and one plan folder under
+// the current working directory and returns the plan folder path.
+func setupBundlePlan(t *testing.T) string {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Join("docs", "designs"), 0755); err != nil {
+		t.Fatalf("failed to create docs dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join("docs", "designs", "feature.md"), []byte("# Feature\n"), 0644); err != nil {
+		t.Fatalf("failed to write design doc: %v", err)
+	}
+
+	planDir := filepath.Join(rafaDir, plansDir, "abc123-feature")
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		t.Fatalf("failed to create plan dir: %v", err)
+	}
+	p := &Plan{
+		ID:         "abc123",
+		Name:       "feature",
+		SourceFile: "docs/designs/feature.md",
+		Status:     PlanStatusCompleted,
+		Tasks: []Task{
+			{ID: "t01", Title: "First", Status: TaskStatusCompleted, Attempts: 1},
+		},
+	}
+	if err := SavePlan(planDir, p); err != nil {
+		t.Fatalf("failed to save plan: %v", err)
+	}
+
+	progress := `{"timestamp":"2026-01-01T00:00:00Z","event":"plan_started","data":{"plan_id":"abc123"}}` + "\n" +
+		`{"timestamp":"2026-01-01T00:00:01Z","event":"task_started","data":{"attempt":1,"task_id":"t01"}}` + "\n"
+	files := map[string]string{
+		progressLogFileName: progress,
+		"output.log":        "=== Task t01, Attempt 1 ===\nhello\n",
+		lockFileName:        "12345",
+		"plan.json.v0.bak":  "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(planDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return planDir
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	for _, format := range []BundleFormat{BundleFormatTarGz, BundleFormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			originalWd, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(originalWd)
+
+			planDir := setupBundlePlan(t)
+
+			exported, err := ExportBundle(planDir)
+			if err != nil {
+				t.Fatalf("ExportBundle: %v", err)
+			}
+			if _, ok := exported.Files[lockFileName]; ok {
+				t.Error("lock file should not be exported")
+			}
+			if _, ok := exported.Files["plan.json.v0.bak"]; ok {
+				t.Error("plan.json backups should not be exported")
+			}
+			if exported.Source == nil || string(exported.Source.Content) != "# Feature\n" {
+				t.Fatalf("expected design doc snapshot, got %+v", exported.Source)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteBundle(&buf, exported, format); err != nil {
+				t.Fatalf("WriteBundle: %v", err)
+			}
+			b, err := ReadBundle(&buf)
+			if err != nil {
+				t.Fatalf("ReadBundle: %v", err)
+			}
+
+			if b.Plan.ID != "abc123" || len(b.Plan.Tasks) != 1 {
+				t.Errorf("unexpected plan after round trip: %+v", b.Plan)
+			}
+			if string(b.Files["output.log"]) != "=== Task t01, Attempt 1 ===\nhello\n" {
+				t.Errorf("output.log = %q", b.Files["output.log"])
+			}
+			if b.Source == nil || b.Source.Path != "docs/designs/feature.md" {
+				t.Errorf("unexpected source after round trip: %+v", b.Source)
+			}
+		})
+	}
+}
+
+func TestImportBundle_RewritesIDsOnCollision(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalWd)
+
+	planDir := setupBundlePlan(t)
+	b, err := ExportBundle(planDir)
+	if err != nil {
+		t.Fatalf("ExportBundle: %v", err)
+	}
+
+	importedDir, err := ImportBundle(b)
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+
+	p, err := LoadPlan(importedDir)
+	if err != nil {
+		t.Fatalf("LoadPlan: %v", err)
+	}
+	if p.ID == "abc123" {
+		t.Error("imported plan should get a new ID")
+	}
+	if p.Name != "feature-2" {
+		t.Errorf("Name = %q, want %q", p.Name, "feature-2")
+	}
+	if filepath.Base(importedDir) != p.ID+"-feature-2" {
+		t.Errorf("unexpected folder name %q", filepath.Base(importedDir))
+	}
+	if p.Tasks[0].Status != TaskStatusCompleted {
+		t.Errorf("task status should be preserved, got %q", p.Tasks[0].Status)
+	}
+
+	progress, err := os.ReadFile(filepath.Join(importedDir, progressLogFileName))
+	if err != nil {
+		t.Fatalf("failed to read progress.log: %v", err)
+	}
+	if strings.Contains(string(progress), "abc123") || !strings.Contains(string(progress), p.ID) {
+		t.Errorf("expected plan_id to be rewritten, got:\n%s", progress)
+	}
+
+	snapshot := filepath.Join(importedDir, sourceSnapshotDir, "feature.md")
+	if _, err := os.Stat(snapshot); err != nil {
+		t.Errorf("expected source snapshot: %v", err)
+	}
+	if p.SourceFile != "docs/designs/feature.md" {
+		t.Errorf("SourceFile should keep the original path when it exists, got %q", p.SourceFile)
+	}
+}
+
+func TestImportBundle_MissingSourceUsesSnapshot(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalWd)
+
+	planDir := setupBundlePlan(t)
+	b, err := ExportBundle(planDir)
+	if err != nil {
+		t.Fatalf("ExportBundle: %v", err)
+	}
+	os.RemoveAll("docs")
+
+	importedDir, err := ImportBundle(b)
+	if err != nil {
+		t.Fatalf("ImportBundle: %v", err)
+	}
+	p, err := LoadPlan(importedDir)
+	if err != nil {
+		t.Fatalf("LoadPlan: %v", err)
+	}
+
+	want := filepath.ToSlash(filepath.Join(importedDir, sourceSnapshotDir, "feature.md"))
+	if p.SourceFile != want {
+		t.Errorf("SourceFile = %q, want %q", p.SourceFile, want)
+	}
+}
+
+func TestImportBundle_RejectsPathEscape(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalWd)
+
+	for _, rel := range []string{"../evil", "/etc/passwd", "a/../../evil", "a\\b", ""} {
+		b := &Bundle{
+			FormatVersion: BundleFormatVersion,
+			Plan:          &Plan{ID: "abc123", Name: "evil", Tasks: []Task{}},
+			Files:         map[string][]byte{rel: []byte("x")},
+		}
+		if _, err := ImportBundle(b); err == nil {
+			t.Errorf("expected error for path %q", rel)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "evil")); !os.IsNotExist(err) {
+		t.Error("file escaped the plan folder")
+	}
+	// Failed imports don't leave half-written plan folders behind.
+	if entries, _ := os.ReadDir(filepath.Join(tmpDir, rafaDir, plansDir)); len(entries) != 0 {
+		t.Errorf("expected no plan folders after failed imports, got %d", len(entries))
+	}
+}
+
+func TestReadBundle_RejectsUnsupportedVersion(t *testing.T) {
+	_, err := ReadBundle(strings.NewReader(`{"formatVersion": 99, "plan": {"id": "x", "name": "y", "tasks": []}}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported bundle format version") {
+		t.Fatalf("expected unsupported version error, got: %v", err)
+	}
+}
+
+func TestReadBundle_ValidatesPlan(t *testing.T) {
+	_, err := ReadBundle(strings.NewReader(`{"formatVersion": 1, "plan": {"id": "x", "name": "y", "tasks": [], "bogus": 1}}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown field "bogus"`) {
+		t.Fatalf("expected unknown field error, got: %v", err)
+	}
+}
+
+func TestExportBundle_DoesNotMigrateSource(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, v0PlanJSON)
+
+	b, err := ExportBundle(dir)
+	if err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+	if b.Plan.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("bundled SchemaVersion = %d, want %d", b.Plan.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "plan.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != v0PlanJSON {
+		t.Error("expected plan.json to be left unchanged")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "plan.json.v0.bak")); !os.IsNotExist(err) {
+		t.Errorf("expected no backup file, got err %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CompletionMarker precedes the JSON completion report in the agent's
+// final message.
+const CompletionMarker = "RAFA_COMPLETION_REPORT"
+
+// CompletionReport is the agent's account of a finished task attempt.
+type CompletionReport struct {
+	CommitMessage string            `json:"commitMessage"`
+	Criteria      []CriterionResult `json:"criteria"`
+	FollowUps     []string          `json:"followUps,omitempty"`
+	Risks         []string          `json:"risks,omitempty"`
+}
+
+// CriterionResult is the agent's verdict on one acceptance criterion.
+type CriterionResult struct {
+	Criterion string `json:"criterion"`
+	Met       bool   `json:"met"`
+	Evidence  string `json:"evidence,omitempty"`
+}
+
+// ParseCompletionReport finds the last completion report in text and
+// decodes it. It returns nil and no error when text has no report. Text
+// after the JSON object and a Markdown code fence around it are ignored.
+func ParseCompletionReport(text string) (*CompletionReport, error) {
+	var report CompletionReport
+	found, err := decodeAfterMarker(text, CompletionMarker, &report)
+	if !found || err != nil {
+		return nil, err
+	}
+	report.CommitMessage = strings.TrimSpace(report.CommitMessage)
+	return &report, nil
+}
+
+// decodeAfterMarker decodes the JSON object following the last marker in
+// text into v. It reports whether text has the marker.
+func decodeAfterMarker(text, marker string, v any) (bool, error) {
+	idx := strings.LastIndex(text, marker)
+	if idx < 0 {
+		return false, nil
+	}
+	rest := text[idx+len(marker):]
+	start := strings.Index(rest, "{")
+	if start < 0 {
+		return true, fmt.Errorf("no JSON object after %s", marker)
+	}
+	if err := json.NewDecoder(strings.NewReader(rest[start:])).Decode(v); err != nil {
+		return true, fmt.Errorf("failed to parse %s: %w", marker, err)
+	}
+	return true, nil
+}
+
+// Verify checks the report against a task's acceptance criteria. Every
+// reported criterion must be met, and every acceptance criterion must be
+// reported. A result is matched to a criterion by its text, ignoring case and
+// surrounding space, or else by its position when the agent reworded it.
+func (r *CompletionReport) Verify(criteria []string) error {
+	var unmet []string
+	for _, c := range r.Criteria {
+		if !c.Met {
+			desc := c.Criterion
+			if c.Evidence != "" {
+				desc += " (" + c.Evidence + ")"
+			}
+			unmet = append(unmet, desc)
+		}
+	}
+	if len(unmet) > 0 {
+		return fmt.Errorf("acceptance criteria not met: %s", strings.Join(unmet, "; "))
+	}
+
+	var missing []string
+	for i, criterion := range criteria {
+		if !r.covers(criterion, i, criteria) {
+			missing = append(missing, criterion)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("completion report doesn't cover %d of %d acceptance criteria: %s",
+			len(missing), len(criteria), strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+// covers reports whether the report has a result for criteria[i]: one with
+// the same text, or the one at position i when its text matches none of the
+// criteria.
+func (r *CompletionReport) covers(criterion string, i int, criteria []string) bool {
+	for _, c := range r.Criteria {
+		if sameCriterion(c.Criterion, criterion) {
+			return true
+		}
+	}
+	if i >= len(r.Criteria) {
+		return false
+	}
+	for _, other := range criteria {
+		if sameCriterion(r.Criteria[i].Criterion, other) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameCriterion(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
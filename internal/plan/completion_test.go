@@ -0,0 +1,95 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCompletionReport(t *testing.T) {
+	text := "Done.\n" + CompletionMarker + "\n```json\n" + `{
+  "commitMessage": "Add login endpoint\n\nChecks the password hash.",
+  "criteria": [{"criterion": "Tests pass", "met": true, "evidence": "go test ./..."}],
+  "followUps": ["Rate limit logins"],
+  "risks": ["Sessions are not revoked"]
+}` + "\n```\nThanks!"
+
+	report, err := ParseCompletionReport(text)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.CommitMessage != "Add login endpoint\n\nChecks the password hash." {
+		t.Errorf("unexpected commit message %q", report.CommitMessage)
+	}
+	if len(report.Criteria) != 1 || !report.Criteria[0].Met || report.Criteria[0].Evidence != "go test ./..." {
+		t.Errorf("unexpected criteria %+v", report.Criteria)
+	}
+	if len(report.FollowUps) != 1 || len(report.Risks) != 1 {
+		t.Errorf("unexpected notes %+v", report)
+	}
+}
+
+func TestParseCompletionReport_TakesLastReport(t *testing.T) {
+	text := CompletionMarker + ` {"commitMessage": "First"}` + "\n" + CompletionMarker + ` {"commitMessage": "Second"}`
+	report, err := ParseCompletionReport(text)
+	if err != nil || report.CommitMessage != "Second" {
+		t.Errorf("expected the last report, got %+v, %v", report, err)
+	}
+}
+
+func TestParseCompletionReport_NoReport(t *testing.T) {
+	report, err := ParseCompletionReport("All done.")
+	if report != nil || err != nil {
+		t.Errorf("expected no report and no error, got %+v, %v", report, err)
+	}
+}
+
+func TestParseCompletionReport_Malformed(t *testing.T) {
+	for _, text := range []string{
+		CompletionMarker,
+		CompletionMarker + ` {"commitMessage": "x", "criteria": [}`,
+		CompletionMarker + ` {"commitMessage": 42}`,
+	} {
+		if _, err := ParseCompletionReport(text); err == nil {
+			t.Errorf("expected an error for %q", text)
+		}
+	}
+}
+
+func TestCompletionReport_Verify(t *testing.T) {
+	criteria := []string{"Tests pass", "Docs updated"}
+
+	met := &CompletionReport{Criteria: []CriterionResult{{Criterion: "Tests pass", Met: true}, {Criterion: "Docs updated", Met: true}}}
+	if err := met.Verify(criteria); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	unmet := &CompletionReport{Criteria: []CriterionResult{
+		{Criterion: "Tests pass", Met: false, Evidence: "2 failures"},
+		{Criterion: "Docs updated", Met: false},
+	}}
+	err := unmet.Verify(criteria)
+	if err == nil || !strings.Contains(err.Error(), "Tests pass (2 failures); Docs updated") {
+		t.Errorf("expected the unmet criteria in the error, got %v", err)
+	}
+
+	partial := &CompletionReport{Criteria: []CriterionResult{{Criterion: "Tests pass", Met: true}}}
+	if err := partial.Verify(criteria); err == nil || !strings.Contains(err.Error(), "cover 1 of 2 acceptance criteria: Docs updated") {
+		t.Errorf("expected an error for unreported criteria, got %v", err)
+	}
+
+	// Reporting one criterion twice doesn't cover the other.
+	repeated := &CompletionReport{Criteria: []CriterionResult{{Criterion: "Tests pass", Met: true}, {Criterion: "tests pass ", Met: true}}}
+	if err := repeated.Verify(criteria); err == nil || !strings.Contains(err.Error(), "Docs updated") {
+		t.Errorf("expected an error for the unreported criterion, got %v", err)
+	}
+
+	// Results are matched by text in any order, or by position when reworded.
+	reordered := &CompletionReport{Criteria: []CriterionResult{{Criterion: "Docs updated", Met: true}, {Criterion: "Tests pass", Met: true}}}
+	if err := reordered.Verify(criteria); err != nil {
+		t.Errorf("expected reordered results to cover the criteria, got %v", err)
+	}
+	reworded := &CompletionReport{Criteria: []CriterionResult{{Criterion: "Tests pass", Met: true}, {Criterion: "README and docs updated", Met: true}}}
+	if err := reworded.Verify(criteria); err != nil {
+		t.Errorf("expected a reworded result to cover its criterion, got %v", err)
+	}
+}
@@ -3,20 +3,29 @@ package plan
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pablasso/rafa/internal/util"
 )
 
 // TaskExtractionResult represents the structured response from AI task extraction.
+// Deterministic importers (see ImportTasks) produce the same structure.
 type TaskExtractionResult struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	Tasks       []ExtractedTask `json:"tasks"`
+	Name        string          `json:"name" yaml:"name"`
+	Description string          `json:"description" yaml:"description"`
+	Tasks       []ExtractedTask `json:"tasks" yaml:"tasks"`
 }
 
 // ExtractedTask represents a single task extracted by the AI.
 type ExtractedTask struct {
-	Title              string   `json:"title"`
-	Description        string   `json:"description"`
-	AcceptanceCriteria []string `json:"acceptanceCriteria"`
+	Title              string   `json:"title" yaml:"title"`
+	Description        string   `json:"description" yaml:"description"`
+	AcceptanceCriteria []string `json:"acceptanceCriteria" yaml:"acceptanceCriteria"`
+	// Section is where in the design doc the task comes from. See
+	// ResolveSections.
+	Section *SectionRef `json:"section,omitempty" yaml:"section,omitempty"`
 }
 
 // Validate checks that the extraction result contains valid data.
@@ -35,3 +44,49 @@ func (r *TaskExtractionResult) Validate() error {
 	}
 	return nil
 }
+
+// NewPlanFromExtraction builds a new plan from an extraction result. It
+// generates the plan and task IDs and resolves the name against existing
+// plans, falling back to the source file name when the result has no name.
+// The plan folder is not created; see CreatePlanFolder.
+func NewPlanFromExtraction(r *TaskExtractionResult, sourceFile string) (*Plan, error) {
+	id, err := util.GenerateShortID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate plan ID: %w", err)
+	}
+
+	baseName := r.Name
+	if baseName == "" {
+		base := filepath.Base(sourceFile)
+		baseName = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	baseName = util.ToKebabCase(baseName)
+
+	name, err := ResolvePlanName(baseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve plan name: %w", err)
+	}
+
+	tasks := make([]Task, len(r.Tasks))
+	for i, et := range r.Tasks {
+		tasks[i] = Task{
+			ID:                 util.GenerateTaskID(i),
+			Title:              et.Title,
+			Description:        et.Description,
+			AcceptanceCriteria: et.AcceptanceCriteria,
+			Section:            et.Section,
+			Status:             TaskStatusPending,
+			Attempts:           0,
+		}
+	}
+
+	return &Plan{
+		ID:          id,
+		Name:        name,
+		Description: r.Description,
+		SourceFile:  sourceFile,
+		CreatedAt:   time.Now(),
+		Status:      PlanStatusNotStarted,
+		Tasks:       tasks,
+	}, nil
+}
@@ -0,0 +1,206 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// archiveDir is the .rafa sub-folder that archived plan folders are moved to.
+const archiveDir = "archive"
+
+// ErrPlanLocked is returned when a housekeeping operation targets a plan that
+// is currently running.
+var ErrPlanLocked = errors.New("plan is running")
+
+// ArchiveDirFor returns the archive folder for the .rafa folder that contains
+// planDir (i.e. <rafa>/archive for <rafa>/plans/<id>-<name>).
+func ArchiveDirFor(planDir string) string {
+	return filepath.Join(filepath.Dir(filepath.Dir(planDir)), archiveDir)
+}
+
+// ArchivePlan moves a plan folder from plans/ to archive/ and returns its new
+// path. Locked plans are never moved.
+func ArchivePlan(planDir string) (string, error) {
+	target := filepath.Join(ArchiveDirFor(planDir), filepath.Base(planDir))
+
+	err := withPlanLock(planDir, func() error {
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("archive already contains %s", filepath.Base(planDir))
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create archive folder: %w", err)
+		}
+		if err := os.Rename(planDir, target); err != nil {
+			return fmt.Errorf("failed to archive plan: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The lock travelled with the folder.
+	if err := NewPlanLock(target).Release(); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// DeletePlan removes a plan folder. Locked plans are never deleted.
+func DeletePlan(planDir string) error {
+	return withPlanLock(planDir, func() error {
+		if err := os.RemoveAll(planDir); err != nil {
+			return fmt.Errorf("failed to delete plan: %w", err)
+		}
+		return nil
+	})
+}
+
+// withPlanLock holds the plan's run lock while fn runs, so a run cannot start
+// in the middle of a housekeeping operation. fn may remove the plan folder.
+func withPlanLock(planDir string, fn func() error) error {
+	if _, err := os.Stat(planDir); err != nil {
+		return fmt.Errorf("plan folder not found: %w", err)
+	}
+
+	lock := NewPlanLock(planDir)
+	if locked, err := lock.IsLocked(); err != nil || locked {
+		return ErrPlanLocked
+	}
+	if err := lock.Acquire(); err != nil {
+		return ErrPlanLocked
+	}
+
+	if err := fn(); err != nil {
+		lock.Release()
+		return err
+	}
+	return lock.Release()
+}
+
+// GCOptions controls which plan output CollectGarbage prunes. A plan's output
+// (output.log plus the per-attempt files in output/) is pruned when its total
+// size exceeds MaxOutputSize or it was last written more than MaxAge ago.
+// Zero values disable the corresponding check.
+type GCOptions struct {
+	MaxOutputSize int64
+	MaxAge        time.Duration
+	DryRun        bool
+	Now           time.Time // Defaults to time.Now()
+}
+
+// GCAction describes plan output that was (or, in a dry run, would be) pruned.
+// Path is the plan's output.log; the output/ folder is removed alongside it.
+type GCAction struct {
+	PlanDir string
+	Path    string
+	Size    int64
+	Reason  string
+}
+
+// GCSkip describes a plan folder that CollectGarbage left alone.
+type GCSkip struct {
+	PlanDir string
+	Reason  string
+}
+
+// GCResult summarizes a CollectGarbage pass.
+type GCResult struct {
+	Pruned     []GCAction
+	Skipped    []GCSkip
+	BytesFreed int64
+}
+
+// CollectGarbage prunes oversized or stale output in the plan folders under
+// each of dirs (typically .rafa/plans and .rafa/archive): output.log is
+// truncated and the per-attempt output/ folder is removed. Locked plans are
+// skipped. Missing directories are ignored.
+func CollectGarbage(dirs []string, opts GCOptions) (*GCResult, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var planDirs []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				planDirs = append(planDirs, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(planDirs)
+
+	result := &GCResult{}
+	for _, planDir := range planDirs {
+		logPath := filepath.Join(planDir, OutputLogFileName)
+		size, modTime := OutputSize(planDir)
+		if size == 0 {
+			continue
+		}
+
+		reason := gcReason(size, modTime, opts, now)
+		if reason == "" {
+			continue
+		}
+
+		action := GCAction{PlanDir: planDir, Path: logPath, Size: size, Reason: reason}
+		if opts.DryRun {
+			if isPlanLocked(planDir) {
+				result.Skipped = append(result.Skipped, GCSkip{PlanDir: planDir, Reason: "plan is running"})
+				continue
+			}
+		} else {
+			err := withPlanLock(planDir, func() error {
+				return pruneOutput(planDir)
+			})
+			if errors.Is(err, ErrPlanLocked) {
+				result.Skipped = append(result.Skipped, GCSkip{PlanDir: planDir, Reason: "plan is running"})
+				continue
+			}
+			if err != nil {
+				result.Skipped = append(result.Skipped, GCSkip{PlanDir: planDir, Reason: err.Error()})
+				continue
+			}
+		}
+		result.Pruned = append(result.Pruned, action)
+		result.BytesFreed += action.Size
+	}
+
+	return result, nil
+}
+
+// pruneOutput truncates output.log and removes the per-attempt output files.
+func pruneOutput(planDir string) error {
+	logPath := filepath.Join(planDir, OutputLogFileName)
+	if err := os.Truncate(logPath, 0); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(planDir, OutputDirName))
+}
+
+func gcReason(size int64, modTime time.Time, opts GCOptions, now time.Time) string {
+	if opts.MaxOutputSize > 0 && size > opts.MaxOutputSize {
+		return fmt.Sprintf("larger than %d bytes", opts.MaxOutputSize)
+	}
+	if opts.MaxAge > 0 && now.Sub(modTime) > opts.MaxAge {
+		return fmt.Sprintf("not written for %s", opts.MaxAge)
+	}
+	return ""
+}
+
+func isPlanLocked(planDir string) bool {
+	locked, err := NewPlanLock(planDir).IsLocked()
+	return err != nil || locked
+}
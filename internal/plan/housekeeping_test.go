@@ -0,0 +1,175 @@
+package plan
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func makePlanDir(t *testing.T, root, folder, output string) string {
+	t.Helper()
+	dir := filepath.Join(root, ".rafa", "plans", folder)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create plan dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plan.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatalf("failed to write plan.json: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "output.log"), []byte(output), 0644); err != nil {
+		t.Fatalf("failed to write output.log: %v", err)
+	}
+	return dir
+}
+
+func lockPlanDir(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, lockFileName), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+}
+
+func TestArchivePlan(t *testing.T) {
+	root := t.TempDir()
+	dir := makePlanDir(t, root, "abc123-feature", "log")
+
+	target, err := ArchivePlan(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := filepath.Join(root, ".rafa", "archive", "abc123-feature")
+	if target != want {
+		t.Errorf("target = %q, want %q", target, want)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("plan folder should be moved out of plans/")
+	}
+	if _, err := os.Stat(filepath.Join(target, "output.log")); err != nil {
+		t.Errorf("expected archived output.log: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, lockFileName)); !os.IsNotExist(err) {
+		t.Error("archived plan should not keep a lock file")
+	}
+
+	// Archiving the same folder name again must not overwrite.
+	dir = makePlanDir(t, root, "abc123-feature", "log")
+	if _, err := ArchivePlan(dir); err == nil {
+		t.Error("expected error when archive already contains the plan")
+	}
+	if _, err := os.Stat(filepath.Join(dir, lockFileName)); !os.IsNotExist(err) {
+		t.Error("failed archive should release the lock")
+	}
+}
+
+func TestArchiveAndDelete_RefuseLockedPlans(t *testing.T) {
+	root := t.TempDir()
+	dir := makePlanDir(t, root, "abc123-feature", "log")
+	lockPlanDir(t, dir)
+
+	if _, err := ArchivePlan(dir); !errors.Is(err, ErrPlanLocked) {
+		t.Errorf("ArchivePlan: expected ErrPlanLocked, got %v", err)
+	}
+	if err := DeletePlan(dir); !errors.Is(err, ErrPlanLocked) {
+		t.Errorf("DeletePlan: expected ErrPlanLocked, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "plan.json")); err != nil {
+		t.Error("locked plan must not be touched")
+	}
+}
+
+func TestDeletePlan(t *testing.T) {
+	root := t.TempDir()
+	dir := makePlanDir(t, root, "abc123-feature", "log")
+
+	if err := DeletePlan(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("plan folder should be deleted")
+	}
+	if err := DeletePlan(dir); err == nil {
+		t.Error("expected error deleting a missing plan")
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+
+	big := makePlanDir(t, root, "aaaaaa-big", "0123456789")
+	old := makePlanDir(t, root, "bbbbbb-old", "x")
+	os.Chtimes(filepath.Join(old, "output.log"), now.Add(-48*time.Hour), now.Add(-48*time.Hour))
+	fresh := makePlanDir(t, root, "cccccc-fresh", "x")
+	locked := makePlanDir(t, root, "dddddd-locked", "0123456789")
+	lockPlanDir(t, locked)
+
+	archived := filepath.Join(root, ".rafa", "archive", "eeeeee-archived")
+	os.MkdirAll(archived, 0755)
+	os.WriteFile(filepath.Join(archived, "output.log"), []byte("0123456789"), 0644)
+
+	dirs := []string{filepath.Join(root, ".rafa", "plans"), filepath.Join(root, ".rafa", "archive")}
+	opts := GCOptions{MaxOutputSize: 5, MaxAge: 24 * time.Hour, Now: now}
+
+	t.Run("dry run", func(t *testing.T) {
+		dry := opts
+		dry.DryRun = true
+		result, err := CollectGarbage(dirs, dry)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Pruned) != 3 || len(result.Skipped) != 1 {
+			t.Fatalf("expected 3 pruned and 1 skipped, got %+v", result)
+		}
+		if info, _ := os.Stat(filepath.Join(big, "output.log")); info.Size() == 0 {
+			t.Error("dry run must not truncate")
+		}
+	})
+
+	t.Run("prunes", func(t *testing.T) {
+		result, err := CollectGarbage(dirs, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.BytesFreed != 21 {
+			t.Errorf("BytesFreed = %d, want 21", result.BytesFreed)
+		}
+
+		sizes := map[string]int64{big: 0, old: 0, archived: 0, fresh: 1, locked: 10}
+		for dir, want := range sizes {
+			info, err := os.Stat(filepath.Join(dir, "output.log"))
+			if err != nil {
+				t.Fatalf("output.log missing in %s: %v", dir, err)
+			}
+			if info.Size() != want {
+				t.Errorf("%s: size = %d, want %d", filepath.Base(dir), info.Size(), want)
+			}
+		}
+		if len(result.Skipped) != 1 || result.Skipped[0].PlanDir != locked {
+			t.Errorf("expected locked plan to be skipped, got %+v", result.Skipped)
+		}
+	})
+}
+
+func TestCollectGarbage_PerAttemptOutput(t *testing.T) {
+	root := t.TempDir()
+	dir := makePlanDir(t, root, "aaaaaa-attempts", "12")
+	writeAttemptFile(t, dir, "output/a.log", "0123456789", false)
+
+	dirs := []string{filepath.Join(root, ".rafa", "plans")}
+	result, err := CollectGarbage(dirs, GCOptions{MaxOutputSize: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Pruned) != 1 || result.BytesFreed != 12 {
+		t.Fatalf("expected output.log and output/ to be pruned together, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, OutputDirName)); !os.IsNotExist(err) {
+		t.Error("output/ folder should be removed")
+	}
+	if info, _ := os.Stat(filepath.Join(dir, OutputLogFileName)); info == nil || info.Size() != 0 {
+		t.Error("output.log should be kept and truncated")
+	}
+}
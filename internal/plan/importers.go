@@ -0,0 +1,357 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImportFormat identifies a hand-written task source that can be turned into
+// a plan without AI extraction.
+type ImportFormat string
+
+const (
+	// ImportFormatMarkdown is a Markdown document whose headings are tasks and
+	// whose "- [ ]" items are acceptance criteria.
+	ImportFormatMarkdown ImportFormat = "markdown"
+	// ImportFormatTaskFile is a YAML or JSON file with the same shape as
+	// TaskExtractionResult.
+	ImportFormatTaskFile ImportFormat = "tasks"
+	// ImportFormatGitHubIssues is a JSON array of issues as exported by
+	// `gh issue list --json number,title,body,state,url` or the REST API.
+	ImportFormatGitHubIssues ImportFormat = "github-issues"
+)
+
+// ParseImportFormat validates an import format name.
+func ParseImportFormat(s string) (ImportFormat, error) {
+	switch ImportFormat(s) {
+	case ImportFormatMarkdown, ImportFormatTaskFile, ImportFormatGitHubIssues:
+		return ImportFormat(s), nil
+	default:
+		return "", fmt.Errorf("invalid import format %q (expected markdown|tasks|github-issues)", s)
+	}
+}
+
+// ImportTasks reads a task source file and converts it into a validated
+// extraction result. If format is empty it is detected from the file
+// extension and, for JSON, from the document shape. The plan name falls back
+// to the file name when the source does not provide one.
+func ImportTasks(path string, format ImportFormat) (*TaskExtractionResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if format == "" {
+		format, err = DetectImportFormat(path, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result *TaskExtractionResult
+	switch format {
+	case ImportFormatMarkdown:
+		result, err = ParseMarkdownChecklist(data)
+	case ImportFormatTaskFile:
+		result, err = ParseTaskFile(data, strings.EqualFold(filepath.Ext(path), ".json"))
+	case ImportFormatGitHubIssues:
+		result, err = ParseGitHubIssues(data)
+	default:
+		return nil, fmt.Errorf("invalid import format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if result.Name == "" {
+		base := filepath.Base(path)
+		result.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if err := result.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan in %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// DetectImportFormat guesses the format of a task source. Markdown and YAML
+// are identified by extension; JSON arrays are treated as GitHub issue
+// exports and JSON objects as task files.
+func DetectImportFormat(path string, data []byte) (ImportFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return ImportFormatMarkdown, nil
+	case ".yaml", ".yml":
+		return ImportFormatTaskFile, nil
+	case ".json":
+		trimmed := bytes.TrimSpace(data)
+		if len(trimmed) > 0 && trimmed[0] == '[' {
+			return ImportFormatGitHubIssues, nil
+		}
+		return ImportFormatTaskFile, nil
+	default:
+		return "", fmt.Errorf("cannot detect import format of %s (use .md, .yaml, .yml or .json)", path)
+	}
+}
+
+var (
+	markdownHeadingRegex   = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	markdownChecklistRegex = regexp.MustCompile(`^\s*[-*+]\s+\[[ xX]\]\s+(.*?)\s*$`)
+	markdownFenceRegex     = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// markdownLine is a classified line of a Markdown document.
+type markdownLine struct {
+	text         string
+	headingLevel int    // 0 when the line is not a heading
+	heading      string // Heading text without the leading #s
+	checklist    string // Item text when the line is a "- [ ]" item
+	isChecklist  bool
+}
+
+// ParseMarkdownChecklist builds an extraction result from a Markdown
+// checklist. Headings at the shallowest level that directly contains
+// "- [ ]" items become tasks when their section has such items; the items
+// become acceptance criteria, and any other text under the heading becomes
+// the task description. A level-1 heading above the task level names the
+// plan. Text before the first task, including sections without items, is
+// the plan description. Sections without items after a task go into the next
+// task's description, or the plan description when no task follows. Lines
+// inside fenced code blocks are kept as description text.
+func ParseMarkdownChecklist(data []byte) (*TaskExtractionResult, error) {
+	lines := classifyMarkdown(string(data))
+
+	// Find the task heading level: the shallowest heading that owns a
+	// checklist item directly.
+	taskLevel := 0
+	currentLevel := 0
+	for i, line := range lines {
+		switch {
+		case line.headingLevel > 0:
+			currentLevel = line.headingLevel
+		case line.isChecklist:
+			if currentLevel == 0 {
+				return nil, fmt.Errorf("line %d: checklist item is not under a heading", i+1)
+			}
+			if taskLevel == 0 || currentLevel < taskLevel {
+				taskLevel = currentLevel
+			}
+		}
+	}
+	if taskLevel == 0 {
+		return nil, errors.New("no checklist items found")
+	}
+
+	// Only task-level headings whose sections have items become tasks.
+	hasItems := make(map[int]bool)
+	section := -1
+	for i, line := range lines {
+		switch {
+		case line.headingLevel > 0 && line.headingLevel <= taskLevel:
+			section = -1
+			if line.headingLevel == taskLevel {
+				section = i
+			}
+		case line.isChecklist && section >= 0:
+			hasItems[section] = true
+		}
+	}
+
+	result := &TaskExtractionResult{}
+	var preamble []string
+	var carried []string // Sections without items, for the next task
+	var current *ExtractedTask
+	var description []string
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Description = joinMarkdownLines(description)
+		result.Tasks = append(result.Tasks, *current)
+		current = nil
+		description = nil
+	}
+	// addLoose keeps text outside any task.
+	addLoose := func(text string) {
+		if len(result.Tasks) == 0 {
+			preamble = append(preamble, text)
+		} else {
+			carried = append(carried, text)
+		}
+	}
+
+	for i, line := range lines {
+		switch {
+		case line.headingLevel == taskLevel && hasItems[i]:
+			flush()
+			current = &ExtractedTask{Title: line.heading}
+			description = carried
+			carried = nil
+		case line.headingLevel == taskLevel:
+			flush()
+			addLoose(line.text)
+		case line.headingLevel > 0 && line.headingLevel < taskLevel:
+			flush()
+			if line.headingLevel == 1 && result.Name == "" {
+				result.Name = line.heading
+				continue
+			}
+			addLoose(line.text)
+		case current == nil:
+			addLoose(line.text)
+		case line.isChecklist:
+			current.AcceptanceCriteria = append(current.AcceptanceCriteria, line.checklist)
+		default:
+			description = append(description, line.text)
+		}
+	}
+	flush()
+
+	result.Description = joinMarkdownLines(append(preamble, carried...))
+	return result, nil
+}
+
+func classifyMarkdown(content string) []markdownLine {
+	rawLines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	lines := make([]markdownLine, len(rawLines))
+	inFence := false
+	for i, raw := range rawLines {
+		lines[i].text = raw
+		if markdownFenceRegex.MatchString(raw) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := markdownHeadingRegex.FindStringSubmatch(raw); m != nil {
+			lines[i].headingLevel = len(m[1])
+			lines[i].heading = m[2]
+		} else if m := markdownChecklistRegex.FindStringSubmatch(raw); m != nil {
+			lines[i].isChecklist = true
+			lines[i].checklist = m[1]
+		}
+	}
+	return lines
+}
+
+// joinMarkdownLines joins lines, trimming surrounding blank lines and
+// collapsing runs of blank lines.
+func joinMarkdownLines(lines []string) string {
+	var out []string
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// ParseTaskFile decodes a YAML or JSON task file with the same fields as
+// TaskExtractionResult. Unknown fields are rejected so typos in field names
+// are not silently dropped.
+func ParseTaskFile(data []byte, isJSON bool) (*TaskExtractionResult, error) {
+	var result TaskExtractionResult
+	if isJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// githubIssue holds the fields we use from `gh issue list --json` and the
+// REST API issue objects.
+type githubIssue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	URL         string          `json:"url"`
+	HTMLURL     string          `json:"html_url"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+// isPullRequest reports whether the issue is a pull request. The REST API
+// marks them with a pull_request object; some exports set it to null on
+// plain issues.
+func (i githubIssue) isPullRequest() bool {
+	raw := bytes.TrimSpace(i.PullRequest)
+	return len(raw) > 0 && !bytes.Equal(raw, []byte("null"))
+}
+
+// ParseGitHubIssues builds an extraction result from a JSON array of GitHub
+// issues. Open issues become tasks in ascending issue-number order; closed
+// issues and pull requests are skipped. Checklist items in an issue body
+// become acceptance criteria. Issues without a checklist get a single
+// criterion requiring the issue to be resolved.
+func ParseGitHubIssues(data []byte) (*TaskExtractionResult, error) {
+	var issues []githubIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Number < issues[j].Number
+	})
+
+	result := &TaskExtractionResult{}
+	for _, issue := range issues {
+		if strings.EqualFold(issue.State, "closed") || issue.isPullRequest() {
+			continue
+		}
+
+		task := ExtractedTask{Title: issue.Title}
+		var description []string
+		for _, line := range classifyMarkdown(issue.Body) {
+			if line.isChecklist {
+				task.AcceptanceCriteria = append(task.AcceptanceCriteria, line.checklist)
+				continue
+			}
+			description = append(description, line.text)
+		}
+
+		url := issue.HTMLURL
+		if url == "" {
+			url = issue.URL
+		}
+		if url != "" {
+			description = append(description, "", fmt.Sprintf("GitHub issue #%d: %s", issue.Number, url))
+		}
+		task.Description = joinMarkdownLines(description)
+
+		if len(task.AcceptanceCriteria) == 0 {
+			task.AcceptanceCriteria = []string{
+				fmt.Sprintf("Issue #%d is resolved as described", issue.Number),
+			}
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	return result, nil
+}
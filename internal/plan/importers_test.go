@@ -0,0 +1,210 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMarkdownChecklist(t *testing.T) {
+	doc := "# Auth Rewrite\n\nMove auth to the new service.\n\n" +
+		"## Add token store\n\nPersist tokens in SQLite.\n\n" +
+		"- [ ] Tokens survive restarts\n- [x] Expired tokens are purged\n\n" +
+		"### Notes\n\n```\n- [ ] not a criterion\n## not a heading\n```\n\n" +
+		"## Wire middleware\n\n* [ ] Requests without a token get 401\n"
+
+	got, err := ParseMarkdownChecklist([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Name != "Auth Rewrite" {
+		t.Errorf("Name = %q", got.Name)
+	}
+	if got.Description != "Move auth to the new service." {
+		t.Errorf("Description = %q", got.Description)
+	}
+	if len(got.Tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d: %+v", len(got.Tasks), got.Tasks)
+	}
+
+	first := got.Tasks[0]
+	if first.Title != "Add token store" {
+		t.Errorf("Title = %q", first.Title)
+	}
+	wantCriteria := []string{"Tokens survive restarts", "Expired tokens are purged"}
+	if !reflect.DeepEqual(first.AcceptanceCriteria, wantCriteria) {
+		t.Errorf("AcceptanceCriteria = %q, want %q", first.AcceptanceCriteria, wantCriteria)
+	}
+	if !strings.Contains(first.Description, "Persist tokens in SQLite.") ||
+		!strings.Contains(first.Description, "- [ ] not a criterion") ||
+		!strings.Contains(first.Description, "### Notes") {
+		t.Errorf("unexpected description:\n%s", first.Description)
+	}
+
+	if got.Tasks[1].AcceptanceCriteria[0] != "Requests without a token get 401" {
+		t.Errorf("unexpected criteria for second task: %q", got.Tasks[1].AcceptanceCriteria)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestParseMarkdownChecklist_TopLevelTasks(t *testing.T) {
+	got, err := ParseMarkdownChecklist([]byte("# One\n- [ ] a\n# Two\n- [ ] b\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "" || len(got.Tasks) != 2 {
+		t.Errorf("expected two unnamed-plan tasks, got %+v", got)
+	}
+}
+
+func TestParseMarkdownChecklist_ProseSections(t *testing.T) {
+	doc := "# My plan\n\n## Overview\n\nWhy we do this.\n\n" +
+		"## Add thing\n\n- [ ] it works\n\n" +
+		"## Background\n\nThe old thing is slow.\n\n" +
+		"## Add other thing\n\n- [ ] it also works\n\n" +
+		"## Open questions\n\nNone yet.\n"
+
+	got, err := ParseMarkdownChecklist([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Tasks) != 2 || got.Tasks[0].Title != "Add thing" || got.Tasks[1].Title != "Add other thing" {
+		t.Fatalf("expected only sections with items to become tasks, got %+v", got.Tasks)
+	}
+	if got.Description != "## Overview\n\nWhy we do this.\n\n## Open questions\n\nNone yet." {
+		t.Errorf("Description = %q", got.Description)
+	}
+	if got.Tasks[1].Description != "## Background\n\nThe old thing is slow." {
+		t.Errorf("expected the prose section in the next task's description, got %q", got.Tasks[1].Description)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestParseMarkdownChecklist_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"no checklist", "# Plan\n\n## Task\n\nJust prose.\n", "no checklist items found"},
+		{"item without heading", "- [ ] orphan\n# Task\n- [ ] ok\n", "line 1: checklist item is not under a heading"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMarkdownChecklist([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseTaskFile(t *testing.T) {
+	yamlDoc := `name: cleanup
+description: Remove dead code
+tasks:
+  - title: Drop legacy flags
+    description: Remove --old
+    acceptanceCriteria:
+      - Flag is gone
+`
+	got, err := ParseTaskFile([]byte(yamlDoc), false)
+	if err != nil {
+		t.Fatalf("yaml: unexpected error: %v", err)
+	}
+	if got.Name != "cleanup" || len(got.Tasks) != 1 || got.Tasks[0].AcceptanceCriteria[0] != "Flag is gone" {
+		t.Errorf("unexpected yaml result: %+v", got)
+	}
+
+	jsonDoc := `{"name": "cleanup", "tasks": [{"title": "Drop", "acceptanceCriteria": ["gone"]}]}`
+	got, err = ParseTaskFile([]byte(jsonDoc), true)
+	if err != nil {
+		t.Fatalf("json: unexpected error: %v", err)
+	}
+	if got.Tasks[0].Title != "Drop" {
+		t.Errorf("unexpected json result: %+v", got)
+	}
+
+	if _, err := ParseTaskFile([]byte("tasks:\n  - title: x\n    criteria: [a]\n"), false); err == nil {
+		t.Error("expected error for unknown yaml field")
+	}
+	if _, err := ParseTaskFile([]byte(`{"tasks": [], "priority": 1}`), true); err == nil {
+		t.Error("expected error for unknown json field")
+	}
+}
+
+func TestParseGitHubIssues(t *testing.T) {
+	issues := `[
+  {"number": 12, "title": "Second", "body": "Fix it.\r\n\r\n- [ ] Works\r\n- [ ] Tested", "state": "OPEN", "url": "https://github.com/o/r/issues/12"},
+  {"number": 3, "title": "First", "body": "", "state": "open", "html_url": "https://github.com/o/r/issues/3"},
+  {"number": 7, "title": "Done", "body": "", "state": "closed"},
+  {"number": 9, "title": "A PR", "body": "", "state": "open", "pull_request": {"url": "x"}},
+  {"number": 20, "title": "Third", "body": "", "state": "open", "pull_request": null}
+]`
+	got, err := ParseGitHubIssues([]byte(issues))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %+v", got.Tasks)
+	}
+	if got.Tasks[0].Title != "First" || got.Tasks[1].Title != "Second" || got.Tasks[2].Title != "Third" {
+		t.Errorf("expected tasks ordered by issue number, got %q, %q, %q", got.Tasks[0].Title, got.Tasks[1].Title, got.Tasks[2].Title)
+	}
+	if got.Tasks[0].AcceptanceCriteria[0] != "Issue #3 is resolved as described" {
+		t.Errorf("unexpected fallback criterion: %q", got.Tasks[0].AcceptanceCriteria)
+	}
+	if !reflect.DeepEqual(got.Tasks[1].AcceptanceCriteria, []string{"Works", "Tested"}) {
+		t.Errorf("unexpected criteria: %q", got.Tasks[1].AcceptanceCriteria)
+	}
+	if got.Tasks[1].Description != "Fix it.\n\nGitHub issue #12: https://github.com/o/r/issues/12" {
+		t.Errorf("unexpected description: %q", got.Tasks[1].Description)
+	}
+}
+
+func TestImportTasks(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	t.Run("detects format and falls back to file name", func(t *testing.T) {
+		path := write("issues.json", `[{"number": 1, "title": "Bug", "state": "open"}]`)
+		got, err := ImportTasks(path, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Name != "issues" || len(got.Tasks) != 1 {
+			t.Errorf("unexpected result: %+v", got)
+		}
+	})
+
+	t.Run("validates result", func(t *testing.T) {
+		path := write("tasks.yaml", "tasks:\n  - title: No criteria\n")
+		_, err := ImportTasks(path, "")
+		if err == nil || !strings.Contains(err.Error(), "missing acceptance criteria") {
+			t.Errorf("expected validation error, got: %v", err)
+		}
+	})
+
+	t.Run("explicit format", func(t *testing.T) {
+		path := write("plan.txt", "## Task\n- [ ] done\n")
+		if _, err := ImportTasks(path, ""); err == nil {
+			t.Error("expected detection error for unknown extension")
+		}
+		if _, err := ImportTasks(path, ImportFormatMarkdown); err != nil {
+			t.Errorf("unexpected error with explicit format: %v", err)
+		}
+	})
+}
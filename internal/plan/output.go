@@ -0,0 +1,307 @@
+package plan
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// OutputLogFileName is the legacy single-file output log. Output written
+	// outside of a task attempt still goes here.
+	OutputLogFileName = "output.log"
+	// OutputDirName is the plan sub-folder holding per-attempt output files.
+	OutputDirName = "output"
+
+	compressedSuffix = ".gz"
+)
+
+// Attempt statuses reported by ListAttemptOutputs and ProgressReader.
+const (
+	AttemptStatusRunning     = "running"
+	AttemptStatusCompleted   = "completed"
+	AttemptStatusFailed      = "failed"
+	AttemptStatusInterrupted = "interrupted" // Cut short by the end of its run
+	AttemptStatusSkipped     = "skipped"     // Stopped by a skip request
+)
+
+// AttemptOutput describes the captured output of one task attempt, as indexed
+// by the output_file field of task_started events in progress.log.
+type AttemptOutput struct {
+	TaskID    string
+	Attempt   int
+	File      string // Plan-relative, slash-separated path of the uncompressed file
+	StartedAt time.Time
+	Status    string
+}
+
+// NewAttemptOutputFile returns the plan-relative path for a new attempt's
+// output file. Names start with the UTC start time so a directory listing is
+// in chronological order.
+func NewAttemptOutputFile(taskID string, attempt int, started time.Time) string {
+	safeID := strings.NewReplacer("/", "_", "\\", "_").Replace(taskID)
+	name := fmt.Sprintf("%s-%s-attempt%d.log", started.UTC().Format("20060102T150405.000Z"), safeID, attempt)
+	return path.Join(OutputDirName, name)
+}
+
+// CompressOutputFile gzips the file at filePath to filePath+".gz" and removes
+// the original.
+func CompressOutputFile(filePath string) error {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dstPath := filePath + compressedSuffix
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(dstPath)
+		return fmt.Errorf("failed to compress %s: %w", filePath, err)
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return fmt.Errorf("failed to compress %s: %w", filePath, err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("failed to compress %s: %w", filePath, err)
+	}
+
+	src.Close()
+	return os.Remove(filePath)
+}
+
+// ListAttemptOutputs returns the attempts recorded in progress.log that have
+// a per-attempt output file, in the order they were started. Statuses are
+// those of SummarizeProgress. Plans that only used the legacy output.log
+// return an empty list.
+func ListAttemptOutputs(planDir string) ([]AttemptOutput, error) {
+	events, err := ReadProgressEvents(planDir)
+	if err != nil {
+		return nil, err
+	}
+	return AttemptOutputs(SummarizeProgress(events)), nil
+}
+
+// AttemptOutputs returns the attempts in stats that have a per-attempt output
+// file, in the order they were started.
+func AttemptOutputs(stats *ProgressStats) []AttemptOutput {
+	var attempts []AttemptOutput
+	for _, t := range stats.Tasks {
+		for _, a := range t.Attempts {
+			if a.OutputFile == "" {
+				continue
+			}
+			attempts = append(attempts, AttemptOutput{
+				TaskID:    t.TaskID,
+				Attempt:   a.Attempt,
+				File:      a.OutputFile,
+				StartedAt: a.Started,
+				Status:    a.Status,
+			})
+		}
+	}
+	// Tasks are grouped by task; restore the start order.
+	sort.SliceStable(attempts, func(i, j int) bool {
+		return attempts[i].StartedAt.Before(attempts[j].StartedAt)
+	})
+	return attempts
+}
+
+// OpenAttemptOutput opens an attempt's output file, transparently
+// decompressing it if the attempt has been compressed.
+func OpenAttemptOutput(planDir string, a AttemptOutput) (io.ReadCloser, error) {
+	resolved, ok := resolveOutputFile(planDir, a.File)
+	if !ok {
+		return nil, fmt.Errorf("output for task %s attempt %d not found: %w", a.TaskID, a.Attempt, os.ErrNotExist)
+	}
+	return openOutputFile(resolved)
+}
+
+// OpenOutputLog returns a reader over all captured output of a plan in the
+// single-file output.log format: the legacy output.log (if any) followed by
+// every per-attempt file in start order. Attempt files carry their own task
+// header and footer lines, so existing output.log parsers work unchanged.
+// Attempt files that are not indexed in progress.log are appended in name
+// (i.e. chronological) order; missing files are skipped.
+func OpenOutputLog(planDir string) (io.ReadCloser, error) {
+	attempts, err := ListAttemptOutputs(planDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress log: %w", err)
+	}
+	return OpenOutputs(planDir, attempts)
+}
+
+// OpenOutputs is OpenOutputLog for callers that have already listed the
+// plan's attempts, such as with AttemptOutputs.
+func OpenOutputs(planDir string, attempts []AttemptOutput) (io.ReadCloser, error) {
+	var files []string
+
+	legacy := filepath.Join(planDir, OutputLogFileName)
+	if _, err := os.Stat(legacy); err == nil {
+		files = append(files, legacy)
+	}
+
+	seen := make(map[string]bool)
+	for _, a := range attempts {
+		if resolved, ok := resolveOutputFile(planDir, a.File); ok && !seen[a.File] {
+			files = append(files, resolved)
+			seen[a.File] = true
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(planDir, OutputDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var unindexed []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		rel := path.Join(OutputDirName, strings.TrimSuffix(entry.Name(), compressedSuffix))
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		if resolved, ok := resolveOutputFile(planDir, rel); ok {
+			unindexed = append(unindexed, resolved)
+		}
+	}
+	sort.Strings(unindexed)
+	files = append(files, unindexed...)
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no output found in %s: %w", planDir, os.ErrNotExist)
+	}
+	return &outputLogReader{files: files}, nil
+}
+
+// OutputSize returns the total on-disk size of a plan's output (output.log
+// plus per-attempt files) and the most recent modification time.
+func OutputSize(planDir string) (int64, time.Time) {
+	var size int64
+	var newest time.Time
+	add := func(info os.FileInfo) {
+		size += info.Size()
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+
+	if info, err := os.Stat(filepath.Join(planDir, OutputLogFileName)); err == nil {
+		add(info)
+	}
+	entries, _ := os.ReadDir(filepath.Join(planDir, OutputDirName))
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && !entry.IsDir() {
+			add(info)
+		}
+	}
+	return size, newest
+}
+
+// resolveOutputFile returns the on-disk path for a plan-relative output
+// file, preferring the compressed copy.
+func resolveOutputFile(planDir, rel string) (string, bool) {
+	if rel == "" || strings.Contains(rel, "..") {
+		return "", false
+	}
+	full := filepath.Join(planDir, filepath.FromSlash(rel))
+	if _, err := os.Stat(full + compressedSuffix); err == nil {
+		return full + compressedSuffix, true
+	}
+	if _, err := os.Stat(full); err == nil {
+		return full, true
+	}
+	return "", false
+}
+
+// openOutputFile opens a possibly-gzipped output file.
+func openOutputFile(filePath string) (io.ReadCloser, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filePath, compressedSuffix) {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to decompress %s: %w", filePath, err)
+	}
+	return &gzipFile{Reader: gz, file: f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// outputLogReader concatenates output files, opening each one lazily.
+type outputLogReader struct {
+	files   []string
+	current io.ReadCloser
+}
+
+func (r *outputLogReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.files) == 0 {
+				return 0, io.EOF
+			}
+			rc, err := openOutputFile(r.files[0])
+			r.files = r.files[1:]
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue // Pruned between listing and reading
+				}
+				return 0, err
+			}
+			r.current = rc
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *outputLogReader) Close() error {
+	r.files = nil
+	if r.current != nil {
+		err := r.current.Close()
+		r.current = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package plan
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeAttemptFile(t *testing.T, planDir, rel, content string, compress bool) {
+	t.Helper()
+	full := filepath.Join(planDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatalf("failed to create output dir: %v", err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", rel, err)
+	}
+	if compress {
+		if err := CompressOutputFile(full); err != nil {
+			t.Fatalf("CompressOutputFile() error: %v", err)
+		}
+	}
+}
+
+func readAll(t *testing.T, rc io.ReadCloser, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	return string(data)
+}
+
+func TestNewAttemptOutputFile(t *testing.T) {
+	started := time.Date(2024, 1, 15, 10, 30, 0, 123000000, time.FixedZone("X", 3600))
+	got := NewAttemptOutputFile("t01", 2, started)
+	want := "output/20240115T093000.123Z-t01-attempt2.log"
+	if got != want {
+		t.Errorf("NewAttemptOutputFile() = %q, want %q", got, want)
+	}
+
+	if got := NewAttemptOutputFile("a/b", 1, started); strings.Count(got, "/") != 1 {
+		t.Errorf("task ID separators should be replaced, got %q", got)
+	}
+}
+
+func TestCompressOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "attempt.log")
+	os.WriteFile(path, []byte("hello\n"), 0644)
+
+	if err := CompressOutputFile(path); err != nil {
+		t.Fatalf("CompressOutputFile() error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("original file should be removed")
+	}
+	rc, err := openOutputFile(path + compressedSuffix)
+	if got := readAll(t, rc, err); got != "hello\n" {
+		t.Errorf("decompressed content = %q", got)
+	}
+}
+
+func TestListAttemptOutputs(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewProgressLogger(dir)
+	logger.TaskStarted("t00", 1, TaskStartedInfo{}) // Legacy event without an output file
+	logger.TaskStarted("t01", 1, TaskStartedInfo{OutputFile: "output/a.log"})
+	logger.TaskFailed("t01", 1)
+	logger.TaskStarted("t01", 2, TaskStartedInfo{OutputFile: "output/b.log"})
+	logger.TaskCompleted("t01", TaskCommit{})
+	// A run that skips an attempt and ends during the next one.
+	logger.PlanStarted("p1", "")
+	logger.TaskStarted("t03", 1, TaskStartedInfo{OutputFile: "output/d.log"})
+	logger.TaskSkipped("t03", 1)
+	logger.TaskStarted("t04", 1, TaskStartedInfo{OutputFile: "output/e.log"})
+	logger.PlanCancelled("t04")
+	logger.TaskStarted("t02", 1, TaskStartedInfo{OutputFile: "output/c.log"})
+
+	attempts, err := ListAttemptOutputs(dir)
+	if err != nil {
+		t.Fatalf("ListAttemptOutputs() error: %v", err)
+	}
+
+	want := []AttemptOutput{
+		{TaskID: "t01", Attempt: 1, File: "output/a.log", Status: AttemptStatusFailed},
+		{TaskID: "t01", Attempt: 2, File: "output/b.log", Status: AttemptStatusCompleted},
+		{TaskID: "t03", Attempt: 1, File: "output/d.log", Status: AttemptStatusSkipped},
+		{TaskID: "t04", Attempt: 1, File: "output/e.log", Status: AttemptStatusInterrupted},
+		{TaskID: "t02", Attempt: 1, File: "output/c.log", Status: AttemptStatusRunning},
+	}
+	if len(attempts) != len(want) {
+		t.Fatalf("expected %d attempts, got %+v", len(want), attempts)
+	}
+	for i, a := range attempts {
+		a.StartedAt = time.Time{}
+		if a != want[i] {
+			t.Errorf("attempt %d = %+v, want %+v", i, a, want[i])
+		}
+	}
+
+	empty, err := ListAttemptOutputs(t.TempDir())
+	if err != nil || len(empty) != 0 {
+		t.Errorf("expected no attempts without progress.log, got %+v, %v", empty, err)
+	}
+}
+
+func TestOpenOutputLog(t *testing.T) {
+	t.Run("combines legacy, indexed and unindexed files", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, OutputLogFileName), []byte("legacy\n"), 0644)
+
+		logger := NewProgressLogger(dir)
+		logger.TaskStarted("t01", 1, TaskStartedInfo{OutputFile: "output/2-t01-attempt1.log"})
+		logger.TaskFailed("t01", 1)
+		logger.TaskStarted("t01", 2, TaskStartedInfo{OutputFile: "output/1-t01-attempt2.log"})
+		logger.TaskStarted("t02", 1, TaskStartedInfo{OutputFile: "output/missing.log"})
+
+		writeAttemptFile(t, dir, "output/2-t01-attempt1.log", "first\n", true)
+		writeAttemptFile(t, dir, "output/1-t01-attempt2.log", "second\n", false)
+		writeAttemptFile(t, dir, "output/3-stray.log", "stray\n", true)
+
+		rc, err := OpenOutputLog(dir)
+		got := readAll(t, rc, err)
+		if want := "legacy\nfirst\nsecond\nstray\n"; got != want {
+			t.Errorf("OpenOutputLog() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("attempt files only", func(t *testing.T) {
+		dir := t.TempDir()
+		writeAttemptFile(t, dir, "output/a.log", "only\n", true)
+
+		rc, err := OpenOutputLog(dir)
+		if got := readAll(t, rc, err); got != "only\n" {
+			t.Errorf("OpenOutputLog() = %q", got)
+		}
+	})
+
+	t.Run("no output", func(t *testing.T) {
+		_, err := OpenOutputLog(t.TempDir())
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected os.ErrNotExist, got %v", err)
+		}
+	})
+}
+
+func TestOpenAttemptOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeAttemptFile(t, dir, "output/a.log", "attempt\n", true)
+
+	rc, err := OpenAttemptOutput(dir, AttemptOutput{TaskID: "t01", Attempt: 1, File: "output/a.log"})
+	if got := readAll(t, rc, err); got != "attempt\n" {
+		t.Errorf("OpenAttemptOutput() = %q", got)
+	}
+
+	_, err = OpenAttemptOutput(dir, AttemptOutput{TaskID: "t01", Attempt: 2, File: "../plan.json"})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected paths outside the plan to be rejected, got %v", err)
+	}
+}
+
+func TestOutputSize(t *testing.T) {
+	dir := t.TempDir()
+	if size, _ := OutputSize(dir); size != 0 {
+		t.Errorf("OutputSize() of empty plan = %d", size)
+	}
+
+	os.WriteFile(filepath.Join(dir, OutputLogFileName), []byte("12345"), 0644)
+	writeAttemptFile(t, dir, "output/a.log", "123", false)
+
+	size, modTime := OutputSize(dir)
+	if size != 8 {
+		t.Errorf("OutputSize() = %d, want 8", size)
+	}
+	if modTime.IsZero() {
+		t.Error("expected a modification time")
+	}
+}
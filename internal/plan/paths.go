@@ -0,0 +1,148 @@
+package plan
+
+import (
+	"path"
+	"strings"
+)
+
+// Path policy violation actions.
+const (
+	PathViolationFail   = "fail"
+	PathViolationRevert = "revert"
+)
+
+// PathPolicy limits the repository paths a task's attempt may change.
+// Patterns are slash-separated globs relative to the repository root, where
+// ** matches any number of directories. A pattern without a slash, such as
+// package-lock.json or *.lock, matches the file name in any directory.
+//
+// The pattern lists aren't omitted when empty, so an empty list that
+// overrides a less specific policy survives saving plan.json.
+type PathPolicy struct {
+	// Allowed are the paths that may change. Empty allows every path that
+	// isn't forbidden.
+	Allowed []string `json:"allowed"`
+	// Forbidden are paths that may not change, even when allowed.
+	Forbidden []string `json:"forbidden"`
+	// OnViolation is PathViolationFail (the default), which fails the
+	// attempt, or PathViolationRevert, which undoes the offending changes
+	// and keeps the rest.
+	OnViolation string `json:"onViolation,omitempty"`
+}
+
+// PathViolation is a changed path the policy doesn't permit.
+type PathViolation struct {
+	Path    string
+	Pattern string // The forbidden pattern it matched; empty when not allowed
+}
+
+// String describes the violation for failure messages.
+func (v PathViolation) String() string {
+	if v.Pattern != "" {
+		return v.Path + " (forbidden by " + v.Pattern + ")"
+	}
+	return v.Path + " (not allowed)"
+}
+
+// ResolvePaths returns the effective path policy from policies ordered from
+// least to most specific, such as the project configuration, the plan and
+// the task; nil policies are skipped. Set fields of a more specific policy
+// override, so a task may lift a plan's restriction when that is its job.
+func ResolvePaths(policies ...*PathPolicy) PathPolicy {
+	var p PathPolicy
+	for _, o := range policies {
+		if o == nil {
+			continue
+		}
+		if o.Allowed != nil {
+			p.Allowed = o.Allowed
+		}
+		if o.Forbidden != nil {
+			p.Forbidden = o.Forbidden
+		}
+		if o.OnViolation != "" {
+			p.OnViolation = o.OnViolation
+		}
+	}
+	return p
+}
+
+// IsZero reports whether the policy permits every change.
+func (p PathPolicy) IsZero() bool {
+	return len(p.Allowed) == 0 && len(p.Forbidden) == 0
+}
+
+// Revert reports whether violations are reverted rather than failing the
+// attempt.
+func (p PathPolicy) Revert() bool {
+	return p.OnViolation == PathViolationRevert
+}
+
+// Check returns the changed paths the policy doesn't permit, in order.
+func (p PathPolicy) Check(paths []string) []PathViolation {
+	var violations []PathViolation
+	for _, name := range paths {
+		if pattern, ok := matchAny(p.Forbidden, name); ok {
+			violations = append(violations, PathViolation{Path: name, Pattern: pattern})
+			continue
+		}
+		if len(p.Allowed) > 0 {
+			if _, ok := matchAny(p.Allowed, name); !ok {
+				violations = append(violations, PathViolation{Path: name})
+			}
+		}
+	}
+	return violations
+}
+
+// matchAny returns the first of patterns matching name.
+func matchAny(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if MatchPath(pattern, name) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// MatchPath reports whether the repository path name matches pattern. A
+// pattern matching a directory also matches everything under it.
+func MatchPath(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	name = strings.TrimSuffix(name, "/")
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		pattern = "**/" + pattern
+	}
+	patternParts := strings.Split(strings.TrimSuffix(pattern, "/"), "/")
+	nameParts := strings.Split(name, "/")
+	// Match a directory pattern against the name's leading directories too.
+	for n := len(nameParts); n > 0; n-- {
+		if matchParts(patternParts, nameParts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchParts matches path segments, with ** standing for any number of
+// them.
+func matchParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchParts(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,93 @@
+package plan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{".github/**", ".github/workflows/ci.yml", true},
+		{".github/**", "docs/.github/x", false},
+		{"migrations/**", "migrations/001_init.sql", true},
+		{"migrations", "migrations/001_init.sql", true},
+		{"internal/*.go", "internal/a.go", true},
+		{"internal/*.go", "internal/sub/a.go", false},
+		{"internal/**/*.go", "internal/a.go", true},
+		{"internal/**/*.go", "internal/sub/deep/a.go", true},
+		{"package-lock.json", "web/package-lock.json", true},
+		{"*.lock", "Cargo.lock", true},
+		{"./vendor/**", "vendor/github.com/x/y.go", true},
+		{"vendor/**", "vendored/y.go", false},
+		{"src/", "src/main.go", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPathPolicy_Check(t *testing.T) {
+	p := PathPolicy{
+		Allowed:   []string{"internal/**", "go.mod", "go.sum"},
+		Forbidden: []string{"internal/generated/**", "go.sum"},
+	}
+	got := p.Check([]string{"internal/a.go", "internal/generated/b.go", "go.sum", "README.md", "go.mod"})
+	want := []PathViolation{
+		{Path: "internal/generated/b.go", Pattern: "internal/generated/**"},
+		{Path: "go.sum", Pattern: "go.sum"},
+		{Path: "README.md"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %+v, want %+v", got, want)
+	}
+	if s := got[0].String(); s != "internal/generated/b.go (forbidden by internal/generated/**)" {
+		t.Errorf("unexpected description %q", s)
+	}
+	if s := got[2].String(); s != "README.md (not allowed)" {
+		t.Errorf("unexpected description %q", s)
+	}
+
+	if v := (PathPolicy{}).Check([]string{"anything"}); v != nil {
+		t.Errorf("expected an empty policy to permit every change, got %+v", v)
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	project := &PathPolicy{Forbidden: []string{".github/**"}}
+	planPolicy := &PathPolicy{Allowed: []string{"src/**"}, OnViolation: PathViolationRevert}
+	task := &PathPolicy{Forbidden: []string{}}
+
+	got := ResolvePaths(project, planPolicy, nil)
+	if !reflect.DeepEqual(got.Forbidden, []string{".github/**"}) || !reflect.DeepEqual(got.Allowed, []string{"src/**"}) || !got.Revert() {
+		t.Errorf("unexpected policy: %+v", got)
+	}
+
+	// A task can lift a restriction by setting the field, even to empty.
+	got = ResolvePaths(project, planPolicy, task)
+	if len(got.Forbidden) != 0 || len(got.Allowed) != 1 {
+		t.Errorf("expected the task to override forbidden paths, got %+v", got)
+	}
+	if !ResolvePaths().IsZero() {
+		t.Error("expected no policies to resolve to the zero policy")
+	}
+}
+
+func TestPathPolicy_EmptyOverrideSurvivesSave(t *testing.T) {
+	dir := t.TempDir()
+	p := &Plan{ID: "p1", Tasks: []Task{{ID: "t01", Paths: &PathPolicy{Forbidden: []string{}}}}}
+	if err := SavePlan(dir, p); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPlan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths := loaded.Tasks[0].Paths; paths == nil || paths.Forbidden == nil || paths.Allowed != nil {
+		t.Errorf("expected the empty forbidden list to be kept, got %+v", paths)
+	}
+}
@@ -4,13 +4,18 @@ import "time"
 
 // Plan represents a collection of tasks extracted from a source document.
 type Plan struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	SourceFile  string    `json:"sourceFile"`
-	CreatedAt   time.Time `json:"createdAt"`
-	Status      string    `json:"status"`
-	Tasks       []Task    `json:"tasks"`
+	SchemaVersion int       `json:"schemaVersion"`
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	SourceFile    string    `json:"sourceFile"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Status        string    `json:"status"`
+	// Agent sets the agent options for every task of the plan.
+	Agent *AgentConfig `json:"agent,omitempty"`
+	// Paths limits what every task of the plan may change.
+	Paths *PathPolicy `json:"paths,omitempty"`
+	Tasks []Task      `json:"tasks"`
 }
 
 // Plan status constants
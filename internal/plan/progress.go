@@ -1,6 +1,7 @@
 package plan
 
 import (
+	"bufio"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -18,6 +19,7 @@ const (
 	EventTaskStarted   = "task_started"
 	EventTaskCompleted = "task_completed"
 	EventTaskFailed    = "task_failed"
+	EventTaskSkipped   = "task_skipped"
 )
 
 // ProgressEvent represents a single progress log entry.
@@ -27,6 +29,73 @@ type ProgressEvent struct {
 	Data      map[string]interface{} `json:"data,omitempty"`
 }
 
+// ReadProgressEvents returns the events in a plan's progress.log in the order
+// they were written. Malformed lines are skipped; a missing log yields no
+// events.
+func ReadProgressEvents(planDir string) ([]ProgressEvent, error) {
+	f, err := os.Open(filepath.Join(planDir, progressLogFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []ProgressEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event ProgressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// TaskCommit is what a task_completed event records about the task's commit.
+type TaskCommit struct {
+	Message string
+	Files   []string
+}
+
+// TaskCommits returns the commit recorded by the last task_completed event of
+// each task in events. Tasks completed without a recorded commit are left
+// out, as are completions a later task_failed event supersedes (the commit
+// itself failed).
+func TaskCommits(events []ProgressEvent) map[string]TaskCommit {
+	commits := make(map[string]TaskCommit)
+	for _, event := range events {
+		taskID, _ := event.Data["task_id"].(string)
+		if event.Event == EventTaskFailed {
+			delete(commits, taskID)
+			continue
+		}
+		if event.Event != EventTaskCompleted {
+			continue
+		}
+		message, _ := event.Data["commit_message"].(string)
+		var files []string
+		if list, ok := event.Data["files"].([]interface{}); ok {
+			for _, f := range list {
+				if name, ok := f.(string); ok {
+					files = append(files, name)
+				}
+			}
+		}
+		if taskID == "" || (message == "" && len(files) == 0) {
+			continue
+		}
+		commits[taskID] = TaskCommit{Message: message, Files: files}
+	}
+	return commits
+}
+
 // ProgressLogger writes progress events to a JSON Lines file.
 type ProgressLogger struct {
 	path string
@@ -63,26 +132,77 @@ func (p *ProgressLogger) Log(event string, data map[string]interface{}) error {
 	return err
 }
 
-// PlanStarted logs a plan_started event.
-func (p *ProgressLogger) PlanStarted(planID string) error {
-	return p.Log(EventPlanStarted, map[string]interface{}{
+// PlanStarted logs a plan_started event that also records the agent backend
+// running the plan. An empty backend is not recorded.
+func (p *ProgressLogger) PlanStarted(planID, backend string) error {
+	data := map[string]interface{}{
 		"plan_id": planID,
-	})
+	}
+	if backend != "" {
+		data["backend"] = backend
+	}
+	return p.Log(EventPlanStarted, data)
+}
+
+// TaskStartedInfo is what a task_started event records about the attempt
+// besides the task and attempt number. Zero fields are not recorded.
+type TaskStartedInfo struct {
+	// OutputFile is the plan-relative path of the attempt's output file (see
+	// ListAttemptOutputs).
+	OutputFile string
+	// Agent is the attempt's effective agent settings. Environment variables
+	// are recorded by name only, since they may hold secrets.
+	Agent AgentSettings
 }
 
 // TaskStarted logs a task_started event.
-func (p *ProgressLogger) TaskStarted(taskID string, attempt int) error {
-	return p.Log(EventTaskStarted, map[string]interface{}{
+func (p *ProgressLogger) TaskStarted(taskID string, attempt int, info TaskStartedInfo) error {
+	data := map[string]interface{}{
 		"task_id": taskID,
 		"attempt": attempt,
-	})
+	}
+	if info.OutputFile != "" {
+		data["output_file"] = info.OutputFile
+	}
+	if agent := info.Agent; !agent.IsZero() {
+		settings := map[string]interface{}{}
+		if agent.Model != "" {
+			settings["model"] = agent.Model
+		}
+		if agent.MaxTurns != 0 {
+			settings["max_turns"] = agent.MaxTurns
+		}
+		if agent.AllowedTools != nil {
+			settings["allowed_tools"] = agent.AllowedTools
+		}
+		if agent.DisallowedTools != nil {
+			settings["disallowed_tools"] = agent.DisallowedTools
+		}
+		if agent.ExtraArgs != nil {
+			settings["extra_args"] = agent.ExtraArgs
+		}
+		if len(agent.Env) > 0 {
+			settings["env"] = agent.EnvNames()
+		}
+		data["agent"] = settings
+	}
+	return p.Log(EventTaskStarted, data)
 }
 
-// TaskCompleted logs a task_completed event.
-func (p *ProgressLogger) TaskCompleted(taskID string) error {
-	return p.Log(EventTaskCompleted, map[string]interface{}{
+// TaskCompleted logs a task_completed event that also records the task's
+// commit message and the files it changed, which later task prompts
+// summarize. A zero commit is not recorded.
+func (p *ProgressLogger) TaskCompleted(taskID string, commit TaskCommit) error {
+	data := map[string]interface{}{
 		"task_id": taskID,
-	})
+	}
+	if commit.Message != "" || len(commit.Files) > 0 {
+		data["commit_message"] = commit.Message
+	}
+	if len(commit.Files) > 0 {
+		data["files"] = commit.Files
+	}
+	return p.Log(EventTaskCompleted, data)
 }
 
 // TaskFailed logs a task_failed event.
@@ -93,6 +213,15 @@ func (p *ProgressLogger) TaskFailed(taskID string, attempt int) error {
 	})
 }
 
+// TaskSkipped logs a task_skipped event: the attempt was stopped on request
+// and the task left pending.
+func (p *ProgressLogger) TaskSkipped(taskID string, attempt int) error {
+	return p.Log(EventTaskSkipped, map[string]interface{}{
+		"task_id": taskID,
+		"attempt": attempt,
+	})
+}
+
 // PlanCompleted logs a plan_completed event with summary statistics.
 func (p *ProgressLogger) PlanCompleted(totalTasks, succeededTasks int, duration time.Duration) error {
 	return p.Log(EventPlanCompleted, map[string]interface{}{
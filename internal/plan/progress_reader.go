@@ -0,0 +1,263 @@
+package plan
+
+import (
+	"sort"
+	"time"
+)
+
+// Run outcomes reported by ProgressReader.
+const (
+	RunOutcomeCompleted   = "completed"
+	RunOutcomeFailed      = "failed"
+	RunOutcomeCancelled   = "cancelled"
+	RunOutcomeInterrupted = "interrupted" // No terminal event: crashed, killed or still running
+)
+
+// AttemptStats is the timing and outcome of one task attempt.
+type AttemptStats struct {
+	Attempt  int
+	Started  time.Time
+	Ended    time.Time
+	Duration time.Duration
+	Status   string // One of the AttemptStatus constants
+	Run      int    // Index into ProgressStats.Runs, or -1 if started outside a run
+	// OutputFile is the plan-relative path of the attempt's output file, or
+	// empty in logs that predate per-attempt output.
+	OutputFile string
+}
+
+// TaskStats aggregates the attempts of one task across every run.
+type TaskStats struct {
+	TaskID   string
+	Attempts []AttemptStats
+	Failures int
+	// AttemptsToSuccess is how many attempts the task took to succeed, or 0
+	// if it has not succeeded.
+	AttemptsToSuccess int
+	Duration          time.Duration // Sum of attempt durations
+}
+
+// Succeeded reports whether the task has a successful attempt.
+func (t TaskStats) Succeeded() bool {
+	return t.AttemptsToSuccess > 0
+}
+
+// RunStats is one execution of the plan, from plan_started to its terminal
+// event.
+type RunStats struct {
+	Started  time.Time
+	Ended    time.Time
+	Duration time.Duration
+	Outcome  string
+	Backend  string // Agent backend from plan_started; empty in logs that predate it
+}
+
+// ProgressStats summarizes a plan's progress.log.
+type ProgressStats struct {
+	Runs  []RunStats
+	Tasks []TaskStats // In the order tasks were first started
+}
+
+// WallTime returns the total duration of all runs.
+func (s *ProgressStats) WallTime() time.Duration {
+	var total time.Duration
+	for _, r := range s.Runs {
+		total += r.Duration
+	}
+	return total
+}
+
+// TotalAttempts returns the number of task attempts across all runs.
+func (s *ProgressStats) TotalAttempts() int {
+	total := 0
+	for _, t := range s.Tasks {
+		total += len(t.Attempts)
+	}
+	return total
+}
+
+// RetryRate returns the share of attempts that were retries of a task that
+// had already been attempted, from 0 to 1.
+func (s *ProgressStats) RetryRate() float64 {
+	attempts := s.TotalAttempts()
+	if attempts == 0 {
+		return 0
+	}
+	retries := attempts - len(s.Tasks)
+	return float64(retries) / float64(attempts)
+}
+
+// FailureHotSpots returns the tasks with at least one failed attempt, most
+// failures first.
+func (s *ProgressStats) FailureHotSpots() []TaskStats {
+	var hot []TaskStats
+	for _, t := range s.Tasks {
+		if t.Failures > 0 {
+			hot = append(hot, t)
+		}
+	}
+	sort.SliceStable(hot, func(i, j int) bool {
+		return hot[i].Failures > hot[j].Failures
+	})
+	return hot
+}
+
+// ProgressReader reads a plan's progress.log back into per-run and per-task
+// statistics.
+type ProgressReader struct {
+	planDir string
+}
+
+// NewProgressReader creates a reader for the progress.log in planDir.
+func NewProgressReader(planDir string) *ProgressReader {
+	return &ProgressReader{planDir: planDir}
+}
+
+// Events returns the raw events in the order they were written.
+func (r *ProgressReader) Events() ([]ProgressEvent, error) {
+	return ReadProgressEvents(r.planDir)
+}
+
+// Stats reads the log and summarizes it. A missing log yields empty stats.
+func (r *ProgressReader) Stats() (*ProgressStats, error) {
+	events, err := r.Events()
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeProgress(events), nil
+}
+
+// SummarizeProgress computes run and task statistics from progress events.
+// An attempt ends at its task's task_completed or task_failed event, and a
+// task_failed event right after task_completed marks a failed commit; attempts
+// cut short by the end of a run are reported as interrupted. A run without a
+// terminal event ends at its last event.
+func SummarizeProgress(events []ProgressEvent) *ProgressStats {
+	stats := &ProgressStats{}
+	taskIndex := make(map[string]int)    // task ID -> index in stats.Tasks
+	openAttempts := make(map[string]int) // task ID -> index of its running attempt
+
+	var run *RunStats
+	var lastEvent time.Time
+
+	closeAttempts := func(at time.Time, status string) {
+		for taskID, idx := range openAttempts {
+			task := &stats.Tasks[taskIndex[taskID]]
+			endAttempt(task, idx, at, status)
+		}
+		openAttempts = make(map[string]int)
+	}
+	endRun := func(at time.Time, outcome string) {
+		if run == nil {
+			return
+		}
+		closeAttempts(at, AttemptStatusInterrupted)
+		run.Ended = at
+		run.Duration = at.Sub(run.Started)
+		run.Outcome = outcome
+		stats.Runs = append(stats.Runs, *run)
+		run = nil
+	}
+
+	for _, ev := range events {
+		taskID, _ := ev.Data["task_id"].(string)
+
+		switch ev.Event {
+		case EventPlanStarted:
+			endRun(lastEvent, RunOutcomeInterrupted)
+			backend, _ := ev.Data["backend"].(string)
+			run = &RunStats{Started: ev.Timestamp, Backend: backend}
+		case EventPlanCompleted:
+			endRun(ev.Timestamp, RunOutcomeCompleted)
+		case EventPlanFailed:
+			endRun(ev.Timestamp, RunOutcomeFailed)
+		case EventPlanCancelled:
+			endRun(ev.Timestamp, RunOutcomeCancelled)
+		case EventTaskStarted:
+			idx, ok := taskIndex[taskID]
+			if !ok {
+				idx = len(stats.Tasks)
+				taskIndex[taskID] = idx
+				stats.Tasks = append(stats.Tasks, TaskStats{TaskID: taskID})
+			}
+			task := &stats.Tasks[idx]
+			if prev, running := openAttempts[taskID]; running {
+				endAttempt(task, prev, ev.Timestamp, AttemptStatusInterrupted)
+			}
+			attempt := len(task.Attempts) + 1
+			if n, ok := ev.Data["attempt"].(float64); ok {
+				attempt = int(n)
+			}
+			runIdx := -1
+			if run != nil {
+				runIdx = len(stats.Runs)
+			}
+			outputFile, _ := ev.Data["output_file"].(string)
+			openAttempts[taskID] = len(task.Attempts)
+			task.Attempts = append(task.Attempts, AttemptStats{
+				Attempt:    attempt,
+				Started:    ev.Timestamp,
+				Status:     AttemptStatusRunning,
+				Run:        runIdx,
+				OutputFile: outputFile,
+			})
+		case EventTaskCompleted, EventTaskFailed, EventTaskSkipped:
+			idx, running := openAttempts[taskID]
+			if !running {
+				if ev.Event == EventTaskFailed {
+					failCommit(stats, taskIndex, taskID)
+				}
+				break
+			}
+			task := &stats.Tasks[taskIndex[taskID]]
+			switch ev.Event {
+			case EventTaskSkipped:
+				endAttempt(task, idx, ev.Timestamp, AttemptStatusSkipped)
+			case EventTaskCompleted:
+				endAttempt(task, idx, ev.Timestamp, AttemptStatusCompleted)
+				if task.AttemptsToSuccess == 0 {
+					task.AttemptsToSuccess = idx + 1
+				}
+			default:
+				endAttempt(task, idx, ev.Timestamp, AttemptStatusFailed)
+				task.Failures++
+			}
+			delete(openAttempts, taskID)
+		}
+		lastEvent = ev.Timestamp
+	}
+	// The last run may still be going, or may have died without a trace.
+	endRun(lastEvent, RunOutcomeInterrupted)
+	return stats
+}
+
+// failCommit marks the task's last attempt as failed when it had completed:
+// task_completed is logged ahead of the task's commit, and a task_failed
+// event right after it means the commit failed.
+func failCommit(stats *ProgressStats, taskIndex map[string]int, taskID string) {
+	idx, ok := taskIndex[taskID]
+	if !ok {
+		return
+	}
+	task := &stats.Tasks[idx]
+	last := len(task.Attempts) - 1
+	if last < 0 || task.Attempts[last].Status != AttemptStatusCompleted {
+		return
+	}
+	task.Attempts[last].Status = AttemptStatusFailed
+	task.Failures++
+	if task.AttemptsToSuccess == last+1 {
+		task.AttemptsToSuccess = 0
+	}
+}
+
+// endAttempt records the end of the attempt at index idx.
+func endAttempt(task *TaskStats, idx int, at time.Time, status string) {
+	a := &task.Attempts[idx]
+	a.Ended = at
+	a.Status = status
+	if at.After(a.Started) {
+		a.Duration = at.Sub(a.Started)
+		task.Duration += a.Duration
+	}
+}
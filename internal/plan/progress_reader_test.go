@@ -0,0 +1,172 @@
+package plan
+
+import (
+	"testing"
+	"time"
+)
+
+func progressEvent(minute int, event string, data map[string]interface{}) ProgressEvent {
+	return ProgressEvent{
+		Timestamp: time.Date(2024, 1, 15, 10, minute, 0, 0, time.UTC),
+		Event:     event,
+		Data:      data,
+	}
+}
+
+func taskData(taskID string, attempt int) map[string]interface{} {
+	// Attempts are float64 after a JSON round trip.
+	return map[string]interface{}{"task_id": taskID, "attempt": float64(attempt)}
+}
+
+func TestSummarizeProgress(t *testing.T) {
+	events := []ProgressEvent{
+		// First run: t01 fails once, then the run is cancelled during t01.
+		progressEvent(0, EventPlanStarted, nil),
+		progressEvent(0, EventTaskStarted, taskData("t01", 1)),
+		progressEvent(4, EventTaskFailed, taskData("t01", 1)),
+		progressEvent(5, EventTaskStarted, taskData("t01", 2)),
+		progressEvent(7, EventPlanCancelled, map[string]interface{}{"last_task_id": "t01"}),
+		// Second run: t01 succeeds, t02 succeeds first time.
+		progressEvent(10, EventPlanStarted, map[string]interface{}{"plan_id": "abc123", "backend": "claude"}),
+		progressEvent(10, EventTaskStarted, taskData("t01", 3)),
+		progressEvent(13, EventTaskCompleted, map[string]interface{}{"task_id": "t01"}),
+		progressEvent(13, EventTaskStarted, taskData("t02", 1)),
+		progressEvent(15, EventTaskCompleted, map[string]interface{}{"task_id": "t02"}),
+		progressEvent(16, EventPlanCompleted, nil),
+	}
+
+	stats := SummarizeProgress(events)
+
+	if len(stats.Runs) != 2 {
+		t.Fatalf("expected 2 runs, got %+v", stats.Runs)
+	}
+	if stats.Runs[0].Outcome != RunOutcomeCancelled || stats.Runs[0].Duration != 7*time.Minute {
+		t.Errorf("first run = %+v", stats.Runs[0])
+	}
+	if stats.Runs[1].Outcome != RunOutcomeCompleted || stats.Runs[1].Duration != 6*time.Minute {
+		t.Errorf("second run = %+v", stats.Runs[1])
+	}
+	if stats.Runs[1].Backend != "claude" || stats.Runs[0].Backend != "" {
+		t.Errorf("backends = %q, %q", stats.Runs[0].Backend, stats.Runs[1].Backend)
+	}
+	if stats.WallTime() != 13*time.Minute {
+		t.Errorf("WallTime = %v, want 13m", stats.WallTime())
+	}
+
+	if len(stats.Tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %+v", stats.Tasks)
+	}
+	t01 := stats.Tasks[0]
+	if t01.TaskID != "t01" || len(t01.Attempts) != 3 || t01.Failures != 1 || t01.AttemptsToSuccess != 3 {
+		t.Errorf("t01 = %+v", t01)
+	}
+	wantStatuses := []string{AttemptStatusFailed, AttemptStatusInterrupted, AttemptStatusCompleted}
+	for i, want := range wantStatuses {
+		if t01.Attempts[i].Status != want {
+			t.Errorf("t01 attempt %d status = %q, want %q", i+1, t01.Attempts[i].Status, want)
+		}
+	}
+	// 4m failed + 2m interrupted + 3m succeeded
+	if t01.Duration != 9*time.Minute {
+		t.Errorf("t01 duration = %v, want 9m", t01.Duration)
+	}
+
+	if t01.Attempts[0].Run != 0 || t01.Attempts[2].Run != 1 {
+		t.Errorf("attempt runs = %d, %d", t01.Attempts[0].Run, t01.Attempts[2].Run)
+	}
+
+	t02 := stats.Tasks[1]
+	if !t02.Succeeded() || t02.AttemptsToSuccess != 1 || t02.Duration != 2*time.Minute {
+		t.Errorf("t02 = %+v", t02)
+	}
+
+	if stats.TotalAttempts() != 4 {
+		t.Errorf("TotalAttempts = %d, want 4", stats.TotalAttempts())
+	}
+	if got := stats.RetryRate(); got != 0.5 {
+		t.Errorf("RetryRate = %v, want 0.5", got)
+	}
+	hot := stats.FailureHotSpots()
+	if len(hot) != 1 || hot[0].TaskID != "t01" {
+		t.Errorf("FailureHotSpots = %+v", hot)
+	}
+}
+
+func TestSummarizeProgress_RunWithoutTerminalEvent(t *testing.T) {
+	stats := SummarizeProgress([]ProgressEvent{
+		progressEvent(0, EventPlanStarted, nil),
+		progressEvent(1, EventTaskStarted, taskData("t01", 1)),
+		progressEvent(3, EventTaskFailed, taskData("t01", 1)),
+		progressEvent(3, EventTaskStarted, taskData("t01", 2)),
+	})
+
+	if len(stats.Runs) != 1 || stats.Runs[0].Outcome != RunOutcomeInterrupted || stats.Runs[0].Duration != 3*time.Minute {
+		t.Errorf("runs = %+v", stats.Runs)
+	}
+	if got := stats.Tasks[0].Attempts[1].Status; got != AttemptStatusInterrupted {
+		t.Errorf("last attempt status = %q, want interrupted", got)
+	}
+}
+
+func TestProgressReader_Stats(t *testing.T) {
+	dir := t.TempDir()
+
+	stats, err := NewProgressReader(dir).Stats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats.Runs) != 0 || len(stats.Tasks) != 0 {
+		t.Errorf("expected empty stats, got %+v", stats)
+	}
+
+	logger := NewProgressLogger(dir)
+	logger.PlanStarted("abc123", "")
+	logger.TaskStarted("t01", 1, TaskStartedInfo{})
+	logger.TaskCompleted("t01", TaskCommit{})
+	logger.PlanCompleted(1, 1, time.Second)
+
+	stats, err = NewProgressReader(dir).Stats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats.Runs) != 1 || stats.Runs[0].Outcome != RunOutcomeCompleted {
+		t.Errorf("runs = %+v", stats.Runs)
+	}
+	if len(stats.Tasks) != 1 || stats.Tasks[0].AttemptsToSuccess != 1 {
+		t.Errorf("tasks = %+v", stats.Tasks)
+	}
+}
+
+func TestSummarizeProgress_SkippedAttempt(t *testing.T) {
+	stats := SummarizeProgress([]ProgressEvent{
+		progressEvent(0, EventPlanStarted, nil),
+		progressEvent(0, EventTaskStarted, taskData("t01", 1)),
+		progressEvent(2, EventTaskSkipped, taskData("t01", 1)),
+		progressEvent(2, EventTaskStarted, taskData("t02", 1)),
+		progressEvent(3, EventTaskCompleted, map[string]interface{}{"task_id": "t02"}),
+		progressEvent(3, EventPlanCompleted, nil),
+	})
+
+	t01 := stats.Tasks[0]
+	if t01.Attempts[0].Status != AttemptStatusSkipped || t01.Failures != 0 || t01.Succeeded() {
+		t.Errorf("t01 = %+v", t01)
+	}
+	if t01.Duration != 2*time.Minute {
+		t.Errorf("t01 duration = %v, want 2m", t01.Duration)
+	}
+}
+
+func TestSummarizeProgress_FailedCommit(t *testing.T) {
+	stats := SummarizeProgress([]ProgressEvent{
+		progressEvent(0, EventPlanStarted, nil),
+		progressEvent(0, EventTaskStarted, taskData("t01", 1)),
+		progressEvent(2, EventTaskCompleted, map[string]interface{}{"task_id": "t01", "commit_message": "Add it"}),
+		progressEvent(2, EventTaskFailed, taskData("t01", 1)),
+		progressEvent(2, EventPlanFailed, nil),
+	})
+
+	t01 := stats.Tasks[0]
+	if len(t01.Attempts) != 1 || t01.Attempts[0].Status != AttemptStatusFailed || t01.Failures != 1 || t01.Succeeded() {
+		t.Errorf("t01 = %+v", t01)
+	}
+}
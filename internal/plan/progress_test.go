@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -96,7 +97,7 @@ func TestProgressLogger_PlanStarted(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	logger := NewProgressLogger(tmpDir)
-	err := logger.PlanStarted("test-plan-123")
+	err := logger.PlanStarted("test-plan-123", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -112,11 +113,31 @@ func TestProgressLogger_PlanStarted(t *testing.T) {
 	}
 }
 
+func TestProgressLogger_PlanStartedBackend(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logger := NewProgressLogger(tmpDir)
+	if err := logger.PlanStarted("test-plan-123", "claude"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event := readLastEvent(t, tmpDir)
+	if event.Data["plan_id"] != "test-plan-123" || event.Data["backend"] != "claude" {
+		t.Errorf("unexpected data: %v", event.Data)
+	}
+
+	if err := logger.PlanStarted("test-plan-123", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := readLastEvent(t, tmpDir).Data["backend"]; ok {
+		t.Error("expected no backend for an empty name")
+	}
+}
+
 func TestProgressLogger_TaskStarted(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	logger := NewProgressLogger(tmpDir)
-	err := logger.TaskStarted("task-456", 2)
+	err := logger.TaskStarted("task-456", 2, TaskStartedInfo{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -137,11 +158,55 @@ func TestProgressLogger_TaskStarted(t *testing.T) {
 	}
 }
 
+func TestProgressLogger_TaskStartedInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logger := NewProgressLogger(tmpDir)
+	agent := AgentSettings{
+		Model:        "opus",
+		MaxTurns:     40,
+		AllowedTools: []string{"Read", "Edit"},
+		Env:          map[string]string{"API_TOKEN": "secret", "CI": "1"},
+	}
+	if err := logger.TaskStarted("t01", 3, TaskStartedInfo{OutputFile: "output/t01-attempt3.log", Agent: agent}); err != nil {
+		t.Fatal(err)
+	}
+
+	event := readLastEvent(t, tmpDir)
+	if event.Event != EventTaskStarted || event.Data["output_file"] != "output/t01-attempt3.log" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	settings, ok := event.Data["agent"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected agent settings, got %v", event.Data)
+	}
+	if settings["model"] != "opus" || settings["max_turns"] != float64(40) {
+		t.Errorf("unexpected settings: %v", settings)
+	}
+	if env, _ := settings["env"].([]interface{}); len(env) != 2 || env[0] != "API_TOKEN" {
+		t.Errorf("expected the environment variable names, got %v", settings["env"])
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, progressLogFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("environment values should not be logged: %s", data)
+	}
+
+	if err := logger.TaskStarted("t02", 1, TaskStartedInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if event := readLastEvent(t, tmpDir); event.Data["agent"] != nil || event.Data["output_file"] != nil {
+		t.Errorf("expected no output file or agent settings for a zero info, got %v", event.Data)
+	}
+}
+
 func TestProgressLogger_TaskCompleted(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	logger := NewProgressLogger(tmpDir)
-	err := logger.TaskCompleted("task-789")
+	err := logger.TaskCompleted("task-789", TaskCommit{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -157,6 +222,49 @@ func TestProgressLogger_TaskCompleted(t *testing.T) {
 	}
 }
 
+func TestProgressLogger_TaskCompletedCommit(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logger := NewProgressLogger(tmpDir)
+	if err := logger.TaskCompleted("t01", TaskCommit{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.TaskCompleted("t02", TaskCommit{Message: "Add login endpoint", Files: []string{"auth/login.go", "auth/login_test.go"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.TaskCompleted("t03", TaskCommit{}); err != nil {
+		t.Fatal(err)
+	}
+	// A commit that failed after being logged is superseded.
+	if err := logger.TaskCompleted("t04", TaskCommit{Message: "Add logout endpoint", Files: []string{"auth/logout.go"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.TaskFailed("t04", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.TaskCompleted("t03", TaskCommit{}); err != nil {
+		t.Fatal(err)
+	}
+
+	event := readLastEvent(t, tmpDir)
+	if _, ok := event.Data["commit_message"]; ok {
+		t.Errorf("expected a plain task_completed event without a commit, got %v", event.Data)
+	}
+
+	events, err := ReadProgressEvents(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits := TaskCommits(events)
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit, got %+v", commits)
+	}
+	c := commits["t02"]
+	if c.Message != "Add login endpoint" || len(c.Files) != 2 || c.Files[1] != "auth/login_test.go" {
+		t.Errorf("unexpected commit: %+v", c)
+	}
+}
+
 func TestProgressLogger_TaskFailed(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -282,3 +390,33 @@ func readLastEvent(t *testing.T, tmpDir string) ProgressEvent {
 
 	return event
 }
+
+func TestReadProgressEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	events, err := ReadProgressEvents(tmpDir)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected no events for a missing log, got %v, %v", events, err)
+	}
+
+	logger := NewProgressLogger(tmpDir)
+	logger.TaskStarted("t01", 1, TaskStartedInfo{})
+	f, err := os.OpenFile(filepath.Join(tmpDir, progressLogFileName), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	f.WriteString("not json\n")
+	f.Close()
+	logger.TaskCompleted("t01", TaskCommit{})
+
+	events, err = ReadProgressEvents(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Event != EventTaskStarted || events[1].Event != EventTaskCompleted {
+		t.Errorf("unexpected events: %s, %s", events[0].Event, events[1].Event)
+	}
+}
@@ -0,0 +1,44 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReviewMarker precedes the JSON verdict in the reviewer's final message.
+const ReviewMarker = "RAFA_REVIEW"
+
+// Review verdicts.
+const (
+	ReviewApprove = "approve"
+	ReviewReject  = "reject"
+)
+
+// Review is a reviewer agent's verdict on a task attempt's changes.
+type Review struct {
+	Verdict string   `json:"verdict"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// ParseReview finds the last review verdict in text and decodes it. Unlike
+// a completion report, a review is required: text without one is an error.
+func ParseReview(text string) (*Review, error) {
+	var review Review
+	found, err := decodeAfterMarker(text, ReviewMarker, &review)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("reviewer gave no %s verdict", ReviewMarker)
+	}
+	review.Verdict = strings.ToLower(strings.TrimSpace(review.Verdict))
+	if review.Verdict != ReviewApprove && review.Verdict != ReviewReject {
+		return nil, fmt.Errorf("unknown review verdict %q", review.Verdict)
+	}
+	return &review, nil
+}
+
+// Approved reports whether the reviewer approved the changes.
+func (r *Review) Approved() bool {
+	return r.Verdict == ReviewApprove
+}
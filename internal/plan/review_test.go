@@ -0,0 +1,35 @@
+package plan
+
+import (
+	"testing"
+)
+
+func TestParseReview(t *testing.T) {
+	review, err := ParseReview("Looks good.\n" + ReviewMarker + "\n" + `{"verdict": "Approve", "reasons": ["Criteria met"]}`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !review.Approved() || len(review.Reasons) != 1 {
+		t.Errorf("unexpected review %+v", review)
+	}
+
+	review, err = ParseReview(ReviewMarker + ` {"verdict": "reject", "reasons": ["No tests for the error path"]}`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if review.Approved() {
+		t.Errorf("expected a rejection, got %+v", review)
+	}
+}
+
+func TestParseReview_Invalid(t *testing.T) {
+	for _, text := range []string{
+		"No verdict here.",
+		ReviewMarker + ` {"verdict": "maybe"}`,
+		ReviewMarker + ` {"verdict": `,
+	} {
+		if _, err := ParseReview(text); err == nil {
+			t.Errorf("expected an error for %q", text)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CurrentSchemaVersion is the plan.json schema version written by this build.
+// Bump it together with a new entry in migrations whenever Plan or Task changes
+// in a way that older documents cannot be decoded into directly.
+const CurrentSchemaVersion = 2
+
+// ErrSchemaTooNew is returned when plan.json was written by a newer Rafa.
+var ErrSchemaTooNew = errors.New("plan.json was written by a newer version of rafa")
+
+// migration upgrades a raw plan document from schema version From to From+1.
+// Migrations operate on the decoded JSON map so they do not depend on the
+// current shape of the Plan struct.
+type migration struct {
+	From    int
+	Migrate func(doc map[string]interface{}) error
+}
+
+// migrations is the ordered registry of schema upgrades. Each entry must
+// upgrade exactly one version.
+var migrations = []migration{
+	{From: 0, Migrate: migrateV0ToV1},
+	{From: 1, Migrate: migrateV1ToV2},
+}
+
+// migrateV0ToV1 upgrades plans written before schemaVersion existed.
+// Those documents could omit statuses, which are filled with their defaults.
+func migrateV0ToV1(doc map[string]interface{}) error {
+	if status, _ := doc["status"].(string); status == "" {
+		doc["status"] = PlanStatusNotStarted
+	}
+
+	tasks, ok := doc["tasks"].([]interface{})
+	if !ok {
+		if doc["tasks"] != nil {
+			return fmt.Errorf("tasks must be an array")
+		}
+		doc["tasks"] = []interface{}{}
+		return nil
+	}
+	for i, raw := range tasks {
+		task, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("task %d must be an object", i+1)
+		}
+		if status, _ := task["status"].(string); status == "" {
+			task["status"] = TaskStatusPending
+		}
+	}
+	return nil
+}
+
+// migrateV1ToV2 marks plans that may use the fields added in version 2:
+// design sections, completion reports, reviews, agent settings and path
+// policies. They are all optional, so version 1 documents decode unchanged;
+// the bump makes older builds report ErrSchemaTooNew instead of failing on
+// an unknown field.
+func migrateV1ToV2(doc map[string]interface{}) error {
+	return nil
+}
+
+// SchemaStatus describes the schema state of a plan.json document.
+type SchemaStatus struct {
+	Version        int  // Version recorded in the document (0 when absent)
+	NeedsMigration bool // True when Version is older than CurrentSchemaVersion
+}
+
+// InspectPlan reports the schema status of plan.json in planDir without
+// modifying it. It returns an error if the document cannot be migrated or
+// contains unknown fields after migration.
+func InspectPlan(planDir string) (SchemaStatus, error) {
+	data, err := os.ReadFile(filepath.Join(planDir, "plan.json"))
+	if err != nil {
+		return SchemaStatus{}, fmt.Errorf("failed to read plan.json: %w", err)
+	}
+
+	_, version, err := decodePlan(data)
+	if err != nil {
+		return SchemaStatus{Version: version}, err
+	}
+	return SchemaStatus{
+		Version:        version,
+		NeedsMigration: version < CurrentSchemaVersion,
+	}, nil
+}
+
+// decodePlan decodes a plan.json document, applying any pending migrations.
+// It returns the decoded plan and the schema version found in the document.
+// Unknown fields are rejected so typos and foreign data are not silently dropped.
+func decodePlan(data []byte) (*Plan, int, error) {
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, 0, err
+	}
+	version := header.SchemaVersion
+
+	if version > CurrentSchemaVersion {
+		return nil, version, fmt.Errorf("%w (schema version %d, supported %d)", ErrSchemaTooNew, version, CurrentSchemaVersion)
+	}
+
+	if version < CurrentSchemaVersion {
+		migrated, err := migrateDocument(data, version)
+		if err != nil {
+			return nil, version, err
+		}
+		data = migrated
+	}
+
+	var p Plan
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, version, err
+	}
+	return &p, version, nil
+}
+
+// migrateDocument runs all migrations starting at version and returns the
+// upgraded document encoded as JSON.
+func migrateDocument(data []byte, version int) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	for version < CurrentSchemaVersion {
+		m, ok := findMigration(version)
+		if !ok {
+			return nil, fmt.Errorf("no migration registered from schema version %d", version)
+		}
+		if err := m.Migrate(doc); err != nil {
+			return nil, fmt.Errorf("migrate schema version %d to %d: %w", version, version+1, err)
+		}
+		version++
+		doc["schemaVersion"] = version
+	}
+
+	return json.Marshal(doc)
+}
+
+func findMigration(from int) (migration, bool) {
+	for _, m := range migrations {
+		if m.From == from {
+			return m, true
+		}
+	}
+	return migration{}, false
+}
+
+// IsMigrationOnly reports whether plan.json in planDir is exactly what
+// LoadPlan writes when it migrates its plan.json.v<version>.bak backup, so it
+// holds no changes besides the schema upgrade.
+func IsMigrationOnly(planDir string, version int) bool {
+	backup, err := os.ReadFile(filepath.Join(planDir, fmt.Sprintf("plan.json.v%d.bak", version)))
+	if err != nil {
+		return false
+	}
+	current, err := os.ReadFile(filepath.Join(planDir, "plan.json"))
+	if err != nil {
+		return false
+	}
+	p, _, err := decodePlan(backup)
+	if err != nil {
+		return false
+	}
+	migrated, err := encodePlan(p)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(migrated, current)
+}
+
+// backupPlanFile writes the original plan.json contents next to the plan as
+// plan.json.v<version>.bak. An existing backup is left untouched so the
+// oldest original is always preserved.
+func backupPlanFile(planDir string, data []byte, version int) error {
+	backupPath := filepath.Join(planDir, fmt.Sprintf("plan.json.v%d.bak", version))
+	f, err := os.OpenFile(backupPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(backupPath)
+		return err
+	}
+	return f.Close()
+}
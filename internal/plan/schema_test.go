@@ -0,0 +1,236 @@
+package plan
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const v0PlanJSON = `{
+  "id": "abc123",
+  "name": "legacy",
+  "description": "Plan written before schemaVersion existed",
+  "sourceFile": "docs/designs/legacy.md",
+  "createdAt": "2026-01-28T20:27:45Z",
+  "tasks": [
+    {
+      "id": "t01",
+      "title": "First",
+      "description": "Do it",
+      "acceptanceCriteria": ["works"],
+      "attempts": 2
+    }
+  ]
+}`
+
+func writePlanJSON(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "plan.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write plan.json: %v", err)
+	}
+}
+
+func TestLoadPlan_MigratesV0(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, v0PlanJSON)
+
+	p, err := LoadPlan(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", p.SchemaVersion, CurrentSchemaVersion)
+	}
+	if p.Status != PlanStatusNotStarted {
+		t.Errorf("Status = %q, want %q", p.Status, PlanStatusNotStarted)
+	}
+	if p.Tasks[0].Status != TaskStatusPending {
+		t.Errorf("task Status = %q, want %q", p.Tasks[0].Status, TaskStatusPending)
+	}
+	if p.Tasks[0].Attempts != 2 {
+		t.Errorf("task Attempts = %d, want 2", p.Tasks[0].Attempts)
+	}
+
+	backup, err := os.ReadFile(filepath.Join(dir, "plan.json.v0.bak"))
+	if err != nil {
+		t.Fatalf("expected backup file: %v", err)
+	}
+	if string(backup) != v0PlanJSON {
+		t.Error("backup should contain the original document verbatim")
+	}
+
+	status, err := InspectPlan(dir)
+	if err != nil {
+		t.Fatalf("InspectPlan after migration: %v", err)
+	}
+	if status.NeedsMigration || status.Version != CurrentSchemaVersion {
+		t.Errorf("expected migrated plan on disk, got %+v", status)
+	}
+}
+
+func TestLoadPlan_MigratesV1(t *testing.T) {
+	dir := t.TempDir()
+	v1 := `{"schemaVersion": 1, "id": "abc123", "name": "x", "status": "in_progress", "tasks": [{"id": "t01", "title": "First", "description": "", "acceptanceCriteria": ["works"], "status": "completed"}]}`
+	writePlanJSON(t, dir, v1)
+
+	p, err := LoadPlan(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.SchemaVersion != CurrentSchemaVersion || p.Status != PlanStatusInProgress || p.Tasks[0].Status != TaskStatusCompleted {
+		t.Errorf("unexpected plan after migration: %+v", p)
+	}
+	if backup, err := os.ReadFile(filepath.Join(dir, "plan.json.v1.bak")); err != nil || string(backup) != v1 {
+		t.Errorf("expected the v1 plan to be backed up, got %q (%v)", backup, err)
+	}
+}
+
+func TestLoadPlan_KeepsExistingBackup(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, v0PlanJSON)
+	backupPath := filepath.Join(dir, "plan.json.v0.bak")
+	if err := os.WriteFile(backupPath, []byte("original"), 0644); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+
+	if _, err := LoadPlan(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(backupPath)
+	if string(data) != "original" {
+		t.Errorf("existing backup was overwritten: %q", data)
+	}
+}
+
+func TestLoadPlan_RejectsUnknownFields(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, `{"schemaVersion": 2, "id": "abc123", "name": "x", "tasks": [], "priority": "high"}`)
+
+	_, err := LoadPlan(dir)
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if !strings.Contains(err.Error(), `unknown field "priority"`) {
+		t.Errorf("expected unknown field error, got: %v", err)
+	}
+}
+
+func TestLoadPlan_RejectsUnknownTaskFields(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, `{"id": "abc123", "name": "x", "tasks": [{"id": "t01", "title": "a", "owner": "me"}]}`)
+
+	_, err := LoadPlan(dir)
+	if err == nil {
+		t.Fatal("expected error for unknown task field")
+	}
+	if !strings.Contains(err.Error(), `unknown field "owner"`) {
+		t.Errorf("expected unknown field error, got: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "plan.json.v0.bak")); !os.IsNotExist(statErr) {
+		t.Error("failed migration should not write a backup")
+	}
+}
+
+func TestLoadPlan_RejectsNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, `{"schemaVersion": 999, "id": "abc123", "name": "x", "tasks": []}`)
+
+	_, err := LoadPlan(dir)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got: %v", err)
+	}
+}
+
+func TestInspectPlan_DoesNotModify(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, v0PlanJSON)
+
+	status, err := InspectPlan(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !status.NeedsMigration || status.Version != 0 {
+		t.Errorf("expected v0 needing migration, got %+v", status)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "plan.json"))
+	if string(data) != v0PlanJSON {
+		t.Error("InspectPlan must not rewrite plan.json")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "plan.json.v0.bak")); !os.IsNotExist(err) {
+		t.Error("InspectPlan must not write a backup")
+	}
+}
+
+func TestSavePlan_StampsSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	p := &Plan{ID: "abc123", Name: "x", Tasks: []Task{}}
+
+	if err := SavePlan(dir, p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "plan.json"))
+	if !strings.Contains(string(data), `"schemaVersion": 2`) {
+		t.Errorf("expected schemaVersion in saved plan, got:\n%s", data)
+	}
+}
+
+func TestMigrationsAreContiguous(t *testing.T) {
+	for v := 0; v < CurrentSchemaVersion; v++ {
+		if _, ok := findMigration(v); !ok {
+			t.Errorf("missing migration from schema version %d", v)
+		}
+	}
+}
+
+func TestReadPlan_MigratesInMemoryOnly(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, v0PlanJSON)
+
+	p, err := ReadPlan(dir)
+	if err != nil {
+		t.Fatalf("ReadPlan() error: %v", err)
+	}
+	if p.SchemaVersion != CurrentSchemaVersion || p.Name != "legacy" {
+		t.Errorf("unexpected plan: %+v", p)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "plan.json"))
+	if err != nil {
+		t.Fatalf("failed to read plan.json: %v", err)
+	}
+	if string(data) != v0PlanJSON {
+		t.Error("ReadPlan should not rewrite plan.json")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "plan.json.v0.bak")); !os.IsNotExist(err) {
+		t.Error("ReadPlan should not write a backup")
+	}
+}
+
+func TestIsMigrationOnly(t *testing.T) {
+	dir := t.TempDir()
+	writePlanJSON(t, dir, v0PlanJSON)
+	if _, err := LoadPlan(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsMigrationOnly(dir, 0) {
+		t.Error("expected a freshly migrated plan to be migration only")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "plan.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePlanJSON(t, dir, strings.Replace(string(data), `"First"`, `"Edited"`, 1))
+	if IsMigrationOnly(dir, 0) {
+		t.Error("expected a hand-edited plan not to be migration only")
+	}
+	if IsMigrationOnly(dir, 1) {
+		t.Error("expected no migration without a backup")
+	}
+}
@@ -0,0 +1,88 @@
+package plan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pablasso/rafa/internal/design"
+)
+
+// SectionRef links a task to the part of the plan's source document it was
+// extracted from: a GitHub-style heading anchor, a 1-based inclusive line
+// range, or both.
+type SectionRef struct {
+	Anchor    string `json:"anchor,omitempty" yaml:"anchor,omitempty"`
+	StartLine int    `json:"startLine,omitempty" yaml:"startLine,omitempty"`
+	EndLine   int    `json:"endLine,omitempty" yaml:"endLine,omitempty"`
+}
+
+// String formats the reference as "#anchor (lines 12-30)".
+func (r SectionRef) String() string {
+	var lines string
+	switch {
+	case r.StartLine > 0 && r.EndLine > r.StartLine:
+		lines = fmt.Sprintf("lines %d-%d", r.StartLine, r.EndLine)
+	case r.StartLine > 0:
+		lines = fmt.Sprintf("line %d", r.StartLine)
+	}
+	switch {
+	case r.Anchor != "" && lines != "":
+		return fmt.Sprintf("#%s (%s)", r.Anchor, lines)
+	case r.Anchor != "":
+		return "#" + r.Anchor
+	}
+	return lines
+}
+
+// Excerpt returns the part of doc r points at: the section under its anchor,
+// or else its line range. The anchor comes first so edits above the section
+// don't break the link.
+func (r SectionRef) Excerpt(doc string) (string, bool) {
+	if s, ok := design.Find(design.Parse(doc), r.Anchor); ok {
+		return s.Text, true
+	}
+	if r.StartLine > 0 {
+		return design.Lines(doc, r.StartLine, r.EndLine)
+	}
+	return "", false
+}
+
+// ResolveSections links the extracted tasks to sections of doc, the design
+// doc they were extracted from. Anchors get the line range of their
+// section, and links that match nothing in doc are dropped.
+func (r *TaskExtractionResult) ResolveSections(doc string) {
+	sections := design.Parse(doc)
+	for i := range r.Tasks {
+		ref := r.Tasks[i].Section
+		if ref == nil {
+			continue
+		}
+		if s, ok := design.Find(sections, ref.Anchor); ok {
+			r.Tasks[i].Section = &SectionRef{Anchor: s.Anchor, StartLine: s.Start, EndLine: s.End}
+			continue
+		}
+		if _, ok := design.Lines(doc, ref.StartLine, ref.EndLine); ok {
+			r.Tasks[i].Section = &SectionRef{StartLine: ref.StartLine, EndLine: ref.EndLine}
+			continue
+		}
+		r.Tasks[i].Section = nil
+	}
+}
+
+// ReadSourceFile reads the plan's source document. A relative SourceFile is
+// resolved against repoRoot.
+func (p *Plan) ReadSourceFile(repoRoot string) (string, error) {
+	if p.SourceFile == "" {
+		return "", fmt.Errorf("plan has no source file")
+	}
+	path := p.SourceFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoRoot, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read source file: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,96 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sectionTestDoc = `# Auth
+
+## Session Storage
+Sessions live in Redis.
+
+## Login Endpoint
+POST /login.
+`
+
+func TestSectionRef_String(t *testing.T) {
+	tests := []struct {
+		ref  SectionRef
+		want string
+	}{
+		{SectionRef{Anchor: "login", StartLine: 6, EndLine: 7}, "#login (lines 6-7)"},
+		{SectionRef{Anchor: "login"}, "#login"},
+		{SectionRef{StartLine: 3}, "line 3"},
+		{SectionRef{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.ref.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestSectionRef_Excerpt(t *testing.T) {
+	// The anchor wins over a stale line range.
+	got, ok := SectionRef{Anchor: "login-endpoint", StartLine: 1, EndLine: 2}.Excerpt(sectionTestDoc)
+	if !ok || got != "## Login Endpoint\nPOST /login." {
+		t.Errorf("Excerpt by anchor = %q, %v", got, ok)
+	}
+	got, ok = SectionRef{Anchor: "renamed", StartLine: 3, EndLine: 4}.Excerpt(sectionTestDoc)
+	if !ok || got != "## Session Storage\nSessions live in Redis." {
+		t.Errorf("Excerpt by lines = %q, %v", got, ok)
+	}
+	if _, ok := (SectionRef{Anchor: "renamed"}).Excerpt(sectionTestDoc); ok {
+		t.Error("expected no excerpt for a dangling anchor")
+	}
+}
+
+func TestTaskExtractionResult_ResolveSections(t *testing.T) {
+	r := &TaskExtractionResult{Tasks: []ExtractedTask{
+		{Title: "Sessions", Section: &SectionRef{Anchor: "#session-storage"}},
+		{Title: "Login", Section: &SectionRef{Anchor: "Login Endpoint"}},
+		{Title: "Lines", Section: &SectionRef{StartLine: 1, EndLine: 2}},
+		{Title: "Dangling", Section: &SectionRef{Anchor: "nope"}},
+		{Title: "None"},
+	}}
+	r.ResolveSections(sectionTestDoc)
+
+	want := []*SectionRef{
+		{Anchor: "session-storage", StartLine: 3, EndLine: 5},
+		{Anchor: "login-endpoint", StartLine: 6, EndLine: 7},
+		{StartLine: 1, EndLine: 2},
+		nil,
+		nil,
+	}
+	for i, w := range want {
+		got := r.Tasks[i].Section
+		if (got == nil) != (w == nil) || (got != nil && *got != *w) {
+			t.Errorf("task %d section = %+v, want %+v", i+1, got, w)
+		}
+	}
+}
+
+func TestPlan_ReadSourceFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "auth.md"), []byte(sectionTestDoc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Plan{SourceFile: "docs/auth.md"}
+	if got, err := p.ReadSourceFile(root); err != nil || got != sectionTestDoc {
+		t.Errorf("ReadSourceFile = %q, %v", got, err)
+	}
+	p.SourceFile = filepath.Join(root, "docs", "auth.md")
+	if _, err := p.ReadSourceFile("/elsewhere"); err != nil {
+		t.Errorf("expected an absolute path to ignore the root, got %v", err)
+	}
+	p.SourceFile = ""
+	if _, err := p.ReadSourceFile(root); err == nil {
+		t.Error("expected an error without a source file")
+	}
+}
@@ -45,6 +45,8 @@ func FindPlanFolder(name string) (string, error) {
 }
 
 // LoadPlan reads and parses plan.json from a plan directory.
+// Documents written with an older schema version are migrated, the original
+// is backed up as plan.json.v<N>.bak, and the upgraded plan is saved in place.
 func LoadPlan(planDir string) (*Plan, error) {
 	planPath := filepath.Join(planDir, "plan.json")
 
@@ -53,12 +55,36 @@ func LoadPlan(planDir string) (*Plan, error) {
 		return nil, fmt.Errorf("failed to read plan.json: %w", err)
 	}
 
-	var plan Plan
-	if err := json.Unmarshal(data, &plan); err != nil {
+	plan, version, err := decodePlan(data)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse plan.json: %w", err)
 	}
 
-	return &plan, nil
+	if version < CurrentSchemaVersion {
+		if err := backupPlanFile(planDir, data, version); err != nil {
+			return nil, fmt.Errorf("failed to back up plan.json: %w", err)
+		}
+		if err := SavePlan(planDir, plan); err != nil {
+			return nil, fmt.Errorf("failed to save migrated plan: %w", err)
+		}
+	}
+
+	return plan, nil
+}
+
+// ReadPlan reads plan.json from a plan directory without modifying it.
+// Documents written with an older schema version are migrated in memory only,
+// which makes it safe for read-only callers such as search and reports.
+func ReadPlan(planDir string) (*Plan, error) {
+	data, err := os.ReadFile(filepath.Join(planDir, "plan.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan.json: %w", err)
+	}
+	plan, _, err := decodePlan(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse plan.json: %w", err)
+	}
+	return plan, nil
 }
 
 // SavePlan atomically writes plan.json to the plan directory.
@@ -67,10 +93,9 @@ func SavePlan(planDir string, p *Plan) error {
 	planPath := filepath.Join(planDir, "plan.json")
 	tmpPath := fmt.Sprintf("%s.tmp.%d", planPath, os.Getpid())
 
-	// Marshal with 2-space indent to match CreatePlanFolder
-	data, err := json.MarshalIndent(p, "", "  ")
+	data, err := encodePlan(p)
 	if err != nil {
-		return fmt.Errorf("failed to marshal plan: %w", err)
+		return err
 	}
 
 	// Write to temp file
@@ -88,6 +113,19 @@ func SavePlan(planDir string, p *Plan) error {
 	return nil
 }
 
+// encodePlan returns plan.json's contents for p, which is always written in
+// the current schema.
+func encodePlan(p *Plan) ([]byte, error) {
+	p.SchemaVersion = CurrentSchemaVersion
+
+	// Marshal with 2-space indent to match CreatePlanFolder
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	return data, nil
+}
+
 // FirstPendingTask finds the first non-completed task.
 // If a failed task is found, its status is reset to pending (attempts are preserved).
 // Returns the task index, or -1 if all tasks are completed.
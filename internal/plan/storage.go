@@ -67,6 +67,7 @@ func CreatePlanFolder(plan *Plan) error {
 	}
 
 	// Write plan.json with pretty formatting
+	plan.SchemaVersion = CurrentSchemaVersion
 	planData, err := json.MarshalIndent(plan, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal plan: %w", err)
@@ -83,10 +84,32 @@ func CreatePlanFolder(plan *Plan) error {
 		return fmt.Errorf("failed to create progress.log: %w", err)
 	}
 
-	outputLogPath := filepath.Join(folderPath, "output.log")
+	outputLogPath := filepath.Join(folderPath, OutputLogFileName)
 	if err := os.WriteFile(outputLogPath, []byte{}, 0644); err != nil {
 		return fmt.Errorf("failed to create output.log: %w", err)
 	}
 
 	return nil
 }
+
+// ListPlanDirs returns the paths of all plan folders in .rafa/plans/,
+// sorted by folder name. A missing plans directory yields an empty list.
+func ListPlanDirs() ([]string, error) {
+	plansPath := filepath.Join(rafaDir, plansDir)
+
+	entries, err := os.ReadDir(plansPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plans directory: %w", err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(plansPath, entry.Name()))
+		}
+	}
+	return dirs, nil
+}
@@ -8,6 +8,19 @@ type Task struct {
 	AcceptanceCriteria []string `json:"acceptanceCriteria"`
 	Status             string   `json:"status"`
 	Attempts           int      `json:"attempts"`
+	// Section is the part of the plan's source document the task was
+	// extracted from, when known.
+	Section *SectionRef `json:"section,omitempty"`
+	// Agent sets the agent options for the task, over the plan's.
+	Agent *AgentConfig `json:"agent,omitempty"`
+	// Paths limits what the task may change, over the plan's policy.
+	Paths *PathPolicy `json:"paths,omitempty"`
+	// Report is the completion report of the task's latest attempt, when
+	// the agent gave one.
+	Report *CompletionReport `json:"report,omitempty"`
+	// Review is the reviewer's verdict on the task's latest reviewed
+	// attempt, when reviews are on.
+	Review *Review `json:"review,omitempty"`
 }
 
 // Task status constants
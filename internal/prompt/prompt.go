@@ -0,0 +1,220 @@
+// Package prompt renders the prompts sent to the agent from text/template
+// templates. The defaults are embedded in the binary; a project can replace
+// either of them with a file of the same name in .rafa/prompts/:
+//
+//	task.tmpl     executing one task attempt, rendered with TaskData
+//	extract.tmpl  extracting a plan from a design doc, rendered with ExtractionData
+//	review.tmpl   reviewing an attempt's changes, rendered with ReviewData
+//
+// Besides the standard template functions, templates can use inc, which adds
+// one to an int (for 1-based numbering of range indexes).
+package prompt
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// Template names, which are also the override file names.
+const (
+	TaskTemplate       = "task.tmpl"
+	ExtractionTemplate = "extract.tmpl"
+	ReviewTemplate     = "review.tmpl"
+)
+
+// DirName is the override folder inside the .rafa folder.
+const DirName = "prompts"
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+var funcs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}
+
+// TaskData is what task.tmpl is rendered with.
+type TaskData struct {
+	Plan PlanData
+	Task plan.Task
+	// DesignSection is the text of the design doc section the task was
+	// extracted from, or empty when the task isn't linked to one.
+	DesignSection string
+	// TaskNumber is the task's 1-based position in the plan.
+	TaskNumber  int
+	TaskCount   int
+	Attempt     int // 1-based number of the attempt being started
+	MaxAttempts int
+	// Failures lists the task's earlier failed attempts, oldest first. Error
+	// is empty for attempts made in earlier runs.
+	Failures []Failure
+	// Paths is the task's effective path policy. Changes outside it fail
+	// the attempt or are reverted.
+	Paths plan.PathPolicy
+	// PlanContext is the plan summary the default template shows under
+	// "## Context".
+	PlanContext string
+	// Context is project context gathered for the task. The default
+	// template adds it after the plan context.
+	Context ContextData
+	Repo    RepoData
+}
+
+// ContextData is project context for a task. Any part may be empty.
+type ContextData struct {
+	Files          []ContextFile   // Project files such as CLAUDE.md
+	CompletedTasks []CompletedTask // Tasks of the plan done so far, in plan order
+	ChangedFiles   []string        // Files the completed tasks changed, sorted
+	DesignExcerpts []string        // Design doc sections that mention the task
+}
+
+// ContextFile is a project file included in the prompt.
+type ContextFile struct {
+	Path    string
+	Content string
+}
+
+// CompletedTask is a task of the plan that is already done.
+type CompletedTask struct {
+	ID            string
+	Title         string
+	CommitMessage string // Empty when no commit was recorded
+}
+
+// PlanData describes the plan a task belongs to.
+type PlanData struct {
+	ID          string
+	Name        string
+	Description string
+	SourceFile  string // Design doc the plan was created from
+}
+
+// Failure is one failed attempt of a task.
+type Failure struct {
+	Attempt int
+	Error   string
+}
+
+// RepoData describes the repository the agent works in.
+type RepoData struct {
+	Root   string
+	Branch string // Empty when it can't be determined
+}
+
+// ExtractionData is what extract.tmpl is rendered with.
+type ExtractionData struct {
+	DesignDoc  string // Contents of the design doc
+	SourceFile string // Path of the design doc
+	Repo       RepoData
+}
+
+// ReviewData is what review.tmpl is rendered with.
+type ReviewData struct {
+	Plan PlanData
+	Task plan.Task
+	// DesignSection is as in TaskData.
+	DesignSection string
+	Attempt       int
+	// Report is the implementing agent's completion report, or nil when it
+	// gave none.
+	Report *plan.CompletionReport
+	// Diff is the attempt's uncommitted changes, untracked files included.
+	Diff string
+	Repo RepoData
+}
+
+// Set holds the task, extraction and review templates.
+type Set struct {
+	task       *template.Template
+	extraction *template.Template
+	review     *template.Template
+}
+
+// Default returns the built-in templates.
+func Default() *Set {
+	return defaultSet()
+}
+
+var defaultSet = sync.OnceValue(func() *Set {
+	s, err := load("")
+	if err != nil {
+		// The defaults are embedded at build time and covered by tests.
+		panic(err)
+	}
+	return s
+})
+
+// Load returns the built-in templates, replaced by any override in
+// rafaDir/prompts.
+func Load(rafaDir string) (*Set, error) {
+	return load(filepath.Join(rafaDir, DirName))
+}
+
+func load(overrideDir string) (*Set, error) {
+	task, err := parse(TaskTemplate, overrideDir)
+	if err != nil {
+		return nil, err
+	}
+	extraction, err := parse(ExtractionTemplate, overrideDir)
+	if err != nil {
+		return nil, err
+	}
+	review, err := parse(ReviewTemplate, overrideDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Set{task: task, extraction: extraction, review: review}, nil
+}
+
+// parse reads the override of name in overrideDir if there is one, or the
+// embedded default.
+func parse(name, overrideDir string) (*template.Template, error) {
+	source := "templates/" + name
+	text, err := defaultTemplates.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	if overrideDir != "" {
+		path := filepath.Join(overrideDir, name)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			source, text = path, data
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read prompt template: %w", err)
+		}
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", source, err)
+	}
+	return tmpl, nil
+}
+
+// RenderTask renders the prompt for a task attempt.
+func (s *Set) RenderTask(data TaskData) (string, error) {
+	return render(s.task, data)
+}
+
+// RenderExtraction renders the prompt for extracting a plan.
+func (s *Set) RenderExtraction(data ExtractionData) (string, error) {
+	return render(s.extraction, data)
+}
+
+// RenderReview renders the prompt for reviewing an attempt's changes.
+func (s *Set) RenderReview(data ReviewData) (string, error) {
+	return render(s.review, data)
+}
+
+func render(tmpl *template.Template, data interface{}) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,192 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+func testTaskData() TaskData {
+	return TaskData{
+		Plan: PlanData{ID: "abc123", Name: "auth", Description: "Add auth", SourceFile: "docs/auth.md"},
+		Task: plan.Task{
+			ID:                 "t01",
+			Title:              "Add login",
+			Description:        "Create the login endpoint",
+			AcceptanceCriteria: []string{"Tests pass", "Linting passes"},
+		},
+		TaskNumber:  1,
+		TaskCount:   3,
+		Attempt:     1,
+		MaxAttempts: 5,
+		PlanContext: "Plan: auth\nDescription: Add auth\nSource: docs/auth.md",
+		Repo:        RepoData{Root: "/repo", Branch: "main"},
+	}
+}
+
+func writeOverride(t *testing.T, rafaDir, name, text string) {
+	t.Helper()
+	dir := filepath.Join(rafaDir, DirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefault_RenderTask(t *testing.T) {
+	got, err := Default().RenderTask(testTaskData())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		"## Context\nPlan: auth\nDescription: Add auth\nSource: docs/auth.md\n## Your Task\n",
+		"**ID**: t01\n",
+		"**Attempt**: 1 of 5\n",
+		"1. Tests pass\n2. Linting passes\n\n## Instructions",
+		"RAFA_COMPLETION_REPORT\n{\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Previous attempts") {
+		t.Error("first attempt should have no retry note")
+	}
+}
+
+func TestDefault_RenderTaskPaths(t *testing.T) {
+	data := testTaskData()
+	data.Paths = plan.PathPolicy{Allowed: []string{"internal/**", "cmd/**"}, Forbidden: []string{".github/**"}}
+	got, err := Default().RenderTask(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := "2. Linting passes\n\n## Paths\n" +
+		"Your changes are checked against these path patterns relative to the repository root:\n" +
+		"- Only change paths matching: `internal/**`, `cmd/**`\n" +
+		"- Never change paths matching: `.github/**`\n\n## Instructions"
+	if !strings.Contains(got, want) {
+		t.Errorf("expected prompt to contain %q, got:\n%s", want, got)
+	}
+}
+
+func TestDefault_RenderTaskRetry(t *testing.T) {
+	data := testTaskData()
+	data.Attempt = 3
+	data.Failures = []Failure{{Attempt: 1}, {Attempt: 2, Error: "claude exited with error: exit status 1"}}
+
+	got, err := Default().RenderTask(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(got, "Previous attempts to complete this task failed.") {
+		t.Errorf("expected retry note, got:\n%s", got)
+	}
+	if !strings.Contains(got, "- Attempt 2 failed: claude exited with error: exit status 1\n\n## Acceptance Criteria") {
+		t.Errorf("expected failure history, got:\n%s", got)
+	}
+	if strings.Contains(got, "Attempt 1 failed") {
+		t.Error("failures without an error should not be listed")
+	}
+}
+
+func TestDefault_RenderExtraction(t *testing.T) {
+	got, err := Default().RenderExtraction(ExtractionData{DesignDoc: "# Auth\nAdd login.", SourceFile: "docs/auth.md"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(got, "DESIGN DOCUMENT:\n# Auth\nAdd login.\n") || !strings.Contains(got, "PLAN_APPROVED_JSON:") {
+		t.Errorf("unexpected extraction prompt:\n%s", got)
+	}
+}
+
+func TestDefault_RenderReview(t *testing.T) {
+	data := testTaskData()
+	review := ReviewData{
+		Plan:    data.Plan,
+		Task:    data.Task,
+		Attempt: 2,
+		Diff:    "+++ b/login.go\n+func Login() {}",
+	}
+	got, err := Default().RenderReview(review)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		"**ID**: t01\n",
+		"**Attempt**: 2\n",
+		"1. Tests pass\n2. Linting passes\n",
+		"```diff\n+++ b/login.go\n+func Login() {}\n```",
+		"RAFA_REVIEW\n{",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Implementer's Report") {
+		t.Error("expected no report section without a report")
+	}
+
+	review.Report = &plan.CompletionReport{
+		Criteria: []plan.CriterionResult{{Criterion: "Tests pass", Met: true, Evidence: "go test"}, {Criterion: "Linting passes", Met: false}},
+		Risks:    []string{"No load test"},
+	}
+	got, err = Default().RenderReview(review)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := "- Tests pass: met (go test)\n- Linting passes: not met\n- Risk: No load test\n"; !strings.Contains(got, want) {
+		t.Errorf("expected prompt to contain %q, got:\n%s", want, got)
+	}
+}
+
+func TestLoad_Override(t *testing.T) {
+	rafaDir := t.TempDir()
+	writeOverride(t, rafaDir, TaskTemplate, "{{.Task.ID}} on {{.Repo.Branch}}, task {{.TaskNumber}}/{{.TaskCount}}")
+
+	s, err := Load(rafaDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	got, err := s.RenderTask(testTaskData())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != "t01 on main, task 1/3" {
+		t.Errorf("got %q", got)
+	}
+
+	// The extraction template wasn't overridden.
+	got, err = s.RenderExtraction(ExtractionData{DesignDoc: "doc"})
+	if err != nil || !strings.Contains(got, "DESIGN DOCUMENT:\ndoc") {
+		t.Errorf("expected default extraction template, got %q (%v)", got, err)
+	}
+}
+
+func TestLoad_ParseError(t *testing.T) {
+	rafaDir := t.TempDir()
+	writeOverride(t, rafaDir, ExtractionTemplate, "{{.DesignDoc")
+
+	_, err := Load(rafaDir)
+	if err == nil || !strings.Contains(err.Error(), "failed to parse prompt template") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
+
+func TestRender_UnknownField(t *testing.T) {
+	rafaDir := t.TempDir()
+	writeOverride(t, rafaDir, TaskTemplate, "{{.Task.Nope}}")
+
+	s, err := Load(rafaDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := s.RenderTask(testTaskData()); err == nil || !strings.Contains(err.Error(), "failed to render prompt template task.tmpl") {
+		t.Errorf("expected render error, got %v", err)
+	}
+}
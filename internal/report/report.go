@@ -0,0 +1,235 @@
+// Package report builds run analytics for a plan from its progress.log and
+// captured agent output, and renders them as Markdown or JSON.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// Report is the run analytics of one plan. Durations are in seconds so the
+// JSON form is easy to consume from scripts.
+type Report struct {
+	PlanID          string      `json:"planId"` // Plan folder name ("shortID-name")
+	PlanName        string      `json:"planName"`
+	Status          string      `json:"status"`
+	Runs            int         `json:"runs"`
+	WallTimeSeconds float64     `json:"wallTimeSeconds"`
+	TotalAttempts   int         `json:"totalAttempts"`
+	RetryRate       float64     `json:"retryRate"` // Share of attempts that were retries, 0 to 1
+	InputTokens     int64       `json:"inputTokens"`
+	OutputTokens    int64       `json:"outputTokens"`
+	CostUSD         float64     `json:"costUsd"`
+	Tasks           []TaskStats `json:"tasks"`
+	// FailureHotSpots lists the IDs of tasks with failed attempts, most
+	// failures first.
+	FailureHotSpots []string `json:"failureHotSpots"`
+}
+
+// TaskStats is the run analytics of one task.
+type TaskStats struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	Failures int    `json:"failures"`
+	// AttemptsToSuccess is how many attempts the task took to succeed, or 0
+	// if it has not succeeded.
+	AttemptsToSuccess int     `json:"attemptsToSuccess"`
+	DurationSeconds   float64 `json:"durationSeconds"`
+	InputTokens       int64   `json:"inputTokens"`
+	OutputTokens      int64   `json:"outputTokens"`
+	CostUSD           float64 `json:"costUsd"`
+}
+
+// Build computes the report for the plan in planDir. Timing and attempts come
+// from progress.log; token usage and cost come from the captured output.
+func Build(planDir string) (*Report, error) {
+	p, err := plan.ReadPlan(planDir)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := plan.NewProgressReader(planDir).Stats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress log: %w", err)
+	}
+	attempts, err := executor.ReadTranscript(planDir, plan.AttemptOutputs(stats))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output: %w", err)
+	}
+	return assemble(filepath.Base(planDir), p, stats, attempts), nil
+}
+
+// assemble joins plan metadata, progress statistics and transcript usage.
+// Tasks are listed in plan order, followed by any task that only appears in
+// the progress log.
+func assemble(planID string, p *plan.Plan, stats *plan.ProgressStats, attempts []executor.TranscriptAttempt) *Report {
+	r := &Report{
+		PlanID:          planID,
+		PlanName:        p.Name,
+		Status:          p.Status,
+		Runs:            len(stats.Runs),
+		WallTimeSeconds: stats.WallTime().Seconds(),
+		TotalAttempts:   stats.TotalAttempts(),
+		RetryRate:       stats.RetryRate(),
+		Tasks:           []TaskStats{},
+		FailureHotSpots: []string{},
+	}
+
+	byID := make(map[string]*plan.TaskStats)
+	for i := range stats.Tasks {
+		byID[stats.Tasks[i].TaskID] = &stats.Tasks[i]
+	}
+	usage := make(map[string]*TaskStats)
+	for _, a := range attempts {
+		u, ok := usage[a.TaskID]
+		if !ok {
+			u = &TaskStats{}
+			usage[a.TaskID] = u
+		}
+		for _, e := range a.Entries {
+			if e.Kind == executor.TranscriptUsage {
+				u.InputTokens += e.InputTokens
+				u.OutputTokens += e.OutputTokens
+				u.CostUSD += e.CostUSD
+			}
+		}
+	}
+
+	add := func(id, title, status string) {
+		t := TaskStats{ID: id, Title: title, Status: status}
+		if s, ok := byID[id]; ok {
+			t.Attempts = len(s.Attempts)
+			t.Failures = s.Failures
+			t.AttemptsToSuccess = s.AttemptsToSuccess
+			t.DurationSeconds = s.Duration.Seconds()
+			delete(byID, id)
+		}
+		if u, ok := usage[id]; ok {
+			t.InputTokens = u.InputTokens
+			t.OutputTokens = u.OutputTokens
+			t.CostUSD = u.CostUSD
+		}
+		r.InputTokens += t.InputTokens
+		r.OutputTokens += t.OutputTokens
+		r.CostUSD += t.CostUSD
+		r.Tasks = append(r.Tasks, t)
+	}
+	for _, task := range p.Tasks {
+		add(task.ID, task.Title, task.Status)
+	}
+	for _, s := range stats.Tasks {
+		if _, ok := byID[s.TaskID]; ok {
+			add(s.TaskID, "", "")
+		}
+	}
+
+	for _, hot := range stats.FailureHotSpots() {
+		r.FailureHotSpots = append(r.FailureHotSpots, hot.TaskID)
+	}
+	return r
+}
+
+// Task returns the stats of the task with the given ID.
+func (r *Report) Task(id string) (TaskStats, bool) {
+	for _, t := range r.Tasks {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return TaskStats{}, false
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes the report as Markdown suitable for a pull request
+// description.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## Run report: %s\n\n", r.PlanName)
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Status | %s |\n", r.Status)
+	fmt.Fprintf(&b, "| Runs | %d |\n", r.Runs)
+	fmt.Fprintf(&b, "| Wall time | %s |\n", FormatDuration(r.WallTimeSeconds))
+	fmt.Fprintf(&b, "| Attempts | %d (%.0f%% retries) |\n", r.TotalAttempts, r.RetryRate*100)
+	fmt.Fprintf(&b, "| Tokens | %s in, %s out |\n", FormatTokens(r.InputTokens), FormatTokens(r.OutputTokens))
+	fmt.Fprintf(&b, "| Cost | $%.2f |\n", r.CostUSD)
+
+	b.WriteString("\n### Tasks\n\n")
+	b.WriteString("| Task | Title | Status | Attempts | To success | Duration | Tokens | Cost |\n")
+	b.WriteString("|---|---|---|---:|---:|---:|---:|---:|\n")
+	for _, t := range r.Tasks {
+		toSuccess := "-"
+		if t.AttemptsToSuccess > 0 {
+			toSuccess = fmt.Sprintf("%d", t.AttemptsToSuccess)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %s | %s | %s | $%.2f |\n",
+			t.ID, escapeCell(t.Title), t.Status, t.Attempts, toSuccess,
+			FormatDuration(t.DurationSeconds), FormatTokens(t.InputTokens+t.OutputTokens), t.CostUSD)
+	}
+
+	if len(r.FailureHotSpots) > 0 {
+		b.WriteString("\n### Failure hot spots\n\n")
+		for _, id := range r.FailureHotSpots {
+			t, _ := r.Task(id)
+			label := id
+			if t.Title != "" {
+				label += " " + t.Title
+			}
+			fmt.Fprintf(&b, "- %s: %d failed %s\n", label, t.Failures, plural(t.Failures, "attempt", "attempts"))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// FormatDuration formats seconds as MM:SS, or HH:MM:SS for an hour or more.
+func FormatDuration(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second)).Round(time.Second)
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+
+	if h > 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
+
+// FormatTokens formats token counts in a human-readable format (e.g., "12.4k").
+func FormatTokens(tokens int64) string {
+	if tokens >= 1000000 {
+		return fmt.Sprintf("%.1fM", float64(tokens)/1000000)
+	}
+	if tokens >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+	}
+	return fmt.Sprintf("%d", tokens)
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+const reportOutputFixture = `
+=== Task t01, Attempt 1 ===
+Started: 2024-01-15T10:00:00Z
+
+{"type":"result","subtype":"error","usage":{"input_tokens":1000,"output_tokens":200},"total_cost_usd":0.5}
+
+=== Task t01: FAILED ===
+
+
+=== Task t01, Attempt 2 ===
+Started: 2024-01-15T10:04:00Z
+
+{"type":"result","subtype":"success","usage":{"input_tokens":2000,"output_tokens":300},"total_cost_usd":0.75}
+
+=== Task t01: SUCCESS ===
+
+`
+
+const reportProgressFixture = `{"timestamp":"2024-01-15T10:00:00Z","event":"plan_started","data":{"plan_id":"abc123"}}
+{"timestamp":"2024-01-15T10:00:00Z","event":"task_started","data":{"task_id":"t01","attempt":1}}
+{"timestamp":"2024-01-15T10:03:00Z","event":"task_failed","data":{"task_id":"t01","attempt":1}}
+{"timestamp":"2024-01-15T10:04:00Z","event":"task_started","data":{"task_id":"t01","attempt":2}}
+{"timestamp":"2024-01-15T10:06:30Z","event":"task_completed","data":{"task_id":"t01"}}
+{"timestamp":"2024-01-15T10:07:00Z","event":"plan_failed","data":{"task_id":"t02","attempts":0}}
+`
+
+func setupReportPlan(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "abc123-auth")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create plan dir: %v", err)
+	}
+	p := &plan.Plan{
+		SchemaVersion: plan.CurrentSchemaVersion,
+		ID:            "abc123",
+		Name:          "auth",
+		CreatedAt:     time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+		Status:        plan.PlanStatusFailed,
+		Tasks: []plan.Task{
+			{ID: "t01", Title: "Harden | sessions", Status: plan.TaskStatusCompleted, Attempts: 2},
+			{ID: "t02", Title: "Write docs", Status: plan.TaskStatusPending},
+		},
+	}
+	if err := plan.SavePlan(dir, p); err != nil {
+		t.Fatalf("failed to save plan: %v", err)
+	}
+	files := map[string]string{"output.log": reportOutputFixture, "progress.log": reportProgressFixture}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestBuild(t *testing.T) {
+	r, err := Build(setupReportPlan(t))
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	if r.PlanID != "abc123-auth" || r.PlanName != "auth" || r.Status != plan.PlanStatusFailed {
+		t.Errorf("unexpected header: %+v", r)
+	}
+	if r.Runs != 1 || r.WallTimeSeconds != 420 || r.TotalAttempts != 2 || r.RetryRate != 0.5 {
+		t.Errorf("unexpected totals: %+v", r)
+	}
+	if r.InputTokens != 3000 || r.OutputTokens != 500 || r.CostUSD != 1.25 {
+		t.Errorf("unexpected usage: %d in, %d out, $%.2f", r.InputTokens, r.OutputTokens, r.CostUSD)
+	}
+
+	if len(r.Tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %+v", r.Tasks)
+	}
+	t01 := r.Tasks[0]
+	if t01.Attempts != 2 || t01.Failures != 1 || t01.AttemptsToSuccess != 2 || t01.DurationSeconds != 330 || t01.CostUSD != 1.25 {
+		t.Errorf("t01 = %+v", t01)
+	}
+	if t02 := r.Tasks[1]; t02.Attempts != 0 || t02.Status != plan.TaskStatusPending {
+		t.Errorf("t02 = %+v", t02)
+	}
+	if len(r.FailureHotSpots) != 1 || r.FailureHotSpots[0] != "t01" {
+		t.Errorf("FailureHotSpots = %v", r.FailureHotSpots)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	r, err := Build(setupReportPlan(t))
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := r.WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown() error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"## Run report: auth",
+		"| Wall time | 07:00 |",
+		"| Attempts | 2 (50% retries) |",
+		"| Tokens | 3.0k in, 500 out |",
+		"| Cost | $1.25 |",
+		`| t01 | Harden \| sessions | completed | 2 | 2 | 05:30 | 3.5k | $1.25 |`,
+		"| t02 | Write docs | pending | 0 | - | 00:00 | 0 | $0.00 |",
+		"- t01 Harden | sessions: 1 failed attempt\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	r, err := Build(setupReportPlan(t))
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if decoded.TotalAttempts != 2 || len(decoded.Tasks) != 2 || decoded.Tasks[0].AttemptsToSuccess != 2 {
+		t.Errorf("unexpected decoded report: %+v", decoded)
+	}
+}
+
+func TestBuild_EmptyPlan(t *testing.T) {
+	dir := t.TempDir()
+	if err := plan.SavePlan(dir, &plan.Plan{SchemaVersion: plan.CurrentSchemaVersion, Name: "empty"}); err != nil {
+		t.Fatalf("failed to save plan: %v", err)
+	}
+	r, err := Build(dir)
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if r.Runs != 0 || r.TotalAttempts != 0 || len(r.Tasks) != 0 {
+		t.Errorf("expected empty report, got %+v", r)
+	}
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil || !strings.Contains(buf.String(), `"tasks": []`) {
+		t.Errorf("expected empty task array, got %s (%v)", buf.String(), err)
+	}
+}
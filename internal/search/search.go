@@ -0,0 +1,302 @@
+// Package search builds a full-text index over plans: task metadata from
+// plan.json, tool calls and assistant text from the captured agent output,
+// and progress.log events.
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+// Kind identifies what a document was indexed from.
+type Kind string
+
+const (
+	KindPlan     Kind = "plan"     // Plan name and description
+	KindTask     Kind = "task"     // Task title, description and acceptance criteria
+	KindTool     Kind = "tool"     // A tool call and its target
+	KindText     Kind = "text"     // Assistant text
+	KindProgress Kind = "progress" // A progress.log event
+)
+
+// Kinds lists every document kind, in display order.
+var Kinds = []Kind{KindPlan, KindTask, KindTool, KindText, KindProgress}
+
+// ParseKind validates a kind name.
+func ParseKind(s string) (Kind, error) {
+	for _, k := range Kinds {
+		if string(k) == s {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown kind %q (expected plan, task, tool, text or progress)", s)
+}
+
+// snippetWidth is roughly how many characters of context a hit's snippet shows.
+const snippetWidth = 80
+
+// document is one searchable unit of text.
+type document struct {
+	planID    string
+	planName  string
+	taskID    string
+	taskTitle string
+	attempt   int
+	position  int
+	kind      Kind
+	text      string
+	lower     string
+}
+
+// Hit is a document matching a query.
+type Hit struct {
+	PlanID    string `json:"planId"` // Plan folder name ("shortID-name")
+	PlanName  string `json:"planName,omitempty"`
+	TaskID    string `json:"taskId,omitempty"`
+	TaskTitle string `json:"taskTitle,omitempty"`
+	Attempt   int    `json:"attempt,omitempty"`
+	// Position is the 1-based index of the matching attempt in the plan's
+	// transcript (see executor.LoadTranscript), or 0 when the hit is not tied
+	// to a recorded attempt.
+	Position int    `json:"position,omitempty"`
+	Kind     Kind   `json:"kind"`
+	Snippet  string `json:"snippet"`
+}
+
+// Query selects documents. Every whitespace-separated term of Text must occur
+// in a document (case-insensitive) for it to match.
+type Query struct {
+	Text   string
+	PlanID string // Restrict to one plan folder; empty searches all plans
+	Kinds  []Kind // Restrict to these kinds; empty searches all kinds
+	Limit  int    // Maximum number of hits; 0 means no limit
+}
+
+// Index is an in-memory full-text index over one or more plans.
+type Index struct {
+	docs []document
+}
+
+// Build indexes every plan folder in planDirs. Folders whose plan.json cannot
+// be read are still indexed from their output and progress log.
+func Build(planDirs []string) (*Index, error) {
+	idx := &Index{}
+	for _, dir := range planDirs {
+		if err := idx.Add(dir); err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+// Add indexes a single plan folder.
+func (idx *Index) Add(planDir string) error {
+	planID := filepath.Base(planDir)
+
+	// An unreadable plan.json leaves p nil; the output is still worth indexing.
+	p, _ := plan.ReadPlan(planDir)
+	planName := ""
+	titles := make(map[string]string)
+	if p != nil {
+		planName = p.Name
+		for _, t := range p.Tasks {
+			titles[t.ID] = t.Title
+		}
+	}
+
+	attempts, err := executor.LoadTranscript(planDir)
+	if err != nil {
+		return fmt.Errorf("failed to read output of %s: %w", planID, err)
+	}
+	// The transcript viewer opens tasks on their latest attempt.
+	latest := make(map[string]int)
+	positions := make(map[string]int)
+	for i, a := range attempts {
+		latest[a.TaskID] = i + 1
+		positions[fmt.Sprintf("%s#%d", a.TaskID, a.Attempt)] = i + 1
+	}
+
+	add := func(taskID string, attempt, position int, kind Kind, text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		idx.docs = append(idx.docs, document{
+			planID:    planID,
+			planName:  planName,
+			taskID:    taskID,
+			taskTitle: titles[taskID],
+			attempt:   attempt,
+			position:  position,
+			kind:      kind,
+			text:      text,
+			lower:     strings.ToLower(text),
+		})
+	}
+
+	if p != nil {
+		add("", 0, 0, KindPlan, p.Name+"\n"+p.Description)
+		for _, t := range p.Tasks {
+			text := t.ID + " " + t.Title + "\n" + t.Description + "\n" + strings.Join(t.AcceptanceCriteria, "\n")
+			add(t.ID, 0, latest[t.ID], KindTask, text)
+		}
+	}
+
+	for i, a := range attempts {
+		for _, e := range a.Entries {
+			switch e.Kind {
+			case executor.TranscriptToolUse:
+				text := e.ToolName
+				if e.ToolTarget != "" {
+					text += ": " + e.ToolTarget
+				}
+				add(a.TaskID, a.Attempt, i+1, KindTool, text)
+			case executor.TranscriptText:
+				add(a.TaskID, a.Attempt, i+1, KindText, e.Text)
+			}
+		}
+	}
+
+	events, err := plan.ReadProgressEvents(planDir)
+	if err != nil {
+		return fmt.Errorf("failed to read progress log of %s: %w", planID, err)
+	}
+	for _, ev := range events {
+		taskID, _ := ev.Data["task_id"].(string)
+		attempt := 0
+		if n, ok := ev.Data["attempt"].(float64); ok {
+			attempt = int(n)
+		}
+		position := latest[taskID]
+		if attempt > 0 {
+			if pos, ok := positions[fmt.Sprintf("%s#%d", taskID, attempt)]; ok {
+				position = pos
+			}
+		}
+		add(taskID, attempt, position, KindProgress, formatProgressEvent(ev))
+	}
+	return nil
+}
+
+// formatProgressEvent renders an event as "<event> key=value ..." with keys
+// sorted, so event names and data are both searchable.
+func formatProgressEvent(ev plan.ProgressEvent) string {
+	keys := make([]string, 0, len(ev.Data))
+	for k := range ev.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(ev.Event)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, ev.Data[k])
+	}
+	return b.String()
+}
+
+// Len returns the number of indexed documents.
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search returns the documents matching q in index order: plans in the order
+// they were added, and within a plan its metadata, transcript and progress
+// events in the order they were recorded.
+func (idx *Index) Search(q Query) []Hit {
+	terms := strings.Fields(strings.ToLower(q.Text))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var hits []Hit
+	for _, d := range idx.docs {
+		if q.PlanID != "" && d.planID != q.PlanID {
+			continue
+		}
+		if len(q.Kinds) > 0 && !containsKind(q.Kinds, d.kind) {
+			continue
+		}
+		if !matchesAll(d.lower, terms) {
+			continue
+		}
+		hits = append(hits, Hit{
+			PlanID:    d.planID,
+			PlanName:  d.planName,
+			TaskID:    d.taskID,
+			TaskTitle: d.taskTitle,
+			Attempt:   d.attempt,
+			Position:  d.position,
+			Kind:      d.kind,
+			Snippet:   snippet(d.text, d.lower, terms[0]),
+		})
+		if q.Limit > 0 && len(hits) == q.Limit {
+			break
+		}
+	}
+	return hits
+}
+
+func containsKind(kinds []Kind, k Kind) bool {
+	for _, candidate := range kinds {
+		if candidate == k {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAll(lower string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(lower, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// snippet returns a single-line excerpt of text around the first occurrence
+// of term.
+func snippet(text, lower, term string) string {
+	start := 0
+	// Byte offsets in lower only map onto text when lowering kept lengths.
+	if len(lower) == len(text) {
+		if i := strings.Index(lower, term); i > 0 {
+			start = i
+		}
+	}
+
+	from := start - snippetWidth/4
+	if from < 0 {
+		from = 0
+	}
+	to := from + snippetWidth
+	if to > len(text) {
+		to = len(text)
+	}
+	// Keep the excerpt on rune boundaries.
+	for from > 0 && !isRuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !isRuneStart(text[to]) {
+		to++
+	}
+
+	excerpt := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		excerpt = "…" + excerpt
+	}
+	if to < len(text) {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
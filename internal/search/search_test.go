@@ -0,0 +1,185 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+const searchOutputFixture = `
+=== Task t01, Attempt 1 ===
+Started: 2024-01-15T10:00:00Z
+
+{"type":"assistant","message":{"content":[{"id":"tool_1","type":"tool_use","name":"Edit","input":{"file_path":"auth/session.go"}}]}}
+
+=== Task t01: FAILED ===
+
+
+=== Task t01, Attempt 2 ===
+Started: 2024-01-15T10:05:00Z
+
+{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"Running the Migration now."}}}
+{"type":"stream_event","event":{"type":"content_block_stop"}}
+{"type":"assistant","message":{"content":[{"id":"tool_2","type":"tool_use","name":"Read","input":{"file_path":"auth/session.go"}}]}}
+
+=== Task t01: SUCCESS ===
+
+`
+
+func setupSearchPlan(t *testing.T, root, folder string) string {
+	t.Helper()
+	dir := filepath.Join(root, folder)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create plan dir: %v", err)
+	}
+	p := &plan.Plan{
+		SchemaVersion: plan.CurrentSchemaVersion,
+		ID:            "abc123",
+		Name:          "auth-rework",
+		Description:   "Rework session handling",
+		CreatedAt:     time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+		Status:        plan.PlanStatusCompleted,
+		Tasks: []plan.Task{
+			{ID: "t01", Title: "Harden sessions", Description: "Rotate session tokens", AcceptanceCriteria: []string{"Tokens rotate on login"}, Status: plan.TaskStatusCompleted},
+			{ID: "t02", Title: "Write docs", Status: plan.TaskStatusPending},
+		},
+	}
+	if err := plan.SavePlan(dir, p); err != nil {
+		t.Fatalf("failed to save plan: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "output.log"), []byte(searchOutputFixture), 0644); err != nil {
+		t.Fatalf("failed to write output.log: %v", err)
+	}
+	logger := plan.NewProgressLogger(dir)
+	if err := logger.TaskFailed("t01", 1); err != nil {
+		t.Fatalf("failed to log progress: %v", err)
+	}
+	return dir
+}
+
+func TestBuildAndSearch(t *testing.T) {
+	dir := setupSearchPlan(t, t.TempDir(), "abc123-auth-rework")
+	idx, err := Build([]string{dir})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	hits := idx.Search(Query{Text: "auth/session.go"})
+	if len(hits) != 2 {
+		t.Fatalf("expected 2 tool hits, got %+v", hits)
+	}
+	first := hits[0]
+	if first.PlanID != "abc123-auth-rework" || first.PlanName != "auth-rework" || first.TaskID != "t01" ||
+		first.TaskTitle != "Harden sessions" || first.Attempt != 1 || first.Position != 1 || first.Kind != KindTool {
+		t.Errorf("unexpected first hit: %+v", first)
+	}
+	if first.Snippet != "Edit: auth/session.go" {
+		t.Errorf("Snippet = %q", first.Snippet)
+	}
+	if hits[1].Attempt != 2 || hits[1].Position != 2 {
+		t.Errorf("unexpected second hit: %+v", hits[1])
+	}
+
+	text := idx.Search(Query{Text: "migration"})
+	if len(text) != 1 || text[0].Kind != KindText || text[0].Position != 2 {
+		t.Errorf("expected one assistant text hit, got %+v", text)
+	}
+
+	task := idx.Search(Query{Text: "rotate LOGIN"})
+	if len(task) != 1 || task[0].Kind != KindTask || task[0].Position != 2 {
+		t.Errorf("expected task hit on the latest attempt, got %+v", task)
+	}
+
+	progress := idx.Search(Query{Text: "task_failed"})
+	if len(progress) != 1 || progress[0].Kind != KindProgress || progress[0].Position != 1 {
+		t.Errorf("expected progress hit on attempt 1, got %+v", progress)
+	}
+
+	if docs := idx.Search(Query{Text: "docs"}); len(docs) != 1 || docs[0].TaskID != "t02" || docs[0].Position != 0 {
+		t.Errorf("expected unrun task hit without position, got %+v", docs)
+	}
+}
+
+func TestSearch_Filters(t *testing.T) {
+	root := t.TempDir()
+	idx, err := Build([]string{
+		setupSearchPlan(t, root, "abc123-auth-rework"),
+		setupSearchPlan(t, root, "def456-auth-rework"),
+	})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	if hits := idx.Search(Query{Text: "session"}); len(hits) < 4 {
+		t.Errorf("expected hits from both plans, got %d", len(hits))
+	}
+	for _, h := range idx.Search(Query{Text: "session", PlanID: "def456-auth-rework"}) {
+		if h.PlanID != "def456-auth-rework" {
+			t.Errorf("plan filter leaked hit: %+v", h)
+		}
+	}
+	for _, h := range idx.Search(Query{Text: "session", Kinds: []Kind{KindTool}}) {
+		if h.Kind != KindTool {
+			t.Errorf("kind filter leaked hit: %+v", h)
+		}
+	}
+	if hits := idx.Search(Query{Text: "session", Limit: 3}); len(hits) != 3 {
+		t.Errorf("expected limit of 3, got %d", len(hits))
+	}
+	if hits := idx.Search(Query{Text: "  "}); hits != nil {
+		t.Errorf("expected no hits for an empty query, got %+v", hits)
+	}
+}
+
+func TestBuild_DoesNotRewritePlan(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "abc123-legacy")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create plan dir: %v", err)
+	}
+	legacy := map[string]interface{}{
+		"id":    "abc123",
+		"name":  "legacy",
+		"tasks": []map[string]interface{}{{"id": "t01", "title": "Old task", "status": "pending"}},
+	}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(filepath.Join(dir, "plan.json"), data, 0644); err != nil {
+		t.Fatalf("failed to write plan.json: %v", err)
+	}
+
+	idx, err := Build([]string{dir})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if hits := idx.Search(Query{Text: "old task"}); len(hits) != 1 {
+		t.Errorf("expected legacy task to be indexed, got %+v", hits)
+	}
+	after, _ := os.ReadFile(filepath.Join(dir, "plan.json"))
+	if string(after) != string(data) {
+		t.Errorf("plan.json was rewritten: %s", after)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 20) + "needle" + strings.Repeat(" dolor sit", 20)
+	got := snippet(text, strings.ToLower(text), "needle")
+	if !strings.Contains(got, "needle") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("snippet = %q", got)
+	}
+	if got := snippet("short\ntext", "short\ntext", "text"); got != "short text" {
+		t.Errorf("snippet = %q, want whitespace collapsed", got)
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	if k, err := ParseKind("tool"); err != nil || k != KindTool {
+		t.Errorf("ParseKind(tool) = %q, %v", k, err)
+	}
+	if _, err := ParseKind("bogus"); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+}
@@ -0,0 +1,248 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsNamespace prefixes every exported metric.
+const metricsNamespace = "rafa"
+
+// Attempt outcome label values.
+const (
+	outcomeSucceeded = "succeeded"
+	outcomeFailed    = "failed"
+)
+
+// RunMetrics exposes plan execution as Prometheus metrics. It implements
+// executor.ExecutorEvents, and its ToolUse and Usage methods match the
+// executor.StreamHooks callbacks. Counters accumulate across every run of the
+// process, as Prometheus expects.
+//
+// A nil *RunMetrics is valid and records nothing.
+type RunMetrics struct {
+	registry *prometheus.Registry
+
+	tasksCompleted  prometheus.Counter
+	tasksFailed     prometheus.Counter
+	attempts        *prometheus.CounterVec
+	currentTask     prometheus.Gauge
+	inputTokens     prometheus.Counter
+	outputTokens    prometheus.Counter
+	costUSD         prometheus.Counter
+	toolCalls       *prometheus.CounterVec
+	attemptDuration *prometheus.HistogramVec
+
+	maxAttempts int // Attempts after which a failed task is given up on
+
+	mu           sync.Mutex
+	attemptStart time.Time
+	seenTools    map[string]bool // Tool use IDs counted in the current attempt
+	now          func() time.Time
+}
+
+// NewRunMetrics creates the metrics on a dedicated registry. maxAttempts is
+// the executor's attempt limit, after which a failed attempt fails its task.
+func NewRunMetrics(maxAttempts int) *RunMetrics {
+	m := &RunMetrics{
+		registry:    prometheus.NewRegistry(),
+		maxAttempts: maxAttempts,
+		tasksCompleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "tasks_completed_total",
+			Help:      "Tasks that completed successfully.",
+		}),
+		tasksFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "tasks_failed_total",
+			Help:      "Tasks that failed after exhausting their attempts.",
+		}),
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "attempts_total",
+			Help:      "Finished task attempts by outcome.",
+		}, []string{"outcome"}),
+		currentTask: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "current_task_index",
+			Help:      "1-based index of the task being executed, 0 when idle.",
+		}),
+		inputTokens: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "input_tokens_total",
+			Help:      "Input tokens reported by the agent.",
+		}),
+		outputTokens: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "output_tokens_total",
+			Help:      "Output tokens reported by the agent.",
+		}),
+		costUSD: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "cost_usd_total",
+			Help:      "Agent cost in US dollars.",
+		}),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "tool_calls_total",
+			Help:      "Tool calls made by the agent, by tool name.",
+		}, []string{"tool"}),
+		attemptDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "attempt_duration_seconds",
+			Help:      "Duration of task attempts by outcome.",
+			// Attempts run from seconds to well over an hour.
+			Buckets: []float64{30, 60, 120, 300, 600, 900, 1800, 3600, 7200},
+		}, []string{"outcome"}),
+		seenTools: make(map[string]bool),
+		now:       time.Now,
+	}
+	m.registry.MustRegister(
+		m.tasksCompleted, m.tasksFailed, m.attempts, m.currentTask,
+		m.inputTokens, m.outputTokens, m.costUSD, m.toolCalls, m.attemptDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *RunMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// OnTaskStart implements executor.ExecutorEvents.
+func (m *RunMetrics) OnTaskStart(taskNum, total int, task *plan.Task, attempt int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.currentTask.Set(float64(taskNum))
+	m.attemptStart = m.now()
+	m.seenTools = make(map[string]bool)
+}
+
+// OnTaskComplete implements executor.ExecutorEvents.
+func (m *RunMetrics) OnTaskComplete(task *plan.Task) {
+	if m == nil {
+		return
+	}
+	m.tasksCompleted.Inc()
+	m.endAttempt(outcomeSucceeded)
+}
+
+// OnTaskFailed implements executor.ExecutorEvents.
+func (m *RunMetrics) OnTaskFailed(task *plan.Task, attempt int, err error) {
+	if m == nil {
+		return
+	}
+	if attempt >= m.maxAttempts {
+		m.tasksFailed.Inc()
+	}
+	m.endAttempt(outcomeFailed)
+}
+
+func (m *RunMetrics) endAttempt(outcome string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts.WithLabelValues(outcome).Inc()
+	if !m.attemptStart.IsZero() {
+		m.attemptDuration.WithLabelValues(outcome).Observe(m.now().Sub(m.attemptStart).Seconds())
+		m.attemptStart = time.Time{}
+	}
+}
+
+// OnOutput implements executor.ExecutorEvents.
+func (m *RunMetrics) OnOutput(line string) {}
+
+// OnPlanComplete implements executor.ExecutorEvents.
+func (m *RunMetrics) OnPlanComplete(succeeded, total int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.currentTask.Set(0)
+}
+
+// OnPlanFailed implements executor.ExecutorEvents. The failed task, if any,
+// was already counted by OnTaskFailed; a run blocked by a hook made no
+// attempt and fails no task.
+func (m *RunMetrics) OnPlanFailed(task *plan.Task, reason string) {
+	if m == nil {
+		return
+	}
+	m.currentTask.Set(0)
+}
+
+// ToolUse counts a tool call. Repeated reports of the same tool use ID
+// within an attempt are counted once. It matches
+// executor.StreamHooks.OnToolUse.
+func (m *RunMetrics) ToolUse(toolID, parentToolID, toolName, toolTarget string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if toolID != "" {
+		if m.seenTools[toolID] {
+			return
+		}
+		m.seenTools[toolID] = true
+	}
+	m.toolCalls.WithLabelValues(toolName).Inc()
+}
+
+// Usage adds tokens and cost. It matches executor.StreamHooks.OnUsage.
+func (m *RunMetrics) Usage(inputTokens, outputTokens int64, costUSD float64) {
+	if m == nil {
+		return
+	}
+	m.inputTokens.Add(float64(inputTokens))
+	m.outputTokens.Add(float64(outputTokens))
+	if costUSD > 0 {
+		m.costUSD.Add(costUSD)
+	}
+}
+
+// MetricsServer serves RunMetrics over HTTP at /metrics.
+type MetricsServer struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// ServeMetrics starts an HTTP listener on addr (e.g. ":9090") exposing m at
+// /metrics. It returns once the listener is bound.
+func ServeMetrics(addr string, m *RunMetrics) (*MetricsServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	s := &MetricsServer{
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		listener: listener,
+	}
+	// Serve only fails once the listener is closed; the TUI owns the
+	// terminal, so there is nowhere useful to report it.
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *MetricsServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server.
+func (s *MetricsServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
@@ -0,0 +1,120 @@
+package telemetry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablasso/rafa/internal/plan"
+)
+
+func scrape(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read scrape: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("scrape status %d: %s", resp.StatusCode, body)
+	}
+	return string(body)
+}
+
+func TestRunMetrics_ServesPrometheusMetrics(t *testing.T) {
+	m := NewRunMetrics(2)
+	clock := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return clock }
+
+	server, err := ServeMetrics("127.0.0.1:0", m)
+	if err != nil {
+		t.Fatalf("ServeMetrics() error: %v", err)
+	}
+	defer server.Close()
+
+	t01 := &plan.Task{ID: "t01"}
+	t02 := &plan.Task{ID: "t02"}
+
+	// t01 fails once after 90s, then succeeds after 45s.
+	m.OnTaskStart(1, 2, t01, 1)
+	m.ToolUse("tool_1", "", "Read", "")
+	m.ToolUse("tool_1", "", "Read", "main.go")
+	m.ToolUse("tool_2", "", "Bash", "go test ./...")
+	m.Usage(1000, 200, 0.5)
+	clock = clock.Add(90 * time.Second)
+	m.OnTaskFailed(t01, 1, errors.New("tests failed"))
+	m.OnTaskStart(1, 2, t01, 2)
+	m.ToolUse("tool_1", "", "Read", "main.go")
+	m.Usage(500, 100, 0.25)
+	clock = clock.Add(45 * time.Second)
+	m.OnTaskComplete(t01)
+	m.OnTaskStart(2, 2, t02, 1)
+
+	out := scrape(t, "http://"+server.Addr()+"/metrics")
+	for _, want := range []string{
+		"rafa_tasks_completed_total 1",
+		"rafa_tasks_failed_total 0",
+		`rafa_attempts_total{outcome="failed"} 1`,
+		`rafa_attempts_total{outcome="succeeded"} 1`,
+		"rafa_current_task_index 2",
+		"rafa_input_tokens_total 1500",
+		"rafa_output_tokens_total 300",
+		"rafa_cost_usd_total 0.75",
+		`rafa_tool_calls_total{tool="Read"} 2`,
+		`rafa_tool_calls_total{tool="Bash"} 1`,
+		`rafa_attempt_duration_seconds_bucket{outcome="failed",le="60"} 0`,
+		`rafa_attempt_duration_seconds_bucket{outcome="failed",le="120"} 1`,
+		`rafa_attempt_duration_seconds_sum{outcome="succeeded"} 45`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q:\n%s", want, out)
+		}
+	}
+
+	// A hook blocking the run fails no task.
+	m.OnPlanFailed(t02, "before_task hook failed")
+	out = scrape(t, "http://"+server.Addr()+"/metrics")
+	if !strings.Contains(out, "rafa_tasks_failed_total 0") {
+		t.Errorf("expected a blocked run not to count as a task failure:\n%s", out)
+	}
+
+	// t02 fails its last attempt.
+	m.OnTaskStart(2, 2, t02, 2)
+	m.OnTaskFailed(t02, 2, errors.New("boom"))
+	m.OnPlanFailed(t02, "failed after 2 attempts")
+	out = scrape(t, "http://"+server.Addr()+"/metrics")
+	for _, want := range []string{"rafa_tasks_failed_total 1", `rafa_attempts_total{outcome="failed"} 2`, "rafa_current_task_index 0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q after failure", want)
+		}
+	}
+}
+
+func TestRunMetrics_NilIsNoop(t *testing.T) {
+	var m *RunMetrics
+	m.OnTaskStart(1, 1, &plan.Task{}, 1)
+	m.ToolUse("a", "", "Read", "")
+	m.Usage(1, 1, 1)
+	m.OnTaskFailed(&plan.Task{}, 1, errors.New("x"))
+	m.OnTaskComplete(&plan.Task{})
+	m.OnPlanFailed(&plan.Task{}, "")
+	m.OnPlanComplete(1, 1, time.Second)
+}
+
+func TestServeMetrics_AddressInUse(t *testing.T) {
+	first, err := ServeMetrics("127.0.0.1:0", NewRunMetrics(2))
+	if err != nil {
+		t.Fatalf("ServeMetrics() error: %v", err)
+	}
+	defer first.Close()
+	if _, err := ServeMetrics(first.Addr(), NewRunMetrics(2)); err == nil {
+		t.Error("expected error when the address is in use")
+	}
+}
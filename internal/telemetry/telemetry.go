@@ -0,0 +1,87 @@
+// Package telemetry exports plan runs to external observability tooling: as
+// OpenTelemetry traces over OTLP/HTTP, so long runs can be inspected in tools
+// such as Jaeger or Tempo, and as Prometheus metrics for dashboards.
+//
+// A plan run is the root span, each task attempt is a child span and each
+// tool use reported by the agent stream is nested under its attempt (or under
+// the subagent tool that issued it).
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pablasso/rafa/internal/version"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ServiceName is the service.name resource attribute of exported spans.
+const ServiceName = "rafa"
+
+// tracesPath is the OTLP/HTTP path appended to endpoints given without one.
+const tracesPath = "/v1/traces"
+
+// endpointEnvVars enable tracing when no endpoint is configured explicitly.
+// The exporter reads them itself.
+var endpointEnvVars = []string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"}
+
+// Enabled reports whether traces should be exported, either because an
+// endpoint was configured or because the standard OTLP environment variables
+// are set.
+func Enabled(endpoint string) bool {
+	if endpoint != "" {
+		return true
+	}
+	for _, name := range endpointEnvVars {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// NewProvider creates a tracer provider that batches spans to the OTLP/HTTP
+// collector at endpoint (e.g. "http://localhost:4318"). An empty endpoint
+// defers to the OTEL_EXPORTER_OTLP_* environment variables. Callers must
+// Shutdown the provider to flush pending spans.
+func NewProvider(ctx context.Context, endpoint string) (*sdktrace.TracerProvider, error) {
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		u, err := endpointURL(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracehttp.WithEndpointURL(u))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	res := resource.NewSchemaless(
+		attribute.String("service.name", ServiceName),
+		attribute.String("service.version", version.Version),
+	)
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// endpointURL validates endpoint and appends the OTLP traces path when the
+// URL has none.
+func endpointURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid OTLP endpoint %q (expected a URL like http://localhost:4318)", endpoint)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = tracesPath
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,221 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pablasso/rafa/internal/plan"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpReceiver is an in-process OTLP/HTTP collector that keeps every span it
+// receives.
+type otlpReceiver struct {
+	mu    sync.Mutex
+	paths []string
+	spans []*tracepb.Span
+}
+
+func (r *otlpReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var export collectortrace.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &export); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.paths = append(r.paths, req.URL.Path)
+	for _, rs := range export.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			r.spans = append(r.spans, ss.Spans...)
+		}
+	}
+	r.mu.Unlock()
+
+	resp, _ := proto.Marshal(&collectortrace.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(resp)
+}
+
+func (r *otlpReceiver) span(t *testing.T, name string) *tracepb.Span {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("span %q not received", name)
+	return nil
+}
+
+func attr(s *tracepb.Span, key string) string {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value.GetStringValue()
+		}
+	}
+	return ""
+}
+
+func intAttr(s *tracepb.Span, key string) int64 {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value.GetIntValue()
+		}
+	}
+	return -1
+}
+
+func TestTracer_ExportsRunToOTLPReceiver(t *testing.T) {
+	receiver := &otlpReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	provider, err := NewProvider(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("NewProvider() error: %v", err)
+	}
+
+	p := &plan.Plan{ID: "abc123", Name: "auth"}
+	task := &plan.Task{ID: "t01", Title: "Add login"}
+
+	tracer := NewTracer(provider)
+	tracer.StartRun(context.Background(), p, "claude")
+	tracer.StartAttempt(task, 1)
+	tracer.ToolUse("tool_task", "", "Task", "")
+	tracer.ToolUse("tool_task", "", "Task", "explore auth")
+	tracer.ToolUse("tool_read", "tool_task", "Read", "internal/auth.go")
+	tracer.ToolResult("tool_read")
+	tracer.ToolResult("tool_task")
+	tracer.Usage(1000, 200, 0.5)
+	tracer.EndAttempt(errors.New("tests failed"))
+	tracer.StartAttempt(task, 2)
+	tracer.Usage(500, 100, 0.25)
+	tracer.EndAttempt(nil)
+	tracer.EndRun(plan.RunOutcomeCompleted, nil)
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error: %v", err)
+	}
+
+	if len(receiver.paths) == 0 || receiver.paths[0] != tracesPath {
+		t.Fatalf("expected export to %s, got %v", tracesPath, receiver.paths)
+	}
+	if len(receiver.spans) != 5 {
+		t.Fatalf("expected 5 spans (run, 2 attempts, 2 tools), got %d", len(receiver.spans))
+	}
+
+	run := receiver.span(t, "plan auth")
+	if len(run.ParentSpanId) != 0 {
+		t.Error("expected the run to be a root span")
+	}
+	if attr(run, AttrOutcome) != plan.RunOutcomeCompleted || attr(run, AttrBackend) != "claude" {
+		t.Errorf("unexpected run attributes: %v", run.Attributes)
+	}
+	if intAttr(run, AttrInputTokens) != 1500 || intAttr(run, AttrOutputTokens) != 300 {
+		t.Errorf("expected run usage to sum attempts, got %v", run.Attributes)
+	}
+
+	var attempts []*tracepb.Span
+	for _, s := range receiver.spans {
+		if s.Name == "task t01" {
+			attempts = append(attempts, s)
+		}
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempt spans, got %d", len(attempts))
+	}
+	first := attempts[0]
+	if intAttr(first, AttrAttempt) != 1 {
+		first = attempts[1]
+	}
+	if string(first.ParentSpanId) != string(run.SpanId) {
+		t.Error("expected attempts to be children of the run")
+	}
+	if first.Status.GetCode() != tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("expected failed attempt to have error status, got %v", first.Status)
+	}
+	if intAttr(first, AttrInputTokens) != 1000 {
+		t.Errorf("expected attempt tokens, got %v", first.Attributes)
+	}
+
+	subagent := receiver.span(t, "tool Task")
+	if string(subagent.ParentSpanId) != string(first.SpanId) {
+		t.Error("expected tool span to be a child of its attempt")
+	}
+	if attr(subagent, AttrToolTarget) != "explore auth" {
+		t.Errorf("expected repeated tool use to fill in the target, got %v", subagent.Attributes)
+	}
+	read := receiver.span(t, "tool Read")
+	if string(read.ParentSpanId) != string(subagent.SpanId) {
+		t.Error("expected subagent tool span to nest under the Task tool")
+	}
+	if attr(read, AttrToolTarget) != "internal/auth.go" {
+		t.Errorf("unexpected tool target: %v", read.Attributes)
+	}
+}
+
+func TestTracer_NilIsNoop(t *testing.T) {
+	var tracer *Tracer
+	tracer.StartRun(context.Background(), &plan.Plan{}, "")
+	tracer.StartAttempt(&plan.Task{}, 1)
+	tracer.ToolUse("a", "", "Read", "x")
+	tracer.ToolResult("a")
+	tracer.Usage(1, 1, 1)
+	tracer.EndAttempt(nil)
+	tracer.EndRun(plan.RunOutcomeCompleted, nil)
+}
+
+func TestEndpointURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{endpoint: "http://localhost:4318", want: "http://localhost:4318/v1/traces"},
+		{endpoint: "http://localhost:4318/", want: "http://localhost:4318/v1/traces"},
+		{endpoint: "https://tempo.example.com/otlp/v1/traces", want: "https://tempo.example.com/otlp/v1/traces"},
+		{endpoint: "localhost:4318", wantErr: true},
+		{endpoint: "ftp://localhost", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := endpointURL(tt.endpoint)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("endpointURL(%q): expected error", tt.endpoint)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("endpointURL(%q) = %q, %v; want %q", tt.endpoint, got, err, tt.want)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	if Enabled("") {
+		t.Error("expected tracing off without endpoint")
+	}
+	if !Enabled("http://localhost:4318") {
+		t.Error("expected tracing on with endpoint")
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	if !Enabled("") {
+		t.Error("expected tracing on from environment")
+	}
+}
@@ -0,0 +1,223 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pablasso/rafa/internal/plan"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies rafa's instrumentation scope.
+const tracerName = "github.com/pablasso/rafa"
+
+// Span attribute keys.
+const (
+	AttrPlanID       = "rafa.plan.id"
+	AttrPlanName     = "rafa.plan.name"
+	AttrBackend      = "rafa.backend"
+	AttrOutcome      = "rafa.outcome"
+	AttrTaskID       = "rafa.task.id"
+	AttrTaskTitle    = "rafa.task.title"
+	AttrAttempt      = "rafa.attempt"
+	AttrToolName     = "rafa.tool.name"
+	AttrToolTarget   = "rafa.tool.target"
+	AttrInputTokens  = "rafa.tokens.input"
+	AttrOutputTokens = "rafa.tokens.output"
+	AttrCostUSD      = "rafa.cost_usd"
+)
+
+// Tracer records the spans of a single plan run. It is safe for concurrent
+// use: stream hooks fire from the output goroutine while the executor drives
+// plan and attempt boundaries.
+//
+// A nil *Tracer is valid and records nothing, so callers don't need to check
+// whether tracing is enabled.
+type Tracer struct {
+	tracer trace.Tracer
+
+	mu      sync.Mutex
+	ctx     context.Context
+	run     trace.Span
+	attempt trace.Span
+	tools   map[string]trace.Span // Open tool spans by tool use ID
+
+	runInput, runOutput         int64
+	runCost                     float64
+	attemptInput, attemptOutput int64
+	attemptCost                 float64
+}
+
+// NewTracer creates a run tracer using provider.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: provider.Tracer(tracerName),
+		tools:  make(map[string]trace.Span),
+	}
+}
+
+// StartRun opens the root span of a plan run.
+func (t *Tracer) StartRun(ctx context.Context, p *plan.Plan, backend string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attrs := []attribute.KeyValue{
+		attribute.String(AttrPlanID, p.ID),
+		attribute.String(AttrPlanName, p.Name),
+	}
+	if backend != "" {
+		attrs = append(attrs, attribute.String(AttrBackend, backend))
+	}
+	t.ctx, t.run = t.tracer.Start(ctx, "plan "+p.Name, trace.WithAttributes(attrs...))
+	t.runInput, t.runOutput, t.runCost = 0, 0, 0
+}
+
+// StartAttempt opens a span for one attempt of task under the run span.
+func (t *Tracer) StartAttempt(task *plan.Task, attempt int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.run == nil {
+		return
+	}
+	t.endAttemptLocked(nil)
+	_, t.attempt = t.tracer.Start(t.ctx, "task "+task.ID,
+		trace.WithAttributes(
+			attribute.String(AttrTaskID, task.ID),
+			attribute.String(AttrTaskTitle, task.Title),
+			attribute.Int(AttrAttempt, attempt),
+		))
+	t.attemptInput, t.attemptOutput, t.attemptCost = 0, 0, 0
+}
+
+// EndAttempt closes the current attempt span, marking it as an error when
+// err is non-nil.
+func (t *Tracer) EndAttempt(err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endAttemptLocked(err)
+}
+
+func (t *Tracer) endAttemptLocked(err error) {
+	if t.attempt == nil {
+		return
+	}
+	// Tools still open when the attempt ends never reported a result.
+	for id, span := range t.tools {
+		span.End()
+		delete(t.tools, id)
+	}
+	t.attempt.SetAttributes(usageAttributes(t.attemptInput, t.attemptOutput, t.attemptCost)...)
+	if err != nil {
+		t.attempt.RecordError(err)
+		t.attempt.SetStatus(codes.Error, err.Error())
+	}
+	t.attempt.End()
+	t.attempt = nil
+}
+
+// EndRun closes the current attempt, if any, and the run span. outcome is one
+// of the plan.RunOutcome* values; err, when non-nil, marks the run as failed.
+func (t *Tracer) EndRun(outcome string, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.run == nil {
+		return
+	}
+	t.endAttemptLocked(nil)
+	t.run.SetAttributes(attribute.String(AttrOutcome, outcome))
+	t.run.SetAttributes(usageAttributes(t.runInput, t.runOutput, t.runCost)...)
+	if err != nil {
+		t.run.RecordError(err)
+		t.run.SetStatus(codes.Error, err.Error())
+	}
+	t.run.End()
+	t.run = nil
+}
+
+// ToolUse opens a span for a tool call. Calls made by a subagent are nested
+// under the tool span that started the subagent. The stream may report a tool
+// use twice (the streamed block, then the full message carrying the input);
+// the repeat only fills in the target. It matches executor.StreamHooks.OnToolUse.
+func (t *Tracer) ToolUse(toolID, parentToolID, toolName, toolTarget string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.attempt == nil {
+		return
+	}
+	if span, ok := t.tools[toolID]; ok {
+		if toolTarget != "" {
+			span.SetAttributes(attribute.String(AttrToolTarget, toolTarget))
+		}
+		return
+	}
+	parent := t.attempt
+	if p, ok := t.tools[parentToolID]; ok && parentToolID != "" {
+		parent = p
+	}
+	attrs := []attribute.KeyValue{attribute.String(AttrToolName, toolName)}
+	if toolTarget != "" {
+		attrs = append(attrs, attribute.String(AttrToolTarget, toolTarget))
+	}
+	ctx := trace.ContextWithSpan(t.ctx, parent)
+	_, span := t.tracer.Start(ctx, "tool "+toolName, trace.WithAttributes(attrs...))
+	if toolID == "" {
+		span.End()
+		return
+	}
+	t.tools[toolID] = span
+}
+
+// ToolResult closes the span of a tool call. It matches
+// executor.StreamHooks.OnToolResult.
+func (t *Tracer) ToolResult(toolID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if span, ok := t.tools[toolID]; ok {
+		span.End()
+		delete(t.tools, toolID)
+	}
+}
+
+// Usage adds token counts and cost to the current attempt and the run. It
+// matches executor.StreamHooks.OnUsage.
+func (t *Tracer) Usage(inputTokens, outputTokens int64, costUSD float64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.attemptInput += inputTokens
+	t.attemptOutput += outputTokens
+	t.attemptCost += costUSD
+	t.runInput += inputTokens
+	t.runOutput += outputTokens
+	t.runCost += costUSD
+}
+
+func usageAttributes(input, output int64, cost float64) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64(AttrInputTokens, input),
+		attribute.Int64(AttrOutputTokens, output),
+		attribute.Float64(AttrCostUSD, cost),
+	}
+}
@@ -10,10 +10,17 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/pablasso/rafa/internal/api"
+	"github.com/pablasso/rafa/internal/config"
 	"github.com/pablasso/rafa/internal/demo"
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/notify"
 	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+	"github.com/pablasso/rafa/internal/telemetry"
 	"github.com/pablasso/rafa/internal/tui/msgs"
 	"github.com/pablasso/rafa/internal/tui/views"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Minimum terminal size requirements
@@ -35,6 +42,9 @@ const (
 	ViewPlanCreate
 	ViewPlanList
 	ViewRunning
+	ViewTranscript
+	ViewSearch
+	ViewReport
 )
 
 // Model is the main Bubble Tea model that orchestrates all views.
@@ -51,17 +61,83 @@ type Model struct {
 	planCreate views.PlanCreateModel
 	planList   views.PlanListModel
 	running    views.RunningModel
+	transcript views.TranscriptModel
+	search     views.SearchModel
+	report     views.ReportModel
 
 	// Shared state
 	repoRoot string
 	rafaDir  string
 	err      error
+
+	// tracerProvider traces plan runs; nil when tracing is disabled.
+	tracerProvider trace.TracerProvider
+	// runMetrics is exported over --metrics-addr; nil when disabled.
+	runMetrics *telemetry.RunMetrics
+	// apiServer serves plan status and run events; nil when disabled.
+	apiServer *api.Server
+	// config is the project configuration in .rafa/config.json.
+	config *config.Config
+	// notifier sends the configured notifications.
+	notifier *notify.Dispatcher
+	// prompts are the agent prompt templates, with .rafa/prompts overrides.
+	prompts *prompt.Set
 }
 
 // Run starts the TUI application.
 func Run(opts Options) error {
 	m := initialModelWithOptions(opts)
 
+	if telemetry.Enabled(opts.OTLPEndpoint) {
+		provider, err := telemetry.NewProvider(context.Background(), opts.OTLPEndpoint)
+		if err != nil {
+			return fmt.Errorf("failed to set up tracing: %w", err)
+		}
+		// Flush spans of the last run before exiting.
+		defer provider.Shutdown(context.Background())
+		m.tracerProvider = provider
+	}
+
+	if opts.MetricsAddr != "" {
+		m.runMetrics = telemetry.NewRunMetrics(executor.MaxAttempts)
+		server, err := telemetry.ServeMetrics(opts.MetricsAddr, m.runMetrics)
+		if err != nil {
+			return fmt.Errorf("failed to start metrics listener: %w", err)
+		}
+		defer server.Close()
+	}
+
+	if opts.APIAddr != "" {
+		token := opts.APIToken
+		if token == "" {
+			token = os.Getenv(api.TokenEnvVar)
+		}
+		m.apiServer = api.New(m.rafaDir, token)
+		if err := m.apiServer.Start(opts.APIAddr); err != nil {
+			return fmt.Errorf("failed to start API server: %w", err)
+		}
+		defer m.apiServer.Close()
+	}
+
+	if m.rafaDir != "" {
+		cfg, err := config.Load(m.rafaDir)
+		if err != nil {
+			return err
+		}
+		notifier, err := notify.New(cfg.Notifications, m.repoRoot)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", config.FileName, err)
+		}
+		prompts, err := prompt.Load(m.rafaDir)
+		if err != nil {
+			return err
+		}
+		cfg.Env = cfg.Env.InDir(m.repoRoot)
+		m.config = cfg
+		m.notifier = notifier
+		m.prompts = prompts
+	}
+
 	Program = tea.NewProgram(
 		m,
 		tea.WithAltScreen(),
@@ -142,6 +218,12 @@ func (m Model) Init() tea.Cmd {
 		base = m.planList.Init()
 	case ViewRunning:
 		base = m.running.Init()
+	case ViewTranscript:
+		base = m.transcript.Init()
+	case ViewSearch:
+		base = m.search.Init()
+	case ViewReport:
+		base = m.report.Init()
 	}
 
 	if m.initCmd == nil {
@@ -207,6 +289,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case msgs.FileSelectedMsg:
 		m.currentView = ViewPlanCreate
 		m.planCreate = views.NewPlanCreateModel(msg.Path)
+		m.planCreate.SetPrompts(m.prompts, m.repoRoot)
+		if m.config != nil {
+			m.planCreate.SetPermissions(m.config.Permissions.ExtractionPermissions())
+			m.planCreate.SetEnv(m.config.Env)
+		}
 		m.planCreate.SetSize(m.width, m.height)
 		return m, m.planCreate.Init()
 
@@ -219,6 +306,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case msgs.RunPlanMsg:
 		return m.transitionToRunning(msg.PlanID)
 
+	case msgs.GoToTranscriptMsg:
+		m.currentView = ViewTranscript
+		m.transcript = views.NewTranscriptModel(filepath.Join(m.rafaDir, "plans", msg.PlanID))
+		m.transcript.SetSize(m.width, m.height)
+		if msg.FromSearch {
+			m.transcript.OpenSearchHit(msg.Position, msg.Query)
+		}
+		return m, m.transcript.Init()
+
+	case msgs.GoToReportMsg:
+		m.currentView = ViewReport
+		m.report = views.NewReportModel(filepath.Join(m.rafaDir, "plans", msg.PlanID))
+		m.report.SetSize(m.width, m.height)
+		return m, m.report.Init()
+
+	case msgs.GoToSearchMsg:
+		m.currentView = ViewSearch
+		if !msg.Resume {
+			m.search = views.NewSearchModel(m.rafaDir)
+		}
+		m.search.SetSize(m.width, m.height)
+		return m, m.search.Init()
+
 	}
 
 	// Delegate all other messages to the current view
@@ -355,6 +465,16 @@ func (m Model) transitionToRunning(planID string) (tea.Model, tea.Cmd) {
 	m.currentView = ViewRunning
 	m.running = views.NewRunningModel(shortID, planName, p.Tasks, planDir, p)
 	m.running.SetSize(m.width, m.height)
+	if m.tracerProvider != nil {
+		m.running.SetTracer(telemetry.NewTracer(m.tracerProvider))
+	}
+	m.running.SetMetrics(m.runMetrics)
+	if m.apiServer != nil {
+		m.running.SetAPI(m.apiServer)
+	}
+	m.running.SetConfig(m.config)
+	m.running.SetNotifier(m.notifier)
+	m.running.SetPrompts(m.prompts)
 
 	// Start the executor in a background goroutine
 	return m, tea.Batch(
@@ -391,6 +511,15 @@ func (m Model) propagateWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.running, cmd = m.running.Update(msg)
 		return m, cmd
+	case ViewTranscript:
+		m.transcript.SetSize(msg.Width, msg.Height)
+		return m, nil
+	case ViewSearch:
+		m.search.SetSize(msg.Width, msg.Height)
+		return m, nil
+	case ViewReport:
+		m.report.SetSize(msg.Width, msg.Height)
+		return m, nil
 	}
 	return m, nil
 }
@@ -418,6 +547,18 @@ func (m Model) delegateToCurrentView(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.running, cmd = m.running.Update(msg)
 		return m, cmd
+	case ViewTranscript:
+		var cmd tea.Cmd
+		m.transcript, cmd = m.transcript.Update(msg)
+		return m, cmd
+	case ViewSearch:
+		var cmd tea.Cmd
+		m.search, cmd = m.search.Update(msg)
+		return m, cmd
+	case ViewReport:
+		var cmd tea.Cmd
+		m.report, cmd = m.report.Update(msg)
+		return m, cmd
 	}
 	return m, nil
 }
@@ -440,6 +581,12 @@ func (m Model) View() string {
 		return m.planList.View()
 	case ViewRunning:
 		return m.running.View()
+	case ViewTranscript:
+		return m.transcript.View()
+	case ViewSearch:
+		return m.search.View()
+	case ViewReport:
+		return m.report.View()
 	}
 	return "Unknown view"
 }
@@ -288,3 +288,74 @@ func TestInitialModelWithOptions_DemoCreateStartsOnPlanCreate(t *testing.T) {
 		t.Fatalf("expected demo create source file to be set")
 	}
 }
+
+func TestModel_GoToTranscriptMsg(t *testing.T) {
+	m := initialModel()
+	m.rafaDir = filepath.Join(t.TempDir(), ".rafa")
+	m.width = 100
+	m.height = 40
+
+	updated, _ := m.Update(msgs.GoToTranscriptMsg{PlanID: "abc123-missing"})
+	m = updated.(Model)
+	if m.currentView != ViewTranscript {
+		t.Fatalf("expected ViewTranscript, got %v", m.currentView)
+	}
+	if !strings.Contains(m.View(), "Transcript: abc123-missing") {
+		t.Errorf("expected transcript view, got:\n%s", m.View())
+	}
+
+	// Esc returns to the plan list.
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected a command from esc")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.currentView != ViewPlanList {
+		t.Errorf("expected ViewPlanList, got %v", m.currentView)
+	}
+}
+
+func TestModel_GoToSearchMsg(t *testing.T) {
+	m := initialModel()
+	m.rafaDir = filepath.Join(t.TempDir(), ".rafa")
+	m.width = 100
+	m.height = 40
+
+	updated, _ := m.Update(msgs.GoToSearchMsg{})
+	m = updated.(Model)
+	if m.currentView != ViewSearch {
+		t.Fatalf("expected ViewSearch, got %v", m.currentView)
+	}
+	m.search.SetQuery("parser")
+
+	// Opening a hit and coming back keeps the query.
+	updated, _ = m.Update(msgs.GoToTranscriptMsg{PlanID: "abc123-missing", FromSearch: true, Query: "parser"})
+	m = updated.(Model)
+	if m.currentView != ViewTranscript {
+		t.Fatalf("expected ViewTranscript, got %v", m.currentView)
+	}
+	updated, _ = m.Update(msgs.GoToSearchMsg{Resume: true})
+	m = updated.(Model)
+	if m.currentView != ViewSearch || m.search.Query() != "parser" {
+		t.Errorf("expected to resume search, got view %v query %q", m.currentView, m.search.Query())
+	}
+}
+
+func TestModel_GoToReportMsg(t *testing.T) {
+	m := initialModel()
+	m.rafaDir = filepath.Join(t.TempDir(), ".rafa")
+	m.width = 100
+	m.height = 40
+
+	updated, _ := m.Update(msgs.GoToReportMsg{PlanID: "abc123-missing"})
+	m = updated.(Model)
+	if m.currentView != ViewReport {
+		t.Fatalf("expected ViewReport, got %v", m.currentView)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Report: abc123-missing") || !strings.Contains(view, "Failed to build report") {
+		t.Errorf("expected report error view, got:\n%s", view)
+	}
+}
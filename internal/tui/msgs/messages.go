@@ -17,6 +17,29 @@ type GoToFilePickerMsg struct {
 // GoToPlanListMsg signals transition to the plan list view.
 type GoToPlanListMsg struct{}
 
+// GoToTranscriptMsg signals transition to the transcript viewer for a plan.
+// PlanID is the plan folder name ("shortID-name"). Position optionally
+// selects an attempt (1-based, as in search.Hit) and Query highlights its
+// matches. FromSearch makes Esc return to the search overlay.
+type GoToTranscriptMsg struct {
+	PlanID     string
+	Position   int
+	Query      string
+	FromSearch bool
+}
+
+// GoToReportMsg signals transition to the run report for a plan.
+// PlanID is the plan folder name ("shortID-name").
+type GoToReportMsg struct {
+	PlanID string
+}
+
+// GoToSearchMsg signals transition to the search overlay. Resume keeps the
+// previous query and results instead of starting a new search.
+type GoToSearchMsg struct {
+	Resume bool
+}
+
 // FileSelectedMsg is sent when a file is selected in the file picker.
 type FileSelectedMsg struct {
 	Path string
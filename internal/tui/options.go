@@ -5,6 +5,19 @@ import "github.com/pablasso/rafa/internal/demo"
 // Options configures TUI startup behavior.
 type Options struct {
 	Demo *DemoOptions
+	// OTLPEndpoint is the OTLP/HTTP collector that plan runs are traced to.
+	// Empty defers to the OTEL_EXPORTER_OTLP_* environment variables; when
+	// neither is set, tracing is off.
+	OTLPEndpoint string
+	// MetricsAddr, when set, is the address of an HTTP listener exposing
+	// Prometheus metrics of plan runs at /metrics.
+	MetricsAddr string
+	// APIAddr, when set, is the address of the HTTP API serving plan status
+	// and a live event stream.
+	APIAddr string
+	// APIToken guards the API's run control endpoints. Empty falls back to
+	// RAFA_API_TOKEN; without either, run control is disabled.
+	APIToken string
 }
 
 // DemoOptions configure demo mode when starting the TUI.
@@ -14,11 +14,12 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pablasso/rafa/internal/ai"
+	"github.com/pablasso/rafa/internal/git"
 	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
 	"github.com/pablasso/rafa/internal/tui/components"
 	"github.com/pablasso/rafa/internal/tui/msgs"
 	"github.com/pablasso/rafa/internal/tui/styles"
-	"github.com/pablasso/rafa/internal/util"
 )
 
 // PlanCreateState represents the current state of the plan creation flow.
@@ -115,6 +116,14 @@ type PlanCreateModel struct {
 	// Conversation starter (injected for testing)
 	conversationStarter ConversationStarter
 
+	// Extraction prompt template; nil renders the built-in one
+	prompts  *prompt.Set
+	repoRoot string
+
+	// Tool permissions and environment for the extraction conversation
+	permissions ai.Permissions
+	env         ai.EnvPolicy
+
 	// Mode and demo metadata
 	mode    PlanCreateMode
 	warning string
@@ -156,6 +165,7 @@ func newPlanCreateModel(sourceFile string, mode PlanCreateMode, starter Conversa
 		activityView:        components.NewScrollViewport(20, 6, 0),
 		activities:          []ActivityEntry{{Text: "Starting task extraction...", Timestamp: time.Now(), Indent: 0, IsDone: false}},
 		conversationStarter: starter,
+		permissions:         ai.ReadOnlyPermissions(),
 		mode:                mode,
 		warning:             warning,
 	}
@@ -166,6 +176,24 @@ func (m *PlanCreateModel) SetConversationStarter(cs ConversationStarter) {
 	m.conversationStarter = cs
 }
 
+// SetPermissions sets the extraction conversation's tool permissions, which
+// default to read-only tools.
+func (m *PlanCreateModel) SetPermissions(p ai.Permissions) {
+	m.permissions = p
+}
+
+// SetEnv sets the extraction conversation's environment policy.
+func (m *PlanCreateModel) SetEnv(p ai.EnvPolicy) {
+	m.env = p
+}
+
+// SetPrompts renders the extraction prompt from s, with repoRoot as the
+// template's repo info.
+func (m *PlanCreateModel) SetPrompts(s *prompt.Set, repoRoot string) {
+	m.prompts = s
+	m.repoRoot = repoRoot
+}
+
 // Init implements tea.Model.
 func (m PlanCreateModel) Init() tea.Cmd {
 	return tea.Batch(
@@ -209,10 +237,15 @@ func (m *PlanCreateModel) startExtraction() tea.Cmd {
 			designContent = fmt.Sprintf("# Demo source context\n\nSource file: %s", m.sourceFile)
 		}
 
-		prompt := m.buildExtractionPrompt(designContent)
+		prompt, err := m.buildExtractionPrompt(designContent)
+		if err != nil {
+			return PlanCreateErrorMsg{Err: err}
+		}
 
 		config := ai.ConversationConfig{
 			InitialPrompt: prompt,
+			Permissions:   m.permissions,
+			Env:           m.env,
 		}
 
 		conv, events, err := m.conversationStarter.Start(m.ctx, config)
@@ -227,48 +260,21 @@ func (m *PlanCreateModel) startExtraction() tea.Cmd {
 	}
 }
 
-// buildExtractionPrompt creates the prompt for task extraction.
-func (m *PlanCreateModel) buildExtractionPrompt(designContent string) string {
-	var sb strings.Builder
-	sb.WriteString(`You are helping create an execution plan from a technical design document.
-
-DESIGN DOCUMENT:
-`)
-	sb.WriteString(designContent)
-	sb.WriteString(`
-
-`)
-	sb.WriteString(`Extract discrete implementation tasks from this design document.
-
-For each task, show:
-1. Task number and title
-2. Brief description
-3. Acceptance criteria (as a bulleted list)
-
-Present the tasks in implementation order. Size each task to be completable by an AI agent in a single session (roughly 50-60% of context window).
-
-Respond with ONLY the following JSON payload prefixed by PLAN_APPROVED_JSON: (no additional text):
-
-PLAN_APPROVED_JSON:
-{
-  "name": "kebab-case-plan-name",
-  "description": "One sentence description",
-  "tasks": [
-    {
-      "title": "Task title",
-      "description": "Detailed description",
-      "acceptanceCriteria": ["criterion 1", "criterion 2"]
-    }
-  ]
-}
-
-Requirements:
-- The response must include PLAN_APPROVED_JSON:
-- Return valid JSON only after the marker
-- Include at least one task
-- Every task must include non-empty title and at least one acceptance criterion`)
-
-	return sb.String()
+// buildExtractionPrompt renders the extraction template for the design doc.
+func (m *PlanCreateModel) buildExtractionPrompt(designContent string) (string, error) {
+	prompts := m.prompts
+	if prompts == nil {
+		prompts = prompt.Default()
+	}
+	repo := prompt.RepoData{Root: m.repoRoot}
+	if m.repoRoot != "" {
+		repo.Branch, _ = git.CurrentBranch(m.repoRoot)
+	}
+	return prompts.RenderExtraction(prompt.ExtractionData{
+		DesignDoc:  designContent,
+		SourceFile: m.sourceFile,
+		Repo:       repo,
+	})
 }
 
 // forwardEvents reads from a response channel and forwards to the main event channel.
@@ -492,52 +498,22 @@ func (m *PlanCreateModel) savePlan() tea.Cmd {
 			return PlanCreateErrorMsg{Err: fmt.Errorf("no plan to save")}
 		}
 
-		// Generate plan ID
-		id, err := util.GenerateShortID()
-		if err != nil {
-			return PlanCreateErrorMsg{Err: fmt.Errorf("failed to generate plan ID: %w", err)}
-		}
+		// Normalize source path
+		sourcePath := normalizeSourcePath(m.sourceFile)
 
-		// Determine plan name
-		baseName := m.extractedPlan.Name
-		if baseName == "" {
-			base := filepath.Base(m.sourceFile)
-			baseName = strings.TrimSuffix(base, filepath.Ext(base))
+		// Point each task's section at lines of the design doc as it is now.
+		if content, err := os.ReadFile(m.sourceFile); err == nil {
+			m.extractedPlan.ResolveSections(string(content))
 		}
-		baseName = util.ToKebabCase(baseName)
 
-		// Resolve name collisions
-		name, err := plan.ResolvePlanName(baseName)
+		p, err := plan.NewPlanFromExtraction(m.extractedPlan, sourcePath)
 		if err != nil {
-			return PlanCreateErrorMsg{Err: fmt.Errorf("failed to resolve plan name: %w", err)}
-		}
-
-		// Build tasks
-		tasks := make([]plan.Task, len(m.extractedPlan.Tasks))
-		taskTitles := make([]string, len(m.extractedPlan.Tasks))
-		for i, et := range m.extractedPlan.Tasks {
-			tasks[i] = plan.Task{
-				ID:                 util.GenerateTaskID(i),
-				Title:              et.Title,
-				Description:        et.Description,
-				AcceptanceCriteria: et.AcceptanceCriteria,
-				Status:             plan.TaskStatusPending,
-				Attempts:           0,
-			}
-			taskTitles[i] = et.Title
+			return PlanCreateErrorMsg{Err: err}
 		}
 
-		// Normalize source path
-		sourcePath := normalizeSourcePath(m.sourceFile)
-
-		p := &plan.Plan{
-			ID:          id,
-			Name:        name,
-			Description: m.extractedPlan.Description,
-			SourceFile:  sourcePath,
-			CreatedAt:   time.Now(),
-			Status:      plan.PlanStatusNotStarted,
-			Tasks:       tasks,
+		taskTitles := make([]string, len(p.Tasks))
+		for i, t := range p.Tasks {
+			taskTitles[i] = t.Title
 		}
 
 		// Create the plan folder
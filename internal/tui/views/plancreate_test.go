@@ -11,6 +11,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/pablasso/rafa/internal/ai"
 	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/testutil"
 	"github.com/pablasso/rafa/internal/tui/msgs"
 )
 
@@ -84,11 +85,17 @@ func TestPlanCreateModel_InitStartsExtraction(t *testing.T) {
 	if !foundConversationStarted {
 		t.Fatal("expected Init command batch to include PlanCreateConversationStartedMsg")
 	}
+	if perms := starter.lastConfig.Permissions; !perms.Restricted() || len(perms.Tools) != 3 {
+		t.Errorf("expected extraction to default to read-only tools, got %+v", perms)
+	}
 }
 
 func TestPlanCreateModel_BuildExtractionPrompt_OneShot(t *testing.T) {
 	m := NewPlanCreateModel("design.md")
-	prompt := m.buildExtractionPrompt("# Test")
+	prompt, err := m.buildExtractionPrompt("# Test")
+	if err != nil {
+		t.Fatalf("failed to build prompt: %v", err)
+	}
 
 	if strings.Contains(prompt, "USER INSTRUCTIONS:") {
 		t.Fatal("prompt should not include user instructions section")
@@ -101,6 +108,40 @@ func TestPlanCreateModel_BuildExtractionPrompt_OneShot(t *testing.T) {
 	}
 }
 
+func TestPlanCreateModel_SavePlanLinksSections(t *testing.T) {
+	testutil.SetupTestDir(t)
+	if err := os.MkdirAll(filepath.Join(".rafa", "plans"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	design := "# Auth\n\n## Session Storage\nRedis.\n"
+	if err := os.WriteFile("design.md", []byte(design), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewPlanCreateModel("design.md")
+	m.extractedPlan = &plan.TaskExtractionResult{
+		Name: "auth",
+		Tasks: []plan.ExtractedTask{{
+			Title:              "Store sessions",
+			AcceptanceCriteria: []string{"Tests pass"},
+			Section:            &plan.SectionRef{Anchor: "session-storage"},
+		}},
+	}
+	saved, ok := m.savePlan()().(PlanCreateSavedMsg)
+	if !ok {
+		t.Fatal("expected the plan to be saved")
+	}
+
+	p, err := plan.LoadPlan(filepath.Join(".rafa", "plans", saved.PlanID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := plan.SectionRef{Anchor: "session-storage", StartLine: 3, EndLine: 4}
+	if got := p.Tasks[0].Section; got == nil || *got != want {
+		t.Errorf("expected section %+v, got %+v", want, got)
+	}
+}
+
 func TestPlanCreateModel_Update_SavedMsgStaysOnSuccessScreen(t *testing.T) {
 	m := NewPlanCreateModel("design.md")
 	updated, cmd := m.Update(PlanCreateSavedMsg{PlanID: "abc123-test-plan"})
@@ -2,6 +2,7 @@ package views
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -34,6 +35,23 @@ type PlanListModel struct {
 	width        int
 	height       int
 	lockedErrMsg string // temporary error message when trying to select locked plan
+	confirm      *planListConfirm
+	actionMsg    string // result of the last archive/delete action
+	actionErr    bool   // true if actionMsg describes a failure
+}
+
+// planListAction is a destructive plan list action that needs confirmation.
+type planListAction string
+
+const (
+	planListActionArchive planListAction = "archive"
+	planListActionDelete  planListAction = "delete"
+)
+
+// planListConfirm is a pending archive/delete awaiting y/n.
+type planListConfirm struct {
+	action planListAction
+	plan   PlanSummary
 }
 
 // NewPlanListModel creates a new PlanListModel and loads plans from the rafaDir.
@@ -212,8 +230,30 @@ func (m PlanListModel) Update(msg tea.Msg) (PlanListModel, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.confirm != nil {
+			return m.handleConfirmKey(msg)
+		}
+
 		// Handle normal state with plans
 		switch msg.String() {
+		case "t":
+			if m.cursor < len(m.plans) {
+				selected := m.plans[m.cursor]
+				fullPlanID := fmt.Sprintf("%s-%s", selected.ID, selected.Name)
+				return m, func() tea.Msg { return msgs.GoToTranscriptMsg{PlanID: fullPlanID} }
+			}
+		case "r":
+			if m.cursor < len(m.plans) {
+				selected := m.plans[m.cursor]
+				fullPlanID := fmt.Sprintf("%s-%s", selected.ID, selected.Name)
+				return m, func() tea.Msg { return msgs.GoToReportMsg{PlanID: fullPlanID} }
+			}
+		case "/":
+			return m, func() tea.Msg { return msgs.GoToSearchMsg{} }
+		case "a":
+			return m.requestConfirm(planListActionArchive), nil
+		case "d":
+			return m.requestConfirm(planListActionDelete), nil
 		case "esc":
 			return m, func() tea.Msg { return msgs.GoToHomeMsg{} }
 		case "ctrl+c":
@@ -222,11 +262,13 @@ func (m PlanListModel) Update(msg tea.Msg) (PlanListModel, tea.Cmd) {
 			if m.cursor > 0 {
 				m.cursor--
 				m.lockedErrMsg = "" // Clear error on navigation
+				m.actionMsg = ""
 			}
 		case "down", "j":
 			if m.cursor < len(m.plans)-1 {
 				m.cursor++
 				m.lockedErrMsg = "" // Clear error on navigation
+				m.actionMsg = ""
 			}
 		case "enter":
 			if m.cursor < len(m.plans) {
@@ -244,6 +286,78 @@ func (m PlanListModel) Update(msg tea.Msg) (PlanListModel, tea.Cmd) {
 	return m, nil
 }
 
+// requestConfirm asks for confirmation before archiving or deleting the
+// selected plan. Locked plans are rejected immediately.
+func (m PlanListModel) requestConfirm(action planListAction) PlanListModel {
+	if m.cursor >= len(m.plans) {
+		return m
+	}
+	selected := m.plans[m.cursor]
+	m.actionMsg = ""
+	if selected.Locked {
+		m.lockedErrMsg = "Plan is running elsewhere"
+		return m
+	}
+	m.lockedErrMsg = ""
+	m.confirm = &planListConfirm{action: action, plan: selected}
+	return m
+}
+
+// handleConfirmKey resolves a pending confirmation: y runs the action,
+// n or Esc cancels it.
+func (m PlanListModel) handleConfirmKey(msg tea.KeyMsg) (PlanListModel, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		pending := *m.confirm
+		m.confirm = nil
+		return m.runAction(pending), nil
+	case "n", "N", "esc":
+		m.confirm = nil
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// runAction archives or deletes a plan and reloads the list.
+func (m PlanListModel) runAction(c planListConfirm) PlanListModel {
+	folderName := fmt.Sprintf("%s-%s", c.plan.ID, c.plan.Name)
+	planDir := filepath.Join(m.rafaDir, "plans", folderName)
+
+	var err error
+	switch c.action {
+	case planListActionArchive:
+		_, err = plan.ArchivePlan(planDir)
+	case planListActionDelete:
+		err = plan.DeletePlan(planDir)
+	}
+
+	switch {
+	case errors.Is(err, plan.ErrPlanLocked):
+		m.actionMsg = "Plan is running elsewhere"
+		m.actionErr = true
+	case err != nil:
+		m.actionMsg = err.Error()
+		m.actionErr = true
+	case c.action == planListActionArchive:
+		m.actionMsg = fmt.Sprintf("Archived %s", folderName)
+		m.actionErr = false
+	default:
+		m.actionMsg = fmt.Sprintf("Deleted %s", folderName)
+		m.actionErr = false
+	}
+
+	m.plans = m.loadPlansGrouped()
+	if m.cursor >= len(m.plans) {
+		m.cursor = len(m.plans) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	return m
+}
+
 // View implements tea.Model.
 func (m PlanListModel) View() string {
 	if m.width == 0 || m.height == 0 {
@@ -288,11 +402,28 @@ func (m PlanListModel) renderNormalView() string {
 
 	planList := strings.Join(planLines, "\n")
 
-	// Calculate vertical centering (add 2 for potential error message)
+	// Message below the list: confirmation prompt, error or action result
+	var messageLine string
+	switch {
+	case m.confirm != nil:
+		verb := "Archive"
+		if m.confirm.action == planListActionDelete {
+			verb = "Delete"
+		}
+		messageLine = styles.ErrorStyle.Render(fmt.Sprintf("%s %s-%s? (y/n)", verb, m.confirm.plan.ID, m.confirm.plan.Name))
+	case m.lockedErrMsg != "":
+		messageLine = styles.ErrorStyle.Render(m.lockedErrMsg)
+	case m.actionMsg != "" && m.actionErr:
+		messageLine = styles.ErrorStyle.Render(m.actionMsg)
+	case m.actionMsg != "":
+		messageLine = styles.SuccessStyle.Render(m.actionMsg)
+	}
+
+	// Calculate vertical centering (add 2 for potential message)
 	statusBarHeight := 1
 	contentHeight := 2 + len(planLines) // title + spacing + plans + section headings
-	if m.lockedErrMsg != "" {
-		contentHeight += 2 // error message + spacing
+	if messageLine != "" {
+		contentHeight += 2 // message + spacing
 	}
 	availableHeight := m.height - statusBarHeight
 
@@ -307,11 +438,9 @@ func (m PlanListModel) renderNormalView() string {
 	b.WriteString("\n\n")
 	b.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, planList))
 
-	// Show locked error message if present
-	if m.lockedErrMsg != "" {
+	if messageLine != "" {
 		b.WriteString("\n\n")
-		errLine := styles.ErrorStyle.Render(m.lockedErrMsg)
-		b.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, errLine))
+		b.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, messageLine))
 	}
 
 	// Calculate remaining lines for bottom padding
@@ -323,7 +452,10 @@ func (m PlanListModel) renderNormalView() string {
 	b.WriteString(strings.Repeat("\n", bottomPadding))
 
 	// Status bar
-	statusItems := []string{"↑↓ Navigate", "Enter Run", "Esc Back"}
+	statusItems := []string{"↑↓ Navigate", "Enter Run", "t Transcript", "r Report", "/ Search", "a Archive", "d Delete", "Esc Back"}
+	if m.confirm != nil {
+		statusItems = []string{"y Confirm", "n Cancel"}
+	}
 	b.WriteString(components.NewStatusBar().Render(m.width, statusItems))
 
 	return b.String()
@@ -465,3 +597,8 @@ func (m PlanListModel) RafaDir() string {
 func (m PlanListModel) LockedErrMsg() string {
 	return m.lockedErrMsg
 }
+
+// ActionMsg returns the result of the last archive/delete action (if any).
+func (m PlanListModel) ActionMsg() string {
+	return m.actionMsg
+}
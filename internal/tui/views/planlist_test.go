@@ -884,3 +884,134 @@ func TestIsLocked_RemovesInvalidLock(t *testing.T) {
 		t.Fatal("expected invalid lock file to be removed")
 	}
 }
+
+func TestPlanListModel_ArchiveWithConfirmation(t *testing.T) {
+	tmpDir := t.TempDir()
+	rafaDir := filepath.Join(tmpDir, ".rafa")
+	plansDir := filepath.Join(rafaDir, "plans")
+	createTestPlan(t, plansDir, "plan1", "done", plan.PlanStatusCompleted, nil)
+
+	m := NewPlanListModel(rafaDir)
+	m.SetSize(100, 30)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if !strings.Contains(m.View(), "Archive plan1-done? (y/n)") {
+		t.Fatalf("expected confirmation prompt, got:\n%s", m.View())
+	}
+
+	// Cancelling leaves the plan in place.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if _, err := os.Stat(filepath.Join(plansDir, "plan1-done")); err != nil {
+		t.Fatal("plan should not be archived after cancel")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+
+	if _, err := os.Stat(filepath.Join(rafaDir, "archive", "plan1-done", "plan.json")); err != nil {
+		t.Errorf("expected plan in archive: %v", err)
+	}
+	if len(m.Plans()) != 0 {
+		t.Errorf("expected archived plan to leave the list, got %d plans", len(m.Plans()))
+	}
+	if m.ActionMsg() != "Archived plan1-done" {
+		t.Errorf("ActionMsg = %q", m.ActionMsg())
+	}
+}
+
+func TestPlanListModel_DeleteWithConfirmation(t *testing.T) {
+	tmpDir := t.TempDir()
+	rafaDir := filepath.Join(tmpDir, ".rafa")
+	plansDir := filepath.Join(rafaDir, "plans")
+	createTestPlan(t, plansDir, "plan1", "a", plan.PlanStatusNotStarted, nil)
+	createTestPlan(t, plansDir, "plan2", "b", plan.PlanStatusNotStarted, nil)
+
+	m := NewPlanListModel(rafaDir)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+
+	if _, err := os.Stat(filepath.Join(plansDir, "plan2-b")); !os.IsNotExist(err) {
+		t.Error("expected plan2-b to be deleted")
+	}
+	if len(m.Plans()) != 1 || m.Cursor() != 0 {
+		t.Errorf("expected one plan with cursor clamped to 0, got %d plans, cursor %d", len(m.Plans()), m.Cursor())
+	}
+}
+
+func TestPlanListModel_ActionsRefuseLockedPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	rafaDir := filepath.Join(tmpDir, ".rafa")
+	plansDir := filepath.Join(rafaDir, "plans")
+	createTestPlan(t, plansDir, "plan1", "busy", plan.PlanStatusInProgress, nil)
+	writeLiveLockFile(t, filepath.Join(plansDir, "plan1-busy", "run.lock"))
+
+	m := NewPlanListModel(rafaDir)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+
+	if m.confirm != nil {
+		t.Error("locked plan should not prompt for confirmation")
+	}
+	if m.LockedErrMsg() != "Plan is running elsewhere" {
+		t.Errorf("LockedErrMsg = %q", m.LockedErrMsg())
+	}
+	if _, err := os.Stat(filepath.Join(plansDir, "plan1-busy")); err != nil {
+		t.Error("locked plan must not be deleted")
+	}
+}
+
+func TestPlanListModel_TranscriptKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	rafaDir := filepath.Join(tmpDir, ".rafa")
+	plansDir := filepath.Join(rafaDir, "plans")
+	createTestPlan(t, plansDir, "plan1", "busy", plan.PlanStatusInProgress, nil)
+	// Transcripts are read-only, so locked plans can be inspected too.
+	writeLiveLockFile(t, filepath.Join(plansDir, "plan1-busy", "run.lock"))
+
+	m := NewPlanListModel(rafaDir)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg, ok := cmd().(msgs.GoToTranscriptMsg)
+	if !ok {
+		t.Fatalf("expected GoToTranscriptMsg, got %T", cmd())
+	}
+	if msg.PlanID != "plan1-busy" {
+		t.Errorf("PlanID = %q, want plan1-busy", msg.PlanID)
+	}
+}
+
+func TestPlanListModel_SearchKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	rafaDir := filepath.Join(tmpDir, ".rafa")
+	createTestPlan(t, filepath.Join(rafaDir, "plans"), "plan1", "one", plan.PlanStatusNotStarted, nil)
+
+	m := NewPlanListModel(rafaDir)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	if _, ok := cmd().(msgs.GoToSearchMsg); !ok {
+		t.Errorf("expected GoToSearchMsg, got %T", cmd())
+	}
+}
+
+func TestPlanListModel_ReportKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	rafaDir := filepath.Join(tmpDir, ".rafa")
+	createTestPlan(t, filepath.Join(rafaDir, "plans"), "plan1", "one", plan.PlanStatusCompleted, nil)
+
+	m := NewPlanListModel(rafaDir)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg, ok := cmd().(msgs.GoToReportMsg)
+	if !ok {
+		t.Fatalf("expected GoToReportMsg, got %T", cmd())
+	}
+	if msg.PlanID != "plan1-one" {
+		t.Errorf("PlanID = %q, want plan1-one", msg.PlanID)
+	}
+}
@@ -0,0 +1,208 @@
+package views
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pablasso/rafa/internal/report"
+	"github.com/pablasso/rafa/internal/tui/components"
+	"github.com/pablasso/rafa/internal/tui/msgs"
+	"github.com/pablasso/rafa/internal/tui/styles"
+)
+
+// reportMaxLines caps the rendered report.
+const reportMaxLines = 10000
+
+// ReportModel shows the run analytics of a plan: totals, a per-task table and
+// failure hot spots.
+type ReportModel struct {
+	planTitle string
+	report    *report.Report
+	loadErr   string
+	view      components.ScrollViewport
+
+	width  int
+	height int
+}
+
+// NewReportModel builds the report for the plan in planDir.
+func NewReportModel(planDir string) ReportModel {
+	m := ReportModel{
+		planTitle: filepath.Base(planDir),
+		view:      components.NewScrollViewport(0, 0, reportMaxLines),
+	}
+	r, err := report.Build(planDir)
+	if err != nil {
+		m.loadErr = fmt.Sprintf("Failed to build report: %v", err)
+	}
+	m.report = r
+	return m
+}
+
+// Init implements tea.Model.
+func (m ReportModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m ReportModel) Update(msg tea.Msg) (ReportModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Width, msg.Height)
+		return m, nil
+
+	case tea.MouseMsg:
+		if msg.Button != tea.MouseButtonWheelUp && msg.Button != tea.MouseButtonWheelDown {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.view, cmd = m.view.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		key := msg.String()
+		switch key {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q":
+			return m, func() tea.Msg { return msgs.GoToPlanListMsg{} }
+		}
+		if isScrollKey(key) {
+			var cmd tea.Cmd
+			m.view, cmd = m.view.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// Lines returns the unstyled report lines.
+func (m ReportModel) Lines() []string {
+	built := m.buildLines()
+	lines := make([]string, len(built))
+	for i, l := range built {
+		lines[i] = l.text
+	}
+	return lines
+}
+
+// buildLines renders the report as a summary, a task table and the failure
+// hot spots.
+func (m ReportModel) buildLines() []transcriptLine {
+	if m.report == nil {
+		return nil
+	}
+	r := m.report
+
+	plain := lipgloss.NewStyle()
+	var lines []transcriptLine
+	add := func(style lipgloss.Style, format string, args ...interface{}) {
+		lines = append(lines, transcriptLine{text: fmt.Sprintf(format, args...), style: style})
+	}
+
+	add(plain, "Plan:       %s (%s)", r.PlanName, r.Status)
+	add(plain, "Runs:       %d", r.Runs)
+	add(plain, "Wall time:  %s", report.FormatDuration(r.WallTimeSeconds))
+	add(plain, "Attempts:   %d (%.0f%% retries)", r.TotalAttempts, r.RetryRate*100)
+	add(plain, "Tokens:     %s in, %s out", report.FormatTokens(r.InputTokens), report.FormatTokens(r.OutputTokens))
+	add(plain, "Cost:       $%.2f", r.CostUSD)
+	add(plain, "")
+
+	const row = "%-6s %-11s %8s %10s %8s %8s %8s  %s"
+	add(styles.SectionStyle, row, "Task", "Status", "Attempts", "To success", "Duration", "Tokens", "Cost", "Title")
+	for _, t := range r.Tasks {
+		toSuccess := "-"
+		if t.AttemptsToSuccess > 0 {
+			toSuccess = fmt.Sprintf("%d", t.AttemptsToSuccess)
+		}
+		style := plain
+		if t.Failures > 0 {
+			style = styles.ErrorStyle
+		}
+		add(style, row, t.ID, t.Status, fmt.Sprintf("%d", t.Attempts), toSuccess,
+			report.FormatDuration(t.DurationSeconds), report.FormatTokens(t.InputTokens+t.OutputTokens),
+			fmt.Sprintf("$%.2f", t.CostUSD), t.Title)
+	}
+
+	if len(r.FailureHotSpots) > 0 {
+		add(plain, "")
+		add(styles.SectionStyle, "Failure hot spots")
+		for _, id := range r.FailureHotSpots {
+			t, _ := r.Task(id)
+			add(plain, "  %s %s: %d failed", id, t.Title, t.Failures)
+		}
+	}
+	return lines
+}
+
+// renderReport pushes the styled report into the viewport.
+func (m *ReportModel) renderReport() {
+	built := m.buildLines()
+	rendered := make([]string, len(built))
+	for i, l := range built {
+		rendered[i] = l.style.Render(truncateWithEllipsis(l.text, m.view.ContentWidth()))
+	}
+	m.view.SetLines(rendered)
+}
+
+// View implements tea.Model.
+func (m ReportModel) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	title := styles.TitleStyle.Render("Report: " + m.planTitle)
+	b.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, title))
+	b.WriteString("\n\n")
+
+	innerW, innerH := m.paneSize()
+	content := m.view.View()
+	switch {
+	case m.loadErr != "":
+		content = styles.ErrorStyle.Render(m.loadErr)
+	case m.report != nil && m.report.Runs == 0:
+		content = styles.SubtleStyle.Render("This plan has not been run yet.")
+	}
+	b.WriteString(renderPane(styles.BoxStyle.Copy().Padding(0, 1), innerW, innerH, content))
+	b.WriteString("\n")
+	if m.report != nil {
+		b.WriteString(styles.SubtleStyle.Render(fmt.Sprintf("Run `rafa report %s` for Markdown, or add --json.", m.report.PlanName)))
+	}
+	b.WriteString("\n")
+	b.WriteString(components.NewStatusBar().Render(m.width, []string{"↑↓ Scroll", "Esc Back"}))
+	return b.String()
+}
+
+// paneSize returns the inner size of the report pane.
+func (m ReportModel) paneSize() (int, int) {
+	const chromeW, chromeH = 4, 2 // border + horizontal padding
+	// Title (2 lines), hint line and status bar.
+	w := m.width - chromeW
+	h := m.height - 4 - chromeH
+	if w < 0 {
+		w = 0
+	}
+	if h < 0 {
+		h = 0
+	}
+	return w, h
+}
+
+// SetSize updates the model dimensions.
+func (m *ReportModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	w, h := m.paneSize()
+	m.view.SetSize(w, h)
+	m.view.SetAutoScroll(false)
+	m.renderReport()
+}
+
+// Report returns the computed report, or nil if it could not be built.
+func (m ReportModel) Report() *report.Report {
+	return m.report
+}
@@ -0,0 +1,59 @@
+package views
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pablasso/rafa/internal/tui/msgs"
+)
+
+const reportTestProgress = `{"timestamp":"2024-01-15T10:00:00Z","event":"plan_started","data":{"plan_id":"abc123"}}
+{"timestamp":"2024-01-15T10:00:00Z","event":"task_started","data":{"task_id":"t01","attempt":1}}
+{"timestamp":"2024-01-15T10:04:00Z","event":"task_failed","data":{"task_id":"t01","attempt":1}}
+{"timestamp":"2024-01-15T10:05:00Z","event":"task_started","data":{"task_id":"t01","attempt":2}}
+{"timestamp":"2024-01-15T10:08:00Z","event":"task_completed","data":{"task_id":"t01"}}
+{"timestamp":"2024-01-15T10:08:00Z","event":"plan_completed","data":{}}
+`
+
+func TestReportModel_ShowsAnalytics(t *testing.T) {
+	planDir := setupTranscriptPlan(t, transcriptTestOutput)
+	if err := os.WriteFile(filepath.Join(planDir, "progress.log"), []byte(reportTestProgress), 0644); err != nil {
+		t.Fatalf("failed to write progress.log: %v", err)
+	}
+
+	m := NewReportModel(planDir)
+	m.SetSize(120, 30)
+	if m.Report() == nil {
+		t.Fatal("expected a report")
+	}
+
+	text := strings.Join(m.Lines(), "\n")
+	for _, want := range []string{"Wall time:  08:00", "Attempts:   2 (50% retries)", "Tokens:     1.5k in, 300 out", "Failure hot spots", "t01 Write the parser: 1 failed"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report missing %q:\n%s", want, text)
+		}
+	}
+	view := m.View()
+	if !strings.Contains(view, "Report: abc123-parser") || !strings.Contains(view, "Write the parser") {
+		t.Errorf("unexpected view:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	if _, ok := cmd().(msgs.GoToPlanListMsg); !ok {
+		t.Errorf("expected GoToPlanListMsg, got %T", cmd())
+	}
+}
+
+func TestReportModel_NotRun(t *testing.T) {
+	m := NewReportModel(setupTranscriptPlan(t, ""))
+	m.SetSize(120, 30)
+	if !strings.Contains(m.View(), "This plan has not been run yet.") {
+		t.Errorf("unexpected view:\n%s", m.View())
+	}
+}
@@ -3,6 +3,7 @@ package views
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -11,8 +12,13 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/pablasso/rafa/internal/api"
+	"github.com/pablasso/rafa/internal/config"
 	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/notify"
 	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/prompt"
+	"github.com/pablasso/rafa/internal/telemetry"
 	"github.com/pablasso/rafa/internal/tui/components"
 	"github.com/pablasso/rafa/internal/tui/msgs"
 	"github.com/pablasso/rafa/internal/tui/styles"
@@ -32,6 +38,10 @@ const (
 type TaskDisplay struct {
 	Title  string
 	Status string // "pending", "running", "completed", "failed"
+	// Design is the design doc section the task is linked to and DesignRef
+	// where it is; both are empty when the task has no section.
+	Design    string
+	DesignRef string
 }
 
 // focusPane identifies which scrollable region has keyboard focus in the Run view.
@@ -101,9 +111,20 @@ type RunningModel struct {
 	// When true, tasksView auto-follows the current task
 	tasksAutoFollow bool
 
+	// When true, the right pane shows the current task's design section
+	// instead of the output
+	showDesign bool
+	reviewing  bool // The reviewer is judging the current attempt
+
 	// For receiving events from executor
 	outputChan chan string
-	cancel     context.CancelFunc // Set when executor starts
+	cancel     context.CancelFunc    // Set when executor starts
+	tracer     *telemetry.Tracer     // nil when tracing is disabled
+	metrics    *telemetry.RunMetrics // nil when metrics are disabled
+	api        *api.Server           // nil when the HTTP API is disabled
+	notifier   *notify.Dispatcher    // nil when no notifications are configured
+	config     *config.Config        // Project configuration; nil means defaults
+	prompts    *prompt.Set           // nil renders the built-in templates
 
 	// Plan execution context
 	planDir string
@@ -163,6 +184,26 @@ type TaskFailedMsg struct {
 	Err     error
 }
 
+// TaskSkippedMsg is sent when the running task was skipped and left pending.
+type TaskSkippedMsg struct {
+	TaskID string
+}
+
+// ReviewStartedMsg is sent when the reviewer starts on a successful attempt.
+type ReviewStartedMsg struct {
+	TaskID  string
+	Attempt int
+}
+
+// ReviewCompletedMsg is sent with the reviewer's verdict, or the error that
+// kept it from giving one.
+type ReviewCompletedMsg struct {
+	TaskID  string
+	Attempt int
+	Review  *plan.Review
+	Err     error
+}
+
 // OutputLineMsg contains a chunk of output from the executor stream.
 type OutputLineMsg struct {
 	Line string
@@ -232,6 +273,7 @@ func NewRunningModel(planID, planName string, tasks []plan.Task, planDir string,
 			Status: status,
 		}
 	}
+	linkDesignSections(taskDisplays, tasks, planDir, p)
 
 	output := components.NewOutputViewport(80, 20, 0) // Will be resized
 	output.SetShowScrollbar(true)
@@ -260,6 +302,32 @@ func NewRunningModel(planID, planName string, tasks []plan.Task, planDir string,
 	}
 }
 
+// linkDesignSections fills in the design doc section of each task linked to
+// one. The plan's source file is resolved against the repository root
+// holding planDir.
+func linkDesignSections(displays []TaskDisplay, tasks []plan.Task, planDir string, p *plan.Plan) {
+	if p == nil || planDir == "" {
+		return
+	}
+	var doc string
+	for i, t := range tasks {
+		if t.Section == nil {
+			continue
+		}
+		if doc == "" {
+			content, err := p.ReadSourceFile(filepath.Dir(filepath.Dir(filepath.Dir(planDir))))
+			if err != nil {
+				return
+			}
+			doc = content
+		}
+		if text, ok := t.Section.Excerpt(doc); ok {
+			displays[i].Design = strings.TrimSpace(text)
+			displays[i].DesignRef = p.SourceFile + t.Section.String()
+		}
+	}
+}
+
 // NewRunningModelForDemo creates a running model for demo playback.
 func NewRunningModelForDemo(planID, planName string, tasks []plan.Task, p *plan.Plan, warning string) RunningModel {
 	model := NewRunningModel(planID, planName, tasks, "", p)
@@ -291,6 +359,9 @@ func (m RunningModel) listenForOutput() tea.Cmd {
 		if !ok {
 			return nil
 		}
+		if m.api != nil {
+			m.api.Hub().Output(line)
+		}
 		return OutputLineMsg{Line: line}
 	}
 }
@@ -305,6 +376,37 @@ func (m *RunningModel) SetCancel(cancel context.CancelFunc) {
 	m.cancel = cancel
 }
 
+// SetTracer enables OpenTelemetry tracing of the run.
+func (m *RunningModel) SetTracer(t *telemetry.Tracer) {
+	m.tracer = t
+}
+
+// SetMetrics exposes the run through Prometheus metrics.
+func (m *RunningModel) SetMetrics(metrics *telemetry.RunMetrics) {
+	m.metrics = metrics
+}
+
+// SetConfig applies the project configuration in .rafa/config.json to the run.
+func (m *RunningModel) SetConfig(cfg *config.Config) {
+	m.config = cfg
+}
+
+// SetPrompts renders the task prompts from s.
+func (m *RunningModel) SetPrompts(s *prompt.Set) {
+	m.prompts = s
+}
+
+// SetNotifier sends notifications about the run's events.
+func (m *RunningModel) SetNotifier(d *notify.Dispatcher) {
+	m.notifier = d
+}
+
+// SetAPI publishes the run's events on server and lets it pause, cancel
+// and skip the run.
+func (m *RunningModel) SetAPI(server *api.Server) {
+	m.api = server
+}
+
 // StartExecutor creates a command that starts plan execution in a goroutine.
 // It creates the executor with events integration and output capture.
 func (m *RunningModel) StartExecutor(program *tea.Program) tea.Cmd {
@@ -317,7 +419,27 @@ func (m *RunningModel) StartExecutor(program *tea.Program) tea.Cmd {
 		ctx, cancel := context.WithCancel(context.Background())
 
 		// Create events handler to send messages to TUI
-		events := NewRunningModelEvents(program)
+		tracer := m.tracer
+		metrics := m.metrics
+		server := m.api
+		var hub *api.Hub
+		sinks := []executor.ExecutorEvents{NewRunningModelEvents(program)}
+		if metrics != nil {
+			sinks = append(sinks, metrics)
+		}
+		if server != nil {
+			hub = server.Hub()
+			sinks = append(sinks, hub)
+		}
+		outputChan := m.outputChan
+		notifier := m.notifier.Events(filepath.Base(m.planDir), m.plan.Name, func(err error) {
+			outputChan <- fmt.Sprintf("\n[rafa] %v\n", err)
+		})
+		if notifier != nil {
+			sinks = append(sinks, notifier)
+		}
+		events := executor.MultiEvents(sinks...)
+		control := executor.NewControl()
 
 		// Normal execution mode with file-based output capture
 		output, err := executor.NewOutputCaptureWithEventsAndHooks(
@@ -325,6 +447,9 @@ func (m *RunningModel) StartExecutor(program *tea.Program) tea.Cmd {
 			m.outputChan,
 			executor.StreamHooks{
 				OnToolUse: func(toolID, parentToolID, toolName, toolTarget string) {
+					tracer.ToolUse(toolID, parentToolID, toolName, toolTarget)
+					metrics.ToolUse(toolID, parentToolID, toolName, toolTarget)
+					hub.ToolUse(toolID, parentToolID, toolName, toolTarget)
 					program.Send(ToolUseMsg{
 						ToolID:       toolID,
 						ParentToolID: parentToolID,
@@ -333,9 +458,14 @@ func (m *RunningModel) StartExecutor(program *tea.Program) tea.Cmd {
 					})
 				},
 				OnToolResult: func(toolID string) {
+					tracer.ToolResult(toolID)
+					hub.ToolResult(toolID)
 					program.Send(ToolResultMsg{ToolID: toolID})
 				},
 				OnUsage: func(inputTokens, outputTokens int64, costUSD float64) {
+					tracer.Usage(inputTokens, outputTokens, costUSD)
+					metrics.Usage(inputTokens, outputTokens, costUSD)
+					hub.Usage(inputTokens, outputTokens, costUSD)
 					program.Send(UsageMsg{
 						InputTokens:  inputTokens,
 						OutputTokens: outputTokens,
@@ -352,12 +482,39 @@ func (m *RunningModel) StartExecutor(program *tea.Program) tea.Cmd {
 		exec := executor.New(m.planDir, m.plan).
 			WithEvents(events).
 			WithOutput(output).
+			WithTracer(tracer).
+			WithControl(control).
 			WithAllowDirty(false)
+		if m.config != nil {
+			runner := executor.NewClaudeRunner().WithPermissions(m.config.Permissions.Tasks).WithEnv(m.config.Env)
+			if m.config.Container.Image != "" {
+				runner.WithContainer(m.config.Container)
+			}
+			exec.WithRunner(runner).WithHooks(m.config.Hooks).WithProjectContext(m.config.Context).
+				WithAgent(m.config.Agent).WithPaths(m.config.Paths)
+			if m.config.Review.Enabled {
+				reviewer := executor.NewClaudeReviewer().WithEnv(m.config.Env)
+				if m.config.Container.Image != "" {
+					reviewer.WithContainer(m.config.Container)
+				}
+				exec.WithReviewer(reviewer)
+			}
+		}
+		if m.prompts != nil {
+			exec.WithPrompts(m.prompts)
+		}
+
+		if server != nil {
+			server.SetRun(&api.ActiveRun{PlanID: filepath.Base(m.planDir), Control: control, Cancel: cancel})
+		}
 
 		// Run in background goroutine
 		go func() {
 			defer output.Close()
 			defer close(m.outputChan)
+			if server != nil {
+				defer server.SetRun(nil)
+			}
 
 			// Run executor and send error as message if it fails
 			if err := exec.Run(ctx); err != nil {
@@ -461,6 +618,39 @@ func (m RunningModel) Update(msg tea.Msg) (RunningModel, tea.Cmd) {
 		m.syncTasksView()
 		return m, nil
 
+	case ReviewStartedMsg:
+		m.reviewing = true
+		m.activities = append(m.activities, RunActivityEntry{
+			Text:        fmt.Sprintf("Reviewing task %d", m.currentTask),
+			Timestamp:   m.currentTime(),
+			IsDone:      true,
+			IsSeparator: true,
+		})
+		m.trimActivities()
+		m.syncActivityView()
+		return m, nil
+
+	case ReviewCompletedMsg:
+		m.reviewing = false
+		m.activities = append(m.activities, RunActivityEntry{
+			Text:      reviewSummary(msg.Review, msg.Err),
+			Timestamp: m.currentTime(),
+			IsDone:    true,
+		})
+		m.trimActivities()
+		m.syncActivityView()
+		return m, nil
+
+	case TaskSkippedMsg:
+		for i := range m.tasks {
+			if m.tasks[i].Status == "running" {
+				m.tasks[i].Status = "pending"
+				break
+			}
+		}
+		m.syncTasksView()
+		return m, nil
+
 	case ToolUseMsg:
 		// Add tool use to activity timeline (with de-dupe by ToolID).
 		m.addOrUpdateActivity(msg)
@@ -595,6 +785,9 @@ func (m RunningModel) handleKeyPress(msg tea.KeyMsg) (RunningModel, tea.Cmd) {
 		case key == "tab":
 			m.focus = m.nextFocus()
 			return m, nil
+		case key == "d" && m.hasDesign():
+			m.showDesign = !m.showDesign
+			return m, nil
 		case isScrollKey(key):
 			return m.routeScrollKey(msg)
 		}
@@ -604,6 +797,9 @@ func (m RunningModel) handleKeyPress(msg tea.KeyMsg) (RunningModel, tea.Cmd) {
 		case key == "tab":
 			m.focus = m.nextFocus()
 			return m, nil
+		case key == "d" && m.hasDesign():
+			m.showDesign = !m.showDesign
+			return m, nil
 		case isScrollKey(key):
 			return m.routeScrollKey(msg)
 		}
@@ -1009,10 +1205,21 @@ func (m RunningModel) renderRunning() string {
 
 	// Status bar with focus indicator and scroll hints
 	focusHint := "Focus: " + focusLabel(m.focus)
-	statusItems := []string{"Running...", focusHint, "Tab Focus", "↑↓ Scroll", "Ctrl+C Cancel"}
+	runningLabel := "Running..."
+	if m.reviewing {
+		runningLabel = "Reviewing..."
+	}
+	statusItems := []string{runningLabel, focusHint, "Tab Focus", "↑↓ Scroll", "Ctrl+C Cancel"}
 	if m.state == stateCancelling {
 		statusItems = []string{"Stopping...", focusHint, "Tab Focus", "↑↓ Scroll"}
 	}
+	if m.hasDesign() {
+		label := "d Design"
+		if m.showDesign {
+			label = "d Output"
+		}
+		statusItems = append(statusItems, label)
+	}
 	if m.demoMode {
 		statusItems = append([]string{"[DEMO]"}, statusItems...)
 		if m.warning != "" {
@@ -1275,6 +1482,10 @@ func (m RunningModel) renderTaskList(width, maxLines int) []string {
 
 // renderRightPanel renders the output panel.
 func (m RunningModel) renderRightPanel(width, height int) string {
+	if m.showDesign {
+		return m.renderDesignPanel(width, height)
+	}
+
 	var lines []string
 
 	// Header
@@ -1294,6 +1505,62 @@ func (m RunningModel) renderRightPanel(width, height int) string {
 	return strings.Join(lines, "\n")
 }
 
+// reviewSummary describes a review outcome in one activity line.
+func reviewSummary(review *plan.Review, err error) string {
+	switch {
+	case err != nil:
+		return "Review failed: " + err.Error()
+	case review.Approved():
+		return "Review approved"
+	case len(review.Reasons) == 0:
+		return "Review rejected"
+	default:
+		return "Review rejected: " + strings.Join(review.Reasons, "; ")
+	}
+}
+
+// hasDesign reports whether any task is linked to a design doc section.
+func (m RunningModel) hasDesign() bool {
+	for _, t := range m.tasks {
+		if t.Design != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderDesignPanel renders the current task's design section in place of
+// the output.
+func (m RunningModel) renderDesignPanel(width, height int) string {
+	idx := max(m.currentTask-1, 0)
+	var task TaskDisplay
+	if idx < len(m.tasks) {
+		task = m.tasks[idx]
+	}
+
+	lines := sectionHeaderLines("Design")
+	if task.Design == "" {
+		lines = append(lines, styles.SubtleStyle.Render("No design section is linked to this task."))
+		return strings.Join(lines, "\n")
+	}
+	for _, line := range wrapTextToLines(task.DesignRef, width) {
+		lines = append(lines, styles.SubtleStyle.Render(line))
+	}
+	lines = append(lines, "")
+
+	var body []string
+	for _, line := range strings.Split(task.Design, "\n") {
+		body = append(body, wrapTextToLines(line, width)...)
+	}
+	if room := height - len(lines); len(body) > room {
+		room = max(room-1, 0)
+		hidden := len(body) - room
+		body = append(body[:room], styles.SubtleStyle.Render(fmt.Sprintf("… %d more lines", hidden)))
+	}
+	lines = append(lines, body...)
+	return strings.Join(lines, "\n")
+}
+
 // getTaskIndicator returns the status indicator for a task.
 func (m RunningModel) getTaskIndicator(status string, isCurrent bool) string {
 	switch status {
@@ -2019,6 +2286,31 @@ func (e *RunningModelEvents) OnTaskFailed(task *plan.Task, attempt int, err erro
 	})
 }
 
+// OnTaskSkipped implements executor.SkipEvents.
+func (e *RunningModelEvents) OnTaskSkipped(task *plan.Task) {
+	e.program.Send(TaskSkippedMsg{
+		TaskID: task.ID,
+	})
+}
+
+// OnReviewStart implements executor.ReviewEvents.
+func (e *RunningModelEvents) OnReviewStart(task *plan.Task, attempt int) {
+	e.program.Send(ReviewStartedMsg{
+		TaskID:  task.ID,
+		Attempt: attempt,
+	})
+}
+
+// OnReviewComplete implements executor.ReviewEvents.
+func (e *RunningModelEvents) OnReviewComplete(task *plan.Task, attempt int, review *plan.Review, err error) {
+	e.program.Send(ReviewCompletedMsg{
+		TaskID:  task.ID,
+		Attempt: attempt,
+		Review:  review,
+		Err:     err,
+	})
+}
+
 // OnOutput implements ExecutorEvents.
 func (e *RunningModelEvents) OnOutput(line string) {
 	// Output is handled via OutputCaptureWithEvents channel
@@ -2044,4 +2336,7 @@ func (e *RunningModelEvents) OnPlanFailed(task *plan.Task, reason string) {
 }
 
 // Verify interface compliance
-var _ executor.ExecutorEvents = (*RunningModelEvents)(nil)
+var (
+	_ executor.ExecutorEvents = (*RunningModelEvents)(nil)
+	_ executor.SkipEvents     = (*RunningModelEvents)(nil)
+)
@@ -3529,3 +3529,85 @@ func TestComputeLayout_ExtremelySmall(t *testing.T) {
 		t.Errorf("tasksContentH=%d, want >= 1", d.tasksContentH)
 	}
 }
+
+func TestRunningModel_DesignToggle(t *testing.T) {
+	planDir, p := setupDesignPlan(t)
+	m := NewRunningModel(p.ID, p.Name, p.Tasks, planDir, p)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	view := m.View()
+	if !strings.Contains(view, "d Design") {
+		t.Errorf("expected the design hint in the status bar:\n%s", view)
+	}
+	if strings.Contains(view, "recursive descent") {
+		t.Errorf("design section should be hidden until toggled:\n%s", view)
+	}
+
+	m, _ = m.Update(keyRunes("d"))
+	view = m.View()
+	for _, want := range []string{"Use a recursive descent parser.", "d Output"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q after toggling:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(keyRunes("d"))
+	if strings.Contains(m.View(), "recursive descent") {
+		t.Error("expected d to toggle back to the output")
+	}
+}
+
+func TestRunningModel_DesignToggleWithoutSections(t *testing.T) {
+	tasks := []plan.Task{{ID: "t01", Title: "Task", Status: plan.TaskStatusPending}}
+	m := NewRunningModel("abc123", "my-plan", tasks, "/tmp/test-plan", nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.Update(keyRunes("d"))
+	if m.showDesign {
+		t.Error("expected d to do nothing when no task has a design section")
+	}
+	if strings.Contains(m.View(), "d Design") {
+		t.Error("expected no design hint without linked sections")
+	}
+}
+
+func TestRunningModel_ReviewMessages(t *testing.T) {
+	tasks := []plan.Task{{ID: "t01", Title: "Task", Status: plan.TaskStatusPending}}
+	m := NewRunningModel("abc123", "my-plan", tasks, "/tmp/test-plan", nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.Update(TaskStartedMsg{TaskNum: 1, Total: 1, TaskID: "t01", Title: "Task", Attempt: 1})
+
+	m, _ = m.Update(ReviewStartedMsg{TaskID: "t01", Attempt: 1})
+	view := m.View()
+	for _, want := range []string{"Reviewing...", "Reviewing task 1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q while reviewing:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(ReviewCompletedMsg{TaskID: "t01", Attempt: 1, Review: &plan.Review{Verdict: plan.ReviewReject, Reasons: []string{"No tests"}}})
+	view = m.View()
+	if strings.Contains(view, "Reviewing...") {
+		t.Error("expected the status bar to return to running after the review")
+	}
+	if !strings.Contains(view, "Review rejected: No tests") {
+		t.Errorf("expected the verdict in the activity:\n%s", view)
+	}
+}
+
+func TestReviewSummary(t *testing.T) {
+	tests := []struct {
+		review *plan.Review
+		err    error
+		want   string
+	}{
+		{&plan.Review{Verdict: plan.ReviewApprove}, nil, "Review approved"},
+		{&plan.Review{Verdict: plan.ReviewReject}, nil, "Review rejected"},
+		{&plan.Review{Verdict: plan.ReviewReject, Reasons: []string{"a", "b"}}, nil, "Review rejected: a; b"},
+		{nil, errors.New("claude exited"), "Review failed: claude exited"},
+	}
+	for _, tt := range tests {
+		if got := reviewSummary(tt.review, tt.err); got != tt.want {
+			t.Errorf("reviewSummary() = %q, want %q", got, tt.want)
+		}
+	}
+}
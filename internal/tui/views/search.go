@@ -0,0 +1,239 @@
+package views
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pablasso/rafa/internal/search"
+	"github.com/pablasso/rafa/internal/tui/components"
+	"github.com/pablasso/rafa/internal/tui/msgs"
+	"github.com/pablasso/rafa/internal/tui/styles"
+)
+
+// searchMaxHits caps the number of results shown in the search overlay.
+const searchMaxHits = 200
+
+// SearchModel searches every plan's metadata, transcripts and progress log.
+// Results update as the query is typed; Enter opens the selected hit in the
+// transcript viewer.
+type SearchModel struct {
+	rafaDir string
+	index   *search.Index
+	loadErr string
+
+	query  string
+	hits   []search.Hit
+	cursor int
+
+	width  int
+	height int
+}
+
+// NewSearchModel indexes the plans in rafaDir/plans.
+func NewSearchModel(rafaDir string) SearchModel {
+	m := SearchModel{rafaDir: rafaDir, index: &search.Index{}}
+
+	plansPath := filepath.Join(rafaDir, "plans")
+	entries, err := os.ReadDir(plansPath)
+	if err != nil && !os.IsNotExist(err) {
+		m.loadErr = fmt.Sprintf("Failed to read plans: %v", err)
+		return m
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(plansPath, entry.Name()))
+		}
+	}
+	index, err := search.Build(dirs)
+	if err != nil {
+		m.loadErr = fmt.Sprintf("Failed to index plans: %v", err)
+		return m
+	}
+	m.index = index
+	return m
+}
+
+// Init implements tea.Model.
+func (m SearchModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Width, msg.Height)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			return m, tea.Quit
+		case tea.KeyEsc:
+			if m.query != "" {
+				m.SetQuery("")
+				return m, nil
+			}
+			return m, func() tea.Msg { return msgs.GoToPlanListMsg{} }
+		case tea.KeyUp, tea.KeyCtrlP:
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case tea.KeyDown, tea.KeyCtrlN:
+			if m.cursor < len(m.hits)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case tea.KeyEnter:
+			if m.cursor >= len(m.hits) {
+				return m, nil
+			}
+			hit := m.hits[m.cursor]
+			query := m.query
+			return m, func() tea.Msg {
+				return msgs.GoToTranscriptMsg{
+					PlanID:     hit.PlanID,
+					Position:   hit.Position,
+					Query:      query,
+					FromSearch: true,
+				}
+			}
+		case tea.KeyBackspace:
+			if r := []rune(m.query); len(r) > 0 {
+				m.SetQuery(string(r[:len(r)-1]))
+			}
+			return m, nil
+		case tea.KeySpace:
+			m.SetQuery(m.query + " ")
+			return m, nil
+		case tea.KeyRunes:
+			m.SetQuery(m.query + string(msg.Runes))
+			return m, nil
+		}
+	}
+	return m, nil
+}
+
+// SetQuery replaces the query and refreshes the results.
+func (m *SearchModel) SetQuery(query string) {
+	m.query = query
+	m.hits = m.index.Search(search.Query{Text: query, Limit: searchMaxHits})
+	m.cursor = 0
+}
+
+// View implements tea.Model.
+func (m SearchModel) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	title := styles.TitleStyle.Render("Search Plans")
+	b.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, title))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("/%s█", m.query))
+	b.WriteString("\n")
+
+	// Title (2 lines), query, result summary and status bar.
+	listHeight := m.height - 5
+	if listHeight < 0 {
+		listHeight = 0
+	}
+
+	var summary string
+	switch {
+	case m.loadErr != "":
+		summary = styles.ErrorStyle.Render(m.loadErr)
+	case strings.TrimSpace(m.query) == "":
+		summary = styles.SubtleStyle.Render(fmt.Sprintf("Type to search tasks, tool calls, agent text and progress events (%d indexed)", m.index.Len()))
+	case len(m.hits) == 0:
+		summary = styles.ErrorStyle.Render(fmt.Sprintf("No matches for %q", m.query))
+	case len(m.hits) == searchMaxHits:
+		summary = styles.SubtleStyle.Render(fmt.Sprintf("First %d matches", len(m.hits)))
+	default:
+		summary = styles.SubtleStyle.Render(fmt.Sprintf("%d matches", len(m.hits)))
+	}
+	b.WriteString(summary)
+	b.WriteString("\n")
+
+	lines := m.renderHits(listHeight)
+	b.WriteString(lines)
+	used := strings.Count(lines, "\n")
+	if lines != "" {
+		used++
+	}
+	if listHeight > used {
+		b.WriteString(strings.Repeat("\n", listHeight-used))
+	}
+
+	statusItems := []string{"↑↓ Select", "Enter Open transcript", "Esc Back"}
+	if m.query != "" {
+		statusItems = []string{"↑↓ Select", "Enter Open transcript", "Esc Clear"}
+	}
+	b.WriteString(components.NewStatusBar().Render(m.width, statusItems))
+	return b.String()
+}
+
+// renderHits renders one line per hit, keeping the cursor visible.
+func (m SearchModel) renderHits(height int) string {
+	if len(m.hits) == 0 || height <= 0 {
+		return ""
+	}
+
+	start := 0
+	if m.cursor >= height {
+		start = m.cursor - height + 1
+	}
+	end := start + height
+	if end > len(m.hits) {
+		end = len(m.hits)
+	}
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		h := m.hits[i]
+		location := h.TaskID
+		if h.Attempt > 0 {
+			location = fmt.Sprintf("%s #%d", h.TaskID, h.Attempt)
+		}
+		label := h.PlanID
+		if location != "" {
+			label += " " + location
+		}
+		label += fmt.Sprintf(" [%s] %s", h.Kind, h.Snippet)
+		label = truncateWithEllipsis(label, m.width-2)
+		if i == m.cursor {
+			lines = append(lines, styles.SelectedStyle.Render("> "+label))
+		} else {
+			lines = append(lines, "  "+label)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetSize updates the model dimensions.
+func (m *SearchModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Query returns the current query.
+func (m SearchModel) Query() string {
+	return m.query
+}
+
+// Hits returns the results of the current query.
+func (m SearchModel) Hits() []search.Hit {
+	return m.hits
+}
+
+// Cursor returns the index of the selected hit.
+func (m SearchModel) Cursor() int {
+	return m.cursor
+}
@@ -0,0 +1,70 @@
+package views
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pablasso/rafa/internal/search"
+	"github.com/pablasso/rafa/internal/tui/msgs"
+)
+
+func TestSearchModel_TypingFiltersHits(t *testing.T) {
+	planDir := setupTranscriptPlan(t, transcriptTestOutput)
+	m := NewSearchModel(filepath.Dir(filepath.Dir(planDir)))
+	m.SetSize(120, 30)
+
+	for _, r := range "parser" {
+		m, _ = m.Update(keyRunes(string(r)))
+	}
+	if m.Query() != "parser" {
+		t.Fatalf("Query = %q", m.Query())
+	}
+	if len(m.Hits()) == 0 {
+		t.Fatal("expected hits for parser")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	for _, r := range "tests" {
+		m, _ = m.Update(keyRunes(string(r)))
+	}
+	hits := m.Hits()
+	if len(hits) != 1 || hits[0].Kind != search.KindText || hits[0].Position != 2 {
+		t.Fatalf("expected the attempt 2 text hit, got %+v", hits)
+	}
+	if view := m.View(); !strings.Contains(view, "abc123-parser t01 #2 [text]") {
+		t.Errorf("view missing hit:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg, ok := cmd().(msgs.GoToTranscriptMsg)
+	if !ok {
+		t.Fatalf("expected GoToTranscriptMsg, got %T", cmd())
+	}
+	if msg.PlanID != "abc123-parser" || msg.Position != 2 || msg.Query != "parser tests" || !msg.FromSearch {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
+
+func TestSearchModel_Esc(t *testing.T) {
+	m := NewSearchModel(filepath.Join(t.TempDir(), ".rafa"))
+	m.SetSize(120, 30)
+	m.SetQuery("anything")
+
+	// The first Esc clears the query, the second leaves.
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil || m.Query() != "" {
+		t.Fatalf("expected query to be cleared, got %q", m.Query())
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	if _, ok := cmd().(msgs.GoToPlanListMsg); !ok {
+		t.Errorf("expected GoToPlanListMsg, got %T", cmd())
+	}
+}
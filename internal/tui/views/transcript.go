@@ -0,0 +1,627 @@
+package views
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pablasso/rafa/internal/executor"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/tui/components"
+	"github.com/pablasso/rafa/internal/tui/msgs"
+	"github.com/pablasso/rafa/internal/tui/styles"
+)
+
+const (
+	// transcriptMaxLines caps the rendered transcript of a single attempt.
+	transcriptMaxLines = 100000
+	// transcriptResultPreviewLines is how many lines of each tool result are shown.
+	transcriptResultPreviewLines = 6
+	// transcriptListMaxWidth is the widest the attempt list pane grows.
+	transcriptListMaxWidth = 40
+)
+
+// transcriptFocus identifies the pane that receives navigation keys.
+type transcriptFocus int
+
+const (
+	transcriptFocusList transcriptFocus = iota
+	transcriptFocusView
+)
+
+// transcriptLine is a rendered transcript line. Text is kept unstyled so it
+// can be searched and highlighted.
+type transcriptLine struct {
+	text  string
+	style lipgloss.Style
+}
+
+// TranscriptModel shows the recorded agent sessions of a plan: a list of task
+// attempts on the left and the selected attempt's transcript on the right.
+type TranscriptModel struct {
+	planDir    string
+	planTitle  string
+	taskTitles map[string]string
+	designs    map[string]TaskDisplay // Tasks linked to a design doc section, by ID
+	attempts   []executor.TranscriptAttempt
+	loadErr    string
+
+	cursor int
+	focus  transcriptFocus
+	view   components.ScrollViewport
+	lines  []transcriptLine
+
+	fromSearch bool // Esc returns to the search overlay
+
+	searching  bool   // true while the search query is being typed
+	query      string // committed (or in-progress) search query
+	matches    []int  // indices of lines matching query
+	matchIndex int    // current position in matches
+
+	width  int
+	height int
+}
+
+// NewTranscriptModel reads the plan in planDir and parses its captured output.
+// The transcript is read-only, so plan.json is never migrated on disk.
+func NewTranscriptModel(planDir string) TranscriptModel {
+	m := TranscriptModel{
+		planDir:    planDir,
+		planTitle:  filepath.Base(planDir),
+		taskTitles: make(map[string]string),
+		view:       components.NewScrollViewport(0, 0, transcriptMaxLines),
+	}
+
+	if p, err := plan.ReadPlan(planDir); err == nil {
+		for _, task := range p.Tasks {
+			m.taskTitles[task.ID] = task.Title
+		}
+		displays := make([]TaskDisplay, len(p.Tasks))
+		linkDesignSections(displays, p.Tasks, planDir, p)
+		m.designs = make(map[string]TaskDisplay)
+		for i, d := range displays {
+			if d.Design != "" {
+				m.designs[p.Tasks[i].ID] = d
+			}
+		}
+	}
+
+	attempts, err := executor.LoadTranscript(planDir)
+	if err != nil {
+		m.loadErr = fmt.Sprintf("Failed to read output: %v", err)
+	}
+	m.attempts = attempts
+	// Start on the most recent attempt.
+	if len(m.attempts) > 0 {
+		m.cursor = len(m.attempts) - 1
+	}
+	m.renderAttempt()
+	return m
+}
+
+// Init implements tea.Model.
+func (m TranscriptModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m TranscriptModel) Update(msg tea.Msg) (TranscriptModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Width, msg.Height)
+		return m, nil
+
+	case tea.MouseMsg:
+		if msg.Button != tea.MouseButtonWheelUp && msg.Button != tea.MouseButtonWheelDown {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.view, cmd = m.view.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		if m.searching {
+			return m.handleSearchKey(msg)
+		}
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m TranscriptModel) handleKey(msg tea.KeyMsg) (TranscriptModel, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		if m.query != "" {
+			m.clearSearch()
+			return m, nil
+		}
+		if m.fromSearch {
+			return m, func() tea.Msg { return msgs.GoToSearchMsg{Resume: true} }
+		}
+		return m, func() tea.Msg { return msgs.GoToPlanListMsg{} }
+	case "tab":
+		if m.focus == transcriptFocusList {
+			m.focus = transcriptFocusView
+		} else {
+			m.focus = transcriptFocusList
+		}
+		return m, nil
+	case "/":
+		m.searching = true
+		m.query = ""
+		m.matches = nil
+		m.renderView()
+		return m, nil
+	case "n":
+		m.jumpToMatch(1)
+		return m, nil
+	case "N":
+		m.jumpToMatch(-1)
+		return m, nil
+	}
+
+	if m.focus == transcriptFocusList {
+		switch key {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+				m.renderAttempt()
+			}
+			return m, nil
+		case "down", "j":
+			if m.cursor < len(m.attempts)-1 {
+				m.cursor++
+				m.renderAttempt()
+			}
+			return m, nil
+		case "enter", "right", "l":
+			m.focus = transcriptFocusView
+			return m, nil
+		}
+		return m, nil
+	}
+
+	switch key {
+	case "left", "h":
+		m.focus = transcriptFocusList
+		return m, nil
+	}
+	if isScrollKey(key) {
+		var cmd tea.Cmd
+		m.view, cmd = m.view.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// handleSearchKey edits the search query. Enter jumps to the first match,
+// Esc abandons the search.
+func (m TranscriptModel) handleSearchKey(msg tea.KeyMsg) (TranscriptModel, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.clearSearch()
+		return m, nil
+	case tea.KeyEnter:
+		m.searching = false
+		m.updateMatches()
+		m.matchIndex = -1
+		m.jumpToMatch(1)
+		return m, nil
+	case tea.KeyBackspace:
+		if r := []rune(m.query); len(r) > 0 {
+			m.query = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.query += " "
+	case tea.KeyRunes:
+		m.query += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	m.updateMatches()
+	m.renderView()
+	return m, nil
+}
+
+func (m *TranscriptModel) clearSearch() {
+	m.searching = false
+	m.query = ""
+	m.matches = nil
+	m.matchIndex = 0
+	m.renderView()
+}
+
+// updateMatches finds the transcript lines containing the query
+// (case-insensitive).
+func (m *TranscriptModel) updateMatches() {
+	m.matches = nil
+	if m.query == "" {
+		return
+	}
+	needle := strings.ToLower(m.query)
+	for i, line := range m.lines {
+		if strings.Contains(strings.ToLower(line.text), needle) {
+			m.matches = append(m.matches, i)
+		}
+	}
+}
+
+// jumpToMatch moves to the next (delta 1) or previous (delta -1) match,
+// wrapping around, and scrolls it into view.
+func (m *TranscriptModel) jumpToMatch(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.matchIndex = (m.matchIndex + delta + len(m.matches)) % len(m.matches)
+	m.focus = transcriptFocusView
+	m.renderView()
+	m.view.EnsureVisible(m.matches[m.matchIndex], true)
+}
+
+// renderAttempt rebuilds the transcript lines for the selected attempt and
+// scrolls to its top.
+func (m *TranscriptModel) renderAttempt() {
+	m.lines = m.buildLines(m.view.ContentWidth())
+	m.updateMatches()
+	m.matchIndex = 0
+	m.view.SetAutoScroll(false)
+	m.renderView()
+	m.view.EnsureVisible(0, false)
+}
+
+// renderView pushes the styled lines into the viewport, highlighting search
+// matches.
+func (m *TranscriptModel) renderView() {
+	rendered := make([]string, len(m.lines))
+	current := -1
+	if len(m.matches) > 0 && m.matchIndex >= 0 && m.matchIndex < len(m.matches) {
+		current = m.matches[m.matchIndex]
+	}
+	for i, line := range m.lines {
+		rendered[i] = renderTranscriptLine(line, m.query, i == current)
+	}
+	m.view.SetLines(rendered)
+}
+
+// renderTranscriptLine styles a line and highlights occurrences of query.
+func renderTranscriptLine(line transcriptLine, query string, current bool) string {
+	if query == "" {
+		return line.style.Render(line.text)
+	}
+	lower := strings.ToLower(line.text)
+	needle := strings.ToLower(query)
+	// Byte offsets in lower only map onto text when lowering kept lengths.
+	if len(lower) != len(line.text) || !strings.Contains(lower, needle) {
+		return line.style.Render(line.text)
+	}
+
+	highlight := lipgloss.NewStyle().Reverse(true)
+	if current {
+		highlight = highlight.Bold(true)
+	}
+
+	var b strings.Builder
+	start := 0
+	for {
+		idx := strings.Index(lower[start:], needle)
+		if idx < 0 {
+			break
+		}
+		idx += start
+		end := idx + len(needle)
+		if idx > start {
+			b.WriteString(line.style.Render(line.text[start:idx]))
+		}
+		b.WriteString(highlight.Render(line.text[idx:end]))
+		start = end
+	}
+	if start < len(line.text) {
+		b.WriteString(line.style.Render(line.text[start:]))
+	}
+	return b.String()
+}
+
+// buildLines renders the selected attempt as wrapped transcript lines.
+func (m TranscriptModel) buildLines(width int) []transcriptLine {
+	if m.cursor < 0 || m.cursor >= len(m.attempts) {
+		return nil
+	}
+	a := m.attempts[m.cursor]
+
+	plain := lipgloss.NewStyle()
+	var lines []transcriptLine
+	add := func(style lipgloss.Style, texts ...string) {
+		for _, t := range texts {
+			lines = append(lines, transcriptLine{text: t, style: style})
+		}
+	}
+
+	header := fmt.Sprintf("Task %s, Attempt %d: %s", a.TaskID, a.Attempt, transcriptOutcomeLabel(a.Outcome))
+	add(styles.SelectedStyle, wrapTextToLines(header, width)...)
+	if title := m.taskTitles[a.TaskID]; title != "" {
+		add(plain, wrapTextToLines(title, width)...)
+	}
+	if !a.StartedAt.IsZero() {
+		add(styles.SubtleStyle, "Started "+a.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if d, ok := m.designs[a.TaskID]; ok {
+		add(styles.SubtleStyle, wrapPrefixedText("Design: ", d.DesignRef, width)...)
+		add(styles.SubtleStyle, transcriptResultPreview(d.Design, width)...)
+	}
+	add(plain, "")
+
+	if len(a.Entries) == 0 {
+		add(styles.SubtleStyle, "No output recorded for this attempt.")
+		return lines
+	}
+
+	for _, e := range a.Entries {
+		switch e.Kind {
+		case executor.TranscriptText:
+			for _, para := range strings.Split(e.Text, "\n") {
+				add(plain, wrapTextToLines(para, width)...)
+			}
+			add(plain, "")
+		case executor.TranscriptToolUse:
+			add(styles.SelectedStyle, wrapPrefixedText("→ ", formatToolUseEntry(e.ToolName, e.ToolTarget), width)...)
+		case executor.TranscriptToolResult:
+			label := "✓ "
+			style := styles.SuccessStyle
+			if e.IsError {
+				label = "✗ "
+				style = styles.ErrorStyle
+			}
+			name := e.ToolName
+			if name == "" {
+				name = "Tool"
+			}
+			add(style, wrapPrefixedText("  "+label, name+" result", width)...)
+			add(styles.SubtleStyle, transcriptResultPreview(e.Text, width)...)
+			add(plain, "")
+		case executor.TranscriptUsage:
+			usage := fmt.Sprintf("Usage: %s in, %s out", formatTokens(e.InputTokens), formatTokens(e.OutputTokens))
+			if e.CostUSD > 0 {
+				usage += fmt.Sprintf(", $%.2f", e.CostUSD)
+			}
+			add(styles.SubtleStyle, usage)
+			add(plain, "")
+		}
+	}
+	return lines
+}
+
+// transcriptResultPreview returns the first lines of a tool result, indented
+// under the result label.
+func transcriptResultPreview(text string, width int) []string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	const indent = "    "
+	source := strings.Split(text, "\n")
+	var out []string
+	for i, line := range source {
+		if i == transcriptResultPreviewLines {
+			out = append(out, fmt.Sprintf("%s… %d more lines", indent, len(source)-i))
+			break
+		}
+		out = append(out, wrapPrefixedText(indent, line, width)...)
+	}
+	return out
+}
+
+func transcriptOutcomeLabel(outcome string) string {
+	switch outcome {
+	case executor.TranscriptSucceeded:
+		return "succeeded"
+	case executor.TranscriptFailed:
+		return "failed"
+	default:
+		return "incomplete"
+	}
+}
+
+func transcriptOutcomeIndicator(outcome string) string {
+	switch outcome {
+	case executor.TranscriptSucceeded:
+		return styles.SuccessStyle.Render("✓")
+	case executor.TranscriptFailed:
+		return styles.ErrorStyle.Render("✗")
+	default:
+		return styles.SubtleStyle.Render("○")
+	}
+}
+
+// transcriptLayout returns the inner sizes of the list and transcript panes.
+func (m TranscriptModel) transcriptLayout() (listW, viewW, paneH int) {
+	const chromeW, chromeH = 4, 2 // border + horizontal padding
+	// Title (2 lines), search line and status bar.
+	paneH = m.height - 4 - chromeH
+	listW = m.width / 3
+	if listW > transcriptListMaxWidth {
+		listW = transcriptListMaxWidth
+	}
+	viewW = m.width - listW - 2*chromeW
+	if listW < 0 {
+		listW = 0
+	}
+	if viewW < 0 {
+		viewW = 0
+	}
+	if paneH < 0 {
+		paneH = 0
+	}
+	return listW, viewW, paneH
+}
+
+// View implements tea.Model.
+func (m TranscriptModel) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	title := styles.TitleStyle.Render("Transcript: " + m.planTitle)
+	b.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, title))
+	b.WriteString("\n\n")
+
+	listW, viewW, paneH := m.transcriptLayout()
+	listStyle := styles.BoxStyle.Copy().Padding(0, 1)
+	viewStyle := styles.BoxStyle.Copy().Padding(0, 1)
+	if m.focus == transcriptFocusList {
+		listStyle = styles.FocusedBoxStyle.Copy().Padding(0, 1)
+	} else {
+		viewStyle = styles.FocusedBoxStyle.Copy().Padding(0, 1)
+	}
+
+	var viewContent string
+	switch {
+	case m.loadErr != "":
+		viewContent = styles.ErrorStyle.Render(m.loadErr)
+	case len(m.attempts) == 0:
+		viewContent = styles.SubtleStyle.Render("No task output recorded for this plan yet.")
+	default:
+		viewContent = m.view.View()
+	}
+
+	listPane := renderPane(listStyle, listW, paneH, m.renderAttemptList(listW, paneH))
+	viewPane := renderPane(viewStyle, viewW, paneH, viewContent)
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, listPane, viewPane))
+	b.WriteString("\n")
+	b.WriteString(m.renderSearchLine())
+	b.WriteString("\n")
+
+	statusItems := []string{"↑↓ Select", "Tab Switch pane", "/ Search", "Esc Back"}
+	switch {
+	case m.searching:
+		statusItems = []string{"Enter Search", "Esc Cancel"}
+	case m.query != "":
+		statusItems = []string{"n Next", "N Previous", "/ New search", "Esc Clear search"}
+	case m.focus == transcriptFocusView:
+		statusItems = []string{"↑↓ Scroll", "Tab Switch pane", "/ Search", "Esc Back"}
+	}
+	b.WriteString(components.NewStatusBar().Render(m.width, statusItems))
+
+	return b.String()
+}
+
+// renderAttemptList renders one line per attempt, keeping the cursor visible.
+func (m TranscriptModel) renderAttemptList(width, height int) string {
+	if len(m.attempts) == 0 || height <= 0 {
+		return ""
+	}
+
+	start := 0
+	if m.cursor >= height {
+		start = m.cursor - height + 1
+	}
+	end := start + height
+	if end > len(m.attempts) {
+		end = len(m.attempts)
+	}
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		a := m.attempts[i]
+		label := fmt.Sprintf("%s #%d", a.TaskID, a.Attempt)
+		if title := m.taskTitles[a.TaskID]; title != "" {
+			label += " " + title
+		}
+		label = truncateWithEllipsis(label, width-2)
+		if i == m.cursor {
+			label = styles.SelectedStyle.Render(label)
+		}
+		lines = append(lines, transcriptOutcomeIndicator(a.Outcome)+" "+label)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSearchLine shows the search prompt or the current match position.
+func (m TranscriptModel) renderSearchLine() string {
+	switch {
+	case m.searching:
+		return fmt.Sprintf("/%s█", m.query)
+	case m.query == "":
+		return ""
+	case len(m.matches) == 0:
+		return styles.ErrorStyle.Render(fmt.Sprintf("No matches for %q", m.query))
+	default:
+		return styles.SubtleStyle.Render(fmt.Sprintf("Match %d/%d for %q", m.matchIndex+1, len(m.matches), m.query))
+	}
+}
+
+// SetSize updates the model dimensions and rewraps the transcript.
+func (m *TranscriptModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	_, viewW, paneH := m.transcriptLayout()
+	// The transcript pane reserves one column for the scrollbar.
+	m.view.SetSize(viewW, paneH)
+	m.lines = m.buildLines(m.view.ContentWidth())
+	m.updateMatches()
+	if m.matchIndex >= len(m.matches) {
+		m.matchIndex = 0
+	}
+	m.renderView()
+}
+
+// OpenSearchHit selects the attempt at position (1-based; 0 keeps the current
+// selection), highlights query in it and scrolls to the first match. Esc then
+// returns to the search overlay. Multi-word queries that do not occur
+// verbatim fall back to their longest word.
+func (m *TranscriptModel) OpenSearchHit(position int, query string) {
+	m.fromSearch = true
+	if position > 0 && position <= len(m.attempts) {
+		m.cursor = position - 1
+		m.renderAttempt()
+	}
+
+	m.query = strings.TrimSpace(query)
+	m.updateMatches()
+	if len(m.matches) == 0 && len(strings.Fields(m.query)) > 1 {
+		longest := ""
+		for _, word := range strings.Fields(query) {
+			if len(word) > len(longest) {
+				longest = word
+			}
+		}
+		m.query = longest
+		m.updateMatches()
+	}
+	m.matchIndex = -1
+	m.jumpToMatch(1)
+	if len(m.matches) == 0 {
+		m.renderView()
+	}
+}
+
+// Attempts returns the parsed task attempts.
+func (m TranscriptModel) Attempts() []executor.TranscriptAttempt {
+	return m.attempts
+}
+
+// Cursor returns the index of the selected attempt.
+func (m TranscriptModel) Cursor() int {
+	return m.cursor
+}
+
+// Lines returns the unstyled transcript lines of the selected attempt.
+func (m TranscriptModel) Lines() []string {
+	lines := make([]string, len(m.lines))
+	for i, l := range m.lines {
+		lines[i] = l.text
+	}
+	return lines
+}
+
+// Matches returns the indices of lines matching the current search.
+func (m TranscriptModel) Matches() []int {
+	return m.matches
+}
@@ -0,0 +1,259 @@
+package views
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pablasso/rafa/internal/plan"
+	"github.com/pablasso/rafa/internal/tui/msgs"
+)
+
+const transcriptTestOutput = `
+=== Task t01, Attempt 1 ===
+Started: 2024-01-15T10:00:00Z
+
+{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"First try at the parser."}}}
+{"type":"stream_event","event":{"type":"content_block_stop"}}
+{"type":"stream_event","event":{"type":"content_block_start","content_block":{"id":"tool_1","type":"tool_use","name":"Bash","input":{"command":"go test ./..."}}}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"tool_1","is_error":true,"content":"FAIL parser_test.go"}]}}
+
+=== Task t01: FAILED ===
+
+
+=== Task t01, Attempt 2 ===
+Started: 2024-01-15T10:05:00Z
+
+{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"Fixed the parser. Tests pass."}}}
+{"type":"stream_event","event":{"type":"content_block_stop"}}
+{"type":"result","subtype":"success","usage":{"input_tokens":1500,"output_tokens":300},"total_cost_usd":0.25}
+
+=== Task t01: SUCCESS ===
+
+`
+
+func setupTranscriptPlan(t *testing.T, output string) string {
+	t.Helper()
+	plansDir := filepath.Join(t.TempDir(), ".rafa", "plans")
+	createTestPlan(t, plansDir, "abc123", "parser", plan.PlanStatusCompleted, []plan.Task{
+		{ID: "t01", Title: "Write the parser", Status: plan.TaskStatusCompleted},
+	})
+	planDir := filepath.Join(plansDir, "abc123-parser")
+	if output != "" {
+		if err := os.WriteFile(filepath.Join(planDir, plan.OutputLogFileName), []byte(output), 0644); err != nil {
+			t.Fatalf("failed to write output.log: %v", err)
+		}
+	}
+	return planDir
+}
+
+func keyRunes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestTranscriptModel_ShowsLatestAttempt(t *testing.T) {
+	m := NewTranscriptModel(setupTranscriptPlan(t, transcriptTestOutput))
+	m.SetSize(120, 40)
+
+	if len(m.Attempts()) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(m.Attempts()))
+	}
+	if m.Cursor() != 1 {
+		t.Errorf("expected cursor on the latest attempt, got %d", m.Cursor())
+	}
+
+	text := strings.Join(m.Lines(), "\n")
+	for _, want := range []string{"Task t01, Attempt 2: succeeded", "Write the parser", "Fixed the parser. Tests pass.", "Usage: 1.5k in, 300 out, $0.25"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("transcript missing %q:\n%s", want, text)
+		}
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "Transcript: abc123-parser") {
+		t.Errorf("view missing title:\n%s", view)
+	}
+	if !strings.Contains(view, "t01 #1") || !strings.Contains(view, "t01 #2") {
+		t.Errorf("view missing attempt list:\n%s", view)
+	}
+}
+
+func TestTranscriptModel_SelectAttempt(t *testing.T) {
+	m := NewTranscriptModel(setupTranscriptPlan(t, transcriptTestOutput))
+	m.SetSize(120, 40)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if m.Cursor() != 0 {
+		t.Fatalf("expected cursor 0, got %d", m.Cursor())
+	}
+
+	text := strings.Join(m.Lines(), "\n")
+	for _, want := range []string{"Attempt 1: failed", "First try at the parser.", "→ Bash: go test ./...", "✗ Bash result", "FAIL parser_test.go"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("transcript missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestTranscriptModel_Search(t *testing.T) {
+	m := NewTranscriptModel(setupTranscriptPlan(t, transcriptTestOutput))
+	m.SetSize(120, 40)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+
+	m, _ = m.Update(keyRunes("/"))
+	m, _ = m.Update(keyRunes("PARSER"))
+	if !strings.Contains(m.View(), "/PARSER") {
+		t.Errorf("expected search prompt in view:\n%s", m.View())
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Task title, assistant text and tool result output.
+	if len(m.Matches()) != 3 {
+		t.Fatalf("expected 3 case-insensitive matches, got %v", m.Matches())
+	}
+	if !strings.Contains(m.View(), `Match 1/3 for "PARSER"`) {
+		t.Errorf("expected match position in view:\n%s", m.View())
+	}
+
+	m, _ = m.Update(keyRunes("n"))
+	if !strings.Contains(m.View(), `Match 2/3`) {
+		t.Errorf("expected n to advance to the next match:\n%s", m.View())
+	}
+	m, _ = m.Update(keyRunes("N"))
+	m, _ = m.Update(keyRunes("N"))
+	if !strings.Contains(m.View(), `Match 3/3`) {
+		t.Errorf("expected N to wrap around:\n%s", m.View())
+	}
+
+	// Esc clears the search before leaving the view.
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil || len(m.Matches()) != 0 {
+		t.Errorf("expected esc to clear the search, matches %v", m.Matches())
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("expected esc to go back")
+	}
+	if _, ok := cmd().(msgs.GoToPlanListMsg); !ok {
+		t.Errorf("expected GoToPlanListMsg, got %T", cmd())
+	}
+}
+
+func TestTranscriptModel_NoOutput(t *testing.T) {
+	m := NewTranscriptModel(setupTranscriptPlan(t, ""))
+	m.SetSize(120, 40)
+
+	if len(m.Attempts()) != 0 {
+		t.Errorf("expected no attempts, got %d", len(m.Attempts()))
+	}
+	if !strings.Contains(m.View(), "No task output recorded for this plan yet.") {
+		t.Errorf("expected empty-state message:\n%s", m.View())
+	}
+}
+
+func TestTranscriptModel_DoesNotMigratePlan(t *testing.T) {
+	planDir := t.TempDir()
+	legacy := `{"id":"abc123","name":"legacy","tasks":[{"id":"t01","title":"Legacy task","description":"","acceptanceCriteria":[]}]}`
+	if err := os.WriteFile(filepath.Join(planDir, "plan.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	NewTranscriptModel(planDir)
+
+	data, err := os.ReadFile(filepath.Join(planDir, "plan.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != legacy {
+		t.Errorf("expected plan.json to be left unchanged, got %s", data)
+	}
+}
+
+func TestTranscriptModel_OpenSearchHit(t *testing.T) {
+	m := NewTranscriptModel(setupTranscriptPlan(t, transcriptTestOutput))
+	m.SetSize(120, 40)
+	m.OpenSearchHit(1, "go test")
+
+	if m.Cursor() != 0 {
+		t.Errorf("expected the first attempt to be selected, got %d", m.Cursor())
+	}
+	if len(m.Matches()) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(m.Matches()))
+	}
+	if !strings.Contains(m.View(), `Match 1/1 for "go test"`) {
+		t.Errorf("view missing match line:\n%s", m.View())
+	}
+
+	// Words that do not appear together fall back to the longest word.
+	m.OpenSearchHit(2, "fixed xyz-parser")
+	if m.Cursor() != 1 || len(m.Matches()) != 0 {
+		t.Errorf("cursor %d, matches %v", m.Cursor(), m.Matches())
+	}
+	m.OpenSearchHit(2, "tests parser")
+	if len(m.Matches()) == 0 {
+		t.Error("expected fallback to the longest word to match")
+	}
+
+	// Esc clears the highlight, then returns to the search overlay.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	if msg, ok := cmd().(msgs.GoToSearchMsg); !ok || !msg.Resume {
+		t.Errorf("expected GoToSearchMsg{Resume: true}, got %#v", cmd())
+	}
+}
+
+// setupDesignPlan saves a plan whose task t01 is linked to the "parser"
+// section of docs/parser.md and returns the plan dir.
+func setupDesignPlan(t *testing.T) (string, *plan.Plan) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	doc := "# Design\n\n## Parser\nUse a recursive descent parser.\n\n## Printer\nPretty print.\n"
+	if err := os.WriteFile(filepath.Join(root, "docs", "parser.md"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	planDir := filepath.Join(root, ".rafa", "plans", "abc123-parser")
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	p := &plan.Plan{
+		ID:         "abc123",
+		Name:       "parser",
+		SourceFile: "docs/parser.md",
+		Status:     plan.PlanStatusInProgress,
+		Tasks: []plan.Task{
+			{ID: "t01", Title: "Write the parser", Status: plan.TaskStatusPending, Section: &plan.SectionRef{Anchor: "parser", StartLine: 3, EndLine: 5}},
+			{ID: "t02", Title: "Write the printer", Status: plan.TaskStatusPending},
+		},
+	}
+	if err := plan.SavePlan(planDir, p); err != nil {
+		t.Fatal(err)
+	}
+	return planDir, p
+}
+
+func TestTranscriptModel_ShowsDesignSection(t *testing.T) {
+	planDir, _ := setupDesignPlan(t)
+	if err := os.WriteFile(filepath.Join(planDir, plan.OutputLogFileName), []byte(transcriptTestOutput), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewTranscriptModel(planDir)
+	m.SetSize(120, 40)
+
+	text := strings.Join(m.Lines(), "\n")
+	for _, want := range []string{"Design: docs/parser.md#parser (lines 3-5)", "## Parser", "Use a recursive descent parser."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("transcript missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Pretty print.") {
+		t.Errorf("transcript should only show the linked section:\n%s", text)
+	}
+}
@@ -4,9 +4,10 @@
 //
 //	go run ./scripts/gen_demo_fixture.go
 //
-// It reads from a real `.rafa/plans/<id>-<name>/` directory, parses `output.log`
-// into demo Events, applies basic redaction/truncation, and writes a curated
-// fixture to `internal/demo/fixtures/default.v1.json`.
+// It reads from a real `.rafa/plans/<id>-<name>/` directory, parses its output
+// (`output.log` and the per-attempt files in `output/`) into demo Events,
+// applies basic redaction/truncation, and writes a curated fixture to
+// `internal/demo/fixtures/default.v1.json`.
 package main
 
 import (
@@ -252,8 +253,7 @@ func main() {
 	bestSuccess := make(map[string]attemptRecord)
 	bestAny := make(map[string]attemptRecord)
 
-	outputLog := filepath.Join(planDir, "output.log")
-	if err := scanOutputLog(outputLog, repoRoot, home, maxEventsPerTask, maxOutputChunkBytes, maxTextBytes, maxToolTargetBytes, func(taskID string, record attemptRecord) {
+	if err := scanOutputLog(planDir, repoRoot, home, maxEventsPerTask, maxOutputChunkBytes, maxTextBytes, maxToolTargetBytes, func(taskID string, record attemptRecord) {
 		any, ok := bestAny[taskID]
 		if !ok || record.Attempt > any.Attempt {
 			bestAny[taskID] = record
@@ -325,7 +325,7 @@ func main() {
 }
 
 func scanOutputLog(
-	path string,
+	planDir string,
 	repoRoot string,
 	home string,
 	maxEventsPerTask int,
@@ -334,7 +334,7 @@ func scanOutputLog(
 	maxToolTargetBytes int,
 	onAttempt func(taskID string, record attemptRecord),
 ) error {
-	f, err := os.Open(path)
+	f, err := plan.OpenOutputLog(planDir)
 	if err != nil {
 		return err
 	}